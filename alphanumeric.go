@@ -0,0 +1,69 @@
+package qrterminal
+
+import (
+	"fmt"
+	"strings"
+
+	"rsc.io/qr/coding"
+)
+
+// alphanumeric reports whether data only uses the 45 characters of the QR
+// alphanumeric mode: digits, upper case letters and " $%*+-./:"
+func alphanumeric(data []byte) bool {
+	return coding.Alpha(data).Check() == nil
+}
+
+// uppercaseURL returns data with the scheme and host in upper case if it is
+// an http or https URL without user info, which is case sensitive
+func uppercaseURL(data []byte) ([]byte, bool) {
+	link, ok := webURL(data)
+	if !ok {
+		return nil, false
+	}
+	start := strings.Index(link, "://") + len("://")
+	end := len(link)
+	if i := strings.IndexAny(link[start:], "/?#"); i >= 0 {
+		end = start + i
+	}
+	if strings.Contains(link[start:end], "@") {
+		return nil, false
+	}
+	return []byte(strings.ToUpper(link[:end]) + link[end:]), true
+}
+
+// qrVersion returns the smallest QR version that holds enc at level, or 0
+// if none does
+func qrVersion(enc coding.Encoding, level Level) int {
+	for v := coding.Version(coding.MinVersion); v <= coding.MaxVersion; v++ {
+		if enc.Bits(v) <= v.DataBytes(coding.Level(level.QR()))*8 {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// lintAlphanumeric points out data that would make a smaller QR code in
+// upper case, as only then it is encoded in the alphanumeric mode
+func lintAlphanumeric(data []byte, cfg Config) []Warning {
+	isQR := cfg.Symbology == "" || cfg.Symbology == SymbologyQR
+	data, err := cfg.payload(data)
+	if !isQR || err != nil || alphanumeric(data) {
+		return nil
+	}
+	upper := []byte(strings.ToUpper(string(data)))
+	if !alphanumeric(upper) {
+		return nil
+	}
+	have := qrVersion(coding.String(data), cfg.Level)
+	want := qrVersion(coding.Alpha(upper), cfg.Level)
+	if have == 0 || want == 0 || want >= have {
+		return nil
+	}
+	msg := fmt.Sprintf("in upper case the data is encoded in alphanumeric mode, version %d instead of %d", want, have)
+	if u, ok := uppercaseURL(data); ok && alphanumeric(u) {
+		msg += ", set AutoUppercaseURLs to upper case the scheme and host"
+	} else if ok {
+		msg += ", but the path of a URL may be case sensitive"
+	}
+	return []Warning{{"alphanumeric", msg}}
+}
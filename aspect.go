@@ -0,0 +1,22 @@
+package qrterminal
+
+import "math"
+
+// moduleScale returns how many times a module is repeated across and down
+// for it to come out roughly square in cells of c.AspectRatio, given the
+// number of columns the module glyphs take up
+func (c *Config) moduleScale() (across, down int) {
+	if c.AspectRatio <= 0 {
+		return 1, 1
+	}
+	cols := displayWidth([]byte(c.BlackChar))
+	if cols == 0 {
+		cols = 1
+	}
+	// The width of a module in line heights
+	width := float64(cols) * c.AspectRatio
+	if width <= 1 {
+		return int(math.Round(1 / width)), 1
+	}
+	return 1, int(math.Round(width))
+}
@@ -0,0 +1,27 @@
+package qrterminal
+
+import "testing"
+
+func TestModuleScale(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       Config
+		across, down int
+	}{
+		{"Unset", Config{BlackChar: "█"}, 1, 1},
+		{"Narrow", Config{BlackChar: "█", AspectRatio: 0.5}, 2, 1},
+		{"VeryNarrow", Config{BlackChar: "█", AspectRatio: 0.3}, 3, 1},
+		{"TwoColumns", Config{BlackChar: BLACK, AspectRatio: 0.5}, 1, 1},
+		{"TwoColumnsSlim", Config{BlackChar: BLACK, AspectRatio: 0.4}, 1, 1},
+		{"Square", Config{BlackChar: "#", AspectRatio: 1}, 1, 1},
+		{"Wide", Config{BlackChar: "##", AspectRatio: 1}, 1, 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			across, down := tc.config.moduleScale()
+			if across != tc.across || down != tc.down {
+				t.Errorf("Expected %dx%d, got %dx%d", tc.across, tc.down, across, down)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package qrterminal
+
+import (
+	"io"
+	"strings"
+)
+
+// Backdrop draws the block formats dark on light on a solid background
+// rectangle, whatever the colors of the terminal. On a dark terminal the
+// default glyphs draw the symbol inverted, light modules on the dark
+// background, which some scanners do not read; a backdrop keeps the modules
+// the right way round instead. A backdrop draws with glyphs of its own, the
+// glyphs and Theme of the Config are not used.
+type Backdrop struct {
+	// Color is the SGR parameters of the background, "107" (bright white)
+	// if empty
+	Color string
+	// Ink is the SGR parameters the black modules are drawn with, "30"
+	// (black) if empty
+	Ink string
+	// Margin is the number of blank cells the backdrop extends past the
+	// quiet zone on every side
+	Margin int
+	// Transparent draws the black modules alone, in Ink, and leaves the
+	// white modules, the quiet zone and the margin to the background of
+	// the terminal, e.g. to composite the code onto the themed background
+	// of a TUI. Color is not used. The code only scans on a light
+	// background, see Lint.
+	Transparent bool
+}
+
+// BackdropWhite is a bright white backdrop with black modules
+var BackdropWhite = &Backdrop{}
+
+// BackdropTransparent draws black modules on the terminal background
+var BackdropTransparent = &Backdrop{Transparent: true}
+
+// sgr returns the escape sequence selecting the backdrop colors
+func (b *Backdrop) sgr() string {
+	color, ink := b.Color, b.Ink
+	if color == "" {
+		color = "107"
+	}
+	if ink == "" {
+		ink = "30"
+	}
+	if b.Transparent {
+		return "\033[" + ink + "m"
+	}
+	return "\033[" + color + ";" + ink + "m"
+}
+
+// draw writes bm with quietZone white modules around it on the backdrop,
+// two rows of modules per line if half is set. Black modules are inked
+// with full and half blocks, white ones are left blank.
+func (b *Backdrop) draw(w io.Writer, bm *Bitmap, quietZone int, half bool) {
+	side := bm.Size + 2*quietZone
+	black := func(x, y int) bool {
+		return bm.Black(x-quietZone, y-quietZone)
+	}
+
+	var rows []string
+	var line strings.Builder
+	if half {
+		for y := 0; y < side; y += 2 {
+			line.Reset()
+			for x := 0; x < side; x++ {
+				top, bottom := black(x, y), y+1 < side && black(x, y+1)
+				switch {
+				case top && bottom:
+					line.WriteString("█")
+				case top:
+					line.WriteString("▀")
+				case bottom:
+					line.WriteString("▄")
+				default:
+					line.WriteString(" ")
+				}
+			}
+			rows = append(rows, line.String())
+		}
+	} else {
+		for y := 0; y < side; y++ {
+			line.Reset()
+			for x := 0; x < side; x++ {
+				if black(x, y) {
+					line.WriteString("██")
+				} else {
+					line.WriteString("  ")
+				}
+			}
+			rows = append(rows, line.String())
+		}
+	}
+
+	width := side
+	if !half {
+		width *= 2
+	}
+	sgr := b.sgr()
+	margin := strings.Repeat(" ", b.Margin)
+	blank := sgr + strings.Repeat(" ", width+2*b.Margin) + sgrReset + "\n"
+	var out strings.Builder
+	out.WriteString(strings.Repeat(blank, b.Margin))
+	for _, row := range rows {
+		out.WriteString(sgr + margin + row + margin + sgrReset + "\n")
+	}
+	out.WriteString(strings.Repeat(blank, b.Margin))
+	io.WriteString(w, out.String())
+}
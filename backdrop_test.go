@@ -0,0 +1,92 @@
+package qrterminal
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var sgrPattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+func TestBackdrop(t *testing.T) {
+	var buf bytes.Buffer
+	backdrop := &Backdrop{Margin: 1}
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 2, Backdrop: backdrop})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	// "test" is a version 1 code of 21 modules, plus the quiet zone and the
+	// margin on every side
+	if len(lines) != 21+2*2+2 {
+		t.Fatalf("Expected 27 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "\033[107;30m") || !strings.HasSuffix(line, sgrReset) {
+			t.Errorf("Line %d is not drawn on the backdrop: %q", i, line)
+		}
+		if w := displayWidth([]byte(line)); w != 2*(21+2*2)+2 {
+			t.Errorf("Line %d is %d columns wide, expected 52", i, w)
+		}
+	}
+
+	// Black modules are inked, white ones left blank
+	bm, _ := Encode([]byte("test"), Config{Level: L})
+	for y := 0; y < bm.Size; y++ {
+		text := []rune(sgrPattern.ReplaceAllString(lines[1+2+y], ""))
+		for x := 0; x < bm.Size; x++ {
+			got := text[1+2*(2+x)] == '█'
+			if got != bm.Black(x, y) {
+				t.Fatalf("Module (%d, %d) is drawn as %q", x, y, text[1+2*(2+x)])
+			}
+		}
+	}
+}
+
+func TestBackdropHalfBlocks(t *testing.T) {
+	// Half blocks pair the rows of the code and an odd quiet zone, the
+	// border must stay blank on every side
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 1, HalfBlocks: true, Backdrop: BackdropWhite})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("Expected 12 lines for 23 rows, got %d", len(lines))
+	}
+	bm, _ := Encode([]byte("test"), Config{Level: L})
+	for i, line := range lines {
+		text := []rune(sgrPattern.ReplaceAllString(line, ""))
+		if len(text) != 23 {
+			t.Fatalf("Line %d is %d columns wide, expected 23", i, len(text))
+		}
+		for x, r := range text {
+			top := bm.Black(x-1, 2*i-1)
+			bottom := bm.Black(x-1, 2*i)
+			want := map[[2]bool]rune{{true, true}: '█', {true, false}: '▀', {false, true}: '▄', {false, false}: ' '}[[2]bool{top, bottom}]
+			if r != want {
+				t.Fatalf("Line %d column %d is %q, expected %q", i, x, r, want)
+			}
+		}
+	}
+}
+
+func TestBackdropTransparent(t *testing.T) {
+	// Only the ink is set, white modules and the quiet zone are blank
+	// cells without a background of their own
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 1, HalfBlocks: true, Backdrop: BackdropTransparent})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("Expected 12 lines for 23 rows, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "\033[30m") || !strings.HasSuffix(line, sgrReset) {
+			t.Errorf("Line %d is not inked: %q", i, line)
+		}
+		if seqs := sgrPattern.FindAllString(line, -1); len(seqs) != 2 {
+			t.Errorf("Line %d sets colors %q, expected only the ink", i, seqs)
+		}
+	}
+	text := []rune(sgrPattern.ReplaceAllString(lines[0], ""))
+	if strings.TrimSpace(string(text[:1])+string(text[len(text)-1:])) != "" {
+		t.Errorf("The quiet zone is drawn: %q", lines[0])
+	}
+}
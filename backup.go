@@ -0,0 +1,17 @@
+package qrterminal
+
+import (
+	"io"
+
+	"github.com/katzenpost/qrterminal/v3/manualcode"
+)
+
+// writeBackupText writes data under the code in the manual-entry format of
+// package manualcode. newline starts a new line first, for formats whose
+// output does not end with one.
+func writeBackupText(w io.Writer, data []byte, newline bool) {
+	if newline {
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, manualcode.Encode(data)+"\n")
+}
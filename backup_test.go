@@ -0,0 +1,44 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/qrterminal/v3/manualcode"
+)
+
+func TestBackupText(t *testing.T) {
+	backup := manualcode.Encode([]byte("test")) + "\n"
+
+	var plain, text bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &plain})
+	GenerateWithConfig("test", Config{Level: L, Writer: &text, BackupText: true})
+	if text.String() != plain.String()+backup {
+		t.Errorf("Expected the backup code to follow the code, got %q", strings.TrimPrefix(text.String(), plain.String()))
+	}
+
+	// The backup code starts on a new line after sixel output
+	var sixel bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &sixel, Format: FormatSixel, BackupText: true})
+	if !strings.HasSuffix(sixel.String(), SIXEL_END+"\n"+backup) {
+		t.Errorf("Expected the backup code on its own line after the sixel image")
+	}
+
+	// Bits output stays machine readable
+	var bits bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &bits, Format: FormatBits, BackupText: true})
+	if strings.Contains(bits.String(), strings.TrimSpace(backup)) {
+		t.Errorf("Bits output should never carry a backup code")
+	}
+}
+
+func TestRenderedSizeBackupText(t *testing.T) {
+	for _, format := range []Format{FormatBlocks, FormatSixel} {
+		_, plain, _ := RenderedSize([]byte("test"), Config{Level: L, Format: format})
+		_, backup, _ := RenderedSize([]byte("test"), Config{Level: L, Format: format, BackupText: true})
+		if backup != plain+1 {
+			t.Errorf("%s: expected the backup code to take one more line, got %d and %d", format, plain, backup)
+		}
+	}
+}
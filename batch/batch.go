@@ -0,0 +1,130 @@
+// Package batch reads the records of a batch run, which renders one code
+// per record, and turns them into payloads and file names with templates.
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Record is one input record. CSV records map the header names to the
+// values of a row, JSON records keep their values as decoded, with numbers
+// as json.Number so they print exactly as written.
+type Record map[string]any
+
+// Reader returns the records of an input one at a time, and io.EOF after
+// the last one
+type Reader interface {
+	Read() (Record, error)
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader reads CSV records from r, the first row naming the fields
+func NewCSVReader(r io.Reader) Reader {
+	return &csvReader{r: csv.NewReader(r)}
+}
+
+func (c *csvReader) Read() (Record, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch: %w", err)
+		}
+		c.header = header
+	}
+	row, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("batch: %w", err)
+	}
+	rec := make(Record, len(row))
+	for i, v := range row {
+		rec[c.header[i]] = v
+	}
+	return rec, nil
+}
+
+type jsonLinesReader struct {
+	d *json.Decoder
+	n int // records read so far
+}
+
+// NewJSONLinesReader reads one JSON object per record from r
+func NewJSONLinesReader(r io.Reader) Reader {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	return &jsonLinesReader{d: d}
+}
+
+func (j *jsonLinesReader) Read() (Record, error) {
+	var rec Record
+	j.n++
+	if err := j.d.Decode(&rec); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, fmt.Errorf("batch: record %d: %w", j.n, err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("batch: record %d: not a JSON object", j.n)
+	}
+	return rec, nil
+}
+
+// NewReader returns the reader for format, "csv" or "jsonl"
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return NewCSVReader(r), nil
+	case "jsonl", "ndjson":
+		return NewJSONLinesReader(r), nil
+	default:
+		return nil, fmt.Errorf("batch: unknown input format %q", format)
+	}
+}
+
+// ErrEmpty is returned by Template.Execute when a template expands to
+// nothing, which is never a useful payload or file name
+var ErrEmpty = errors.New("batch: template expanded to an empty string")
+
+// Template expands the fields of a record, such as
+// "https://example.com/activate?token={{.token}}". Referencing a field the
+// record does not have is an error.
+type Template struct {
+	t *template.Template
+}
+
+// ParseTemplate parses text in the text/template syntax
+func ParseTemplate(text string) (*Template, error) {
+	t, err := template.New("batch").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("batch: %w", err)
+	}
+	return &Template{t}, nil
+}
+
+// Execute expands the template with the fields of rec
+func (t *Template) Execute(rec Record) (string, error) {
+	var buf bytes.Buffer
+	if err := t.t.Execute(&buf, rec); err != nil {
+		return "", fmt.Errorf("batch: %w", err)
+	}
+	if buf.Len() == 0 {
+		return "", ErrEmpty
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,148 @@
+package batch
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r Reader) []Record {
+	t.Helper()
+	var recs []Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	in := "id,token\n1,abc\n2,\"d,e\"\n"
+	got := readAll(t, NewCSVReader(strings.NewReader(in)))
+	want := []Record{{"id": "1", "token": "abc"}, {"id": "2", "token": "d,e"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if recs := readAll(t, NewCSVReader(strings.NewReader(""))); len(recs) != 0 {
+		t.Errorf("Expected no records from empty input, got %v", recs)
+	}
+
+	r := NewCSVReader(strings.NewReader("id,token\n1\n"))
+	if _, err := r.Read(); err == nil {
+		t.Errorf("Expected an error for a short row")
+	}
+}
+
+func TestJSONLinesReader(t *testing.T) {
+	in := `{"id": 12345678901234567890, "user": {"name": "ada"}}
+{"id": 2}
+`
+	recs := readAll(t, NewJSONLinesReader(strings.NewReader(in)))
+	if len(recs) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(recs))
+	}
+	tpl, _ := ParseTemplate("{{.id}} {{.user.name}}")
+	if got, err := tpl.Execute(recs[0]); err != nil || got != "12345678901234567890 ada" {
+		t.Errorf("Expected numbers to print as written, got %q (%v)", got, err)
+	}
+
+	r := NewJSONLinesReader(strings.NewReader("{\"id\": 1}\n[1, 2]\n"))
+	r.Read()
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("Expected an error naming record 2, got %v", err)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	for _, format := range []string{"csv", "jsonl", "NDJSON"} {
+		if _, err := NewReader(format, strings.NewReader("")); err != nil {
+			t.Errorf("NewReader(%q) failed: %v", format, err)
+		}
+	}
+	if _, err := NewReader("xml", strings.NewReader("")); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tpl, err := ParseTemplate("https://example.com/activate?token={{.token}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+	got, err := tpl.Execute(Record{"token": "abc"})
+	if err != nil || got != "https://example.com/activate?token=abc" {
+		t.Errorf("Unexpected expansion %q (%v)", got, err)
+	}
+
+	if _, err := tpl.Execute(Record{"id": "1"}); err == nil {
+		t.Errorf("Expected an error for a missing field")
+	}
+
+	empty, _ := ParseTemplate("{{.token}}")
+	if _, err := empty.Execute(Record{"token": ""}); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, err := ParseTemplate("{{.token"); err == nil {
+		t.Errorf("Expected a parse error")
+	}
+}
+
+func TestFramedReader(t *testing.T) {
+	testCases := []struct {
+		framing string
+		in      string
+		want    []string
+	}{
+		{"eof", "a\x00b\n", []string{"a\x00b\n"}},
+		{"eof", "", nil},
+		{"line", "a\r\nb\n\nc", []string{"a", "b", "", "c"}},
+		{"null", "a\x00b\x00", []string{"a", "b"}},
+		{"null", "a\x00\xffb", []string{"a", "\xffb"}},
+		{"len", "\x00\x00\x00\x01a\x00\x00\x00\x03\x00b\n", []string{"a", "\x00b\n"}},
+		{"len", "", nil},
+	}
+	for _, tc := range testCases {
+		r, err := NewFramedReader(tc.framing, strings.NewReader(tc.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, rec := range readAll(t, r) {
+			got = append(got, rec[FrameField].(string))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s framing of %q: expected %q, got %q", tc.framing, tc.in, tc.want, got)
+		}
+	}
+
+	if _, err := NewFramedReader("lines", strings.NewReader("")); err == nil {
+		t.Errorf("Expected an error for an unknown framing")
+	}
+}
+
+func TestFramedReaderErrors(t *testing.T) {
+	for _, in := range []string{
+		"\x00\x00\x00\x05abc",  // truncated
+		"\x00\x00\x01",         // truncated length
+		"\x7f\x00\x00\x00data", // too large
+	} {
+		r, _ := NewFramedReader("len", strings.NewReader(in))
+		if _, err := r.Read(); err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q, got %v", in, err)
+		}
+	}
+	r, _ := NewFramedReader("len", strings.NewReader("\x00\x00\x00\x01a\x00\x00"))
+	r.Read()
+	if _, err := r.Read(); !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "frame 2") {
+		t.Errorf("Expected a truncated frame 2, got %v", err)
+	}
+}
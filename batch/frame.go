@@ -0,0 +1,95 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FrameField is the field holding the payload in the records of a
+// framed reader
+const FrameField = "data"
+
+// MaxFrameSize is the largest frame a framed reader accepts, far more than
+// a QR code holds
+const MaxFrameSize = 1 << 20
+
+// ScanNUL is a bufio.SplitFunc that returns the frames of NUL delimited
+// input. A final NUL is optional.
+func ScanNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ScanLengthPrefixed is a bufio.SplitFunc that returns the frames of input
+// in which every frame is preceded by its length as a 4 byte big-endian
+// integer
+func ScanLengthPrefixed(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) >= 4 {
+		n := binary.BigEndian.Uint32(data)
+		if n > MaxFrameSize {
+			return 0, nil, fmt.Errorf("frame of %d bytes is larger than %d", n, MaxFrameSize)
+		}
+		if end := 4 + int(n); len(data) >= end {
+			return end, data[4:end], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return 0, nil, fmt.Errorf("truncated frame: %w", io.ErrUnexpectedEOF)
+	}
+	return 0, nil, nil
+}
+
+// scanAll is a bufio.SplitFunc that returns the whole input as one frame
+func scanAll(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+type frameReader struct {
+	s *bufio.Scanner
+	n int // frames read so far
+}
+
+// NewFramedReader reads binary payloads from r, one record per frame with
+// the payload in FrameField. framing is "eof" for the whole input as one
+// frame, "line" for newline delimited frames, "null" for NUL delimited
+// frames or "len" for frames with a length prefix, see ScanLengthPrefixed.
+func NewFramedReader(framing string, r io.Reader) (Reader, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, MaxFrameSize+4)
+	switch strings.ToLower(framing) {
+	case "eof":
+		s.Split(scanAll)
+	case "line":
+		s.Split(bufio.ScanLines)
+	case "null", "nul":
+		s.Split(ScanNUL)
+	case "len":
+		s.Split(ScanLengthPrefixed)
+	default:
+		return nil, fmt.Errorf("batch: unknown framing %q", framing)
+	}
+	return &frameReader{s: s}, nil
+}
+
+func (f *frameReader) Read() (Record, error) {
+	f.n++
+	if !f.s.Scan() {
+		if err := f.s.Err(); err != nil {
+			return nil, fmt.Errorf("batch: frame %d: %w", f.n, err)
+		}
+		return nil, io.EOF
+	}
+	return Record{FrameField: f.s.Text()}, nil
+}
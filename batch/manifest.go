@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+// Entry records one generated code for auditing
+type Entry struct {
+	// Record is the number of the input record, counting from 1
+	Record int `json:"record"`
+	// File is the output file the code was written to
+	File string `json:"file"`
+	// Version is the symbol version, 0 for symbologies without versions
+	Version int `json:"version"`
+	// Level is the error correction level
+	Level string `json:"level"`
+	// SHA256 is the hex encoded SHA-256 hash of the payload
+	SHA256 string `json:"sha256"`
+}
+
+// PayloadHash returns the hash recorded in Entry.SHA256 for payload
+func PayloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashNameLen is the number of hex digits of the payload hash in HashName,
+// 64 bits make collisions unlikely even for millions of codes
+const hashNameLen = 16
+
+// HashName returns a file name for payload made of the start of its
+// PayloadHash, so it is the same on every run and for equal payloads
+func HashName(payload []byte) string {
+	return PayloadHash(payload)[:hashNameLen]
+}
+
+// ErrUnsafeName is returned by LocalName for a file name that leaves the
+// output directory
+var ErrUnsafeName = errors.New("batch: file name is absolute or leaves the output directory")
+
+// LocalName cleans name, which may come from the fields of a record, and
+// checks that it stays inside the directory it is joined to
+func LocalName(name string) (string, error) {
+	name = filepath.Clean(name)
+	if !filepath.IsLocal(name) || name == "." {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeName, name)
+	}
+	return name, nil
+}
+
+// Manifest lists the codes generated by a batch run
+type Manifest struct {
+	Entries []Entry
+}
+
+// Add appends e to the manifest
+func (m *Manifest) Add(e Entry) {
+	m.Entries = append(m.Entries, e)
+}
+
+// WriteCSV writes the manifest as CSV with a header row
+func (m *Manifest) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"record", "file", "version", "level", "sha256"})
+	for _, e := range m.Entries {
+		cw.Write([]string{strconv.Itoa(e.Record), e.File, strconv.Itoa(e.Version), e.Level, e.SHA256})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the manifest as a JSON array of entries
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	entries := m.Entries
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
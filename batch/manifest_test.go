@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	var m Manifest
+	m.Add(Entry{Record: 1, File: "codes/1.pdf", Version: 3, Level: "M", SHA256: PayloadHash([]byte("abc"))})
+	m.Add(Entry{Record: 2, File: "codes/a,b.pdf", Version: 4, Level: "M", SHA256: PayloadHash([]byte("def"))})
+
+	var csv bytes.Buffer
+	if err := m.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "record,file,version,level,sha256\n" +
+		"1,codes/1.pdf,3,M,ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n" +
+		"2,\"codes/a,b.pdf\",4,M,cb8379ac2098aa165029e3938a51da0bcecfc008fd6795f401178647f96c5b34\n"
+	if csv.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, csv.String())
+	}
+
+	var out bytes.Buffer
+	if err := m.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(entries) != 2 || entries[1] != m.Entries[1] {
+		t.Errorf("JSON manifest does not round trip: %v", entries)
+	}
+
+	out.Reset()
+	(&Manifest{}).WriteJSON(&out)
+	if out.String() != "[]\n" {
+		t.Errorf("Expected an empty array for an empty manifest, got %q", out.String())
+	}
+}
+
+func TestHashName(t *testing.T) {
+	if got := HashName([]byte("abc")); got != "ba7816bf8f01cfea" {
+		t.Errorf("Expected the first 16 hex digits of the SHA-256, got %s", got)
+	}
+	if HashName([]byte("abc")) == HashName([]byte("abd")) {
+		t.Errorf("Different payloads should get different names")
+	}
+}
+
+func TestLocalName(t *testing.T) {
+	for name, want := range map[string]string{
+		"codes/1.pdf":    "codes/1.pdf",
+		"./codes//1.pdf": "codes/1.pdf",
+		"codes/../1.pdf": "1.pdf",
+		"..foo/1.pdf":    "..foo/1.pdf",
+	} {
+		if got, err := LocalName(name); err != nil || got != want {
+			t.Errorf("LocalName(%q) = %q, %v, expected %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"/etc/passwd", "../1.pdf", "codes/../../1.pdf", "..", "."} {
+		if _, err := LocalName(name); !errors.Is(err, ErrUnsafeName) {
+			t.Errorf("LocalName(%q) should fail, got %v", name, err)
+		}
+	}
+}
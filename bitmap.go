@@ -0,0 +1,69 @@
+package qrterminal
+
+import (
+	"image"
+	"image/color"
+
+	"rsc.io/qr"
+)
+
+// Point is the position of a module, X counts columns and Y rows from the
+// top left corner
+type Point struct {
+	X, Y int
+}
+
+// Bitmap is the square grid of modules of an encoded symbol, without any
+// quiet zone. Modules outside the grid read as white.
+type Bitmap struct {
+	// Size is the number of modules on a side
+	Size  int
+	pix   []bool
+	roles []ModuleRole
+}
+
+// NewBitmap returns an all white Bitmap with size modules on a side
+func NewBitmap(size int) *Bitmap {
+	return &Bitmap{Size: size, pix: make([]bool, size*size)}
+}
+
+// Black reports whether the module at (x, y) is black
+func (b *Bitmap) Black(x, y int) bool {
+	return 0 <= x && x < b.Size && 0 <= y && y < b.Size && b.pix[y*b.Size+x]
+}
+
+// Set colors the module at (x, y), coordinates outside the grid are ignored
+func (b *Bitmap) Set(x, y int, black bool) {
+	if 0 <= x && x < b.Size && 0 <= y && y < b.Size {
+		b.pix[y*b.Size+x] = black
+	}
+}
+
+// Image returns the bitmap surrounded by quietZone white modules as an
+// image of one pixel per module, with black at index 0 of its palette and
+// white at index 1
+func (b *Bitmap) Image(quietZone int) *image.Paletted {
+	if quietZone < 0 {
+		quietZone = 0
+	}
+	side := b.Size + 2*quietZone
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.Black, color.White})
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			if !b.Black(x-quietZone, y-quietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img
+}
+
+func bitmapFromCode(code *qr.Code) *Bitmap {
+	b := NewBitmap(code.Size)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			b.pix[y*b.Size+x] = code.Black(x, y)
+		}
+	}
+	return b
+}
@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package qrterminal
+
+import "iter"
+
+// All iterates over every module of b, row by row from the top left
+// corner, yielding its position and whether it is black
+func (b *Bitmap) All() iter.Seq2[Point, bool] {
+	return func(yield func(Point, bool) bool) {
+		for y := 0; y < b.Size; y++ {
+			for x := 0; x < b.Size; x++ {
+				if !yield(Point{x, y}, b.pix[y*b.Size+x]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// RowsIter iterates over the rows of b from top to bottom, yielding the row
+// number and the row's modules, true being black. The slice shares memory
+// with b, it must not be modified or retained after the loop iteration.
+func (b *Bitmap) RowsIter() iter.Seq2[int, []bool] {
+	return func(yield func(int, []bool) bool) {
+		for y := 0; y < b.Size; y++ {
+			if !yield(y, b.pix[y*b.Size:(y+1)*b.Size:(y+1)*b.Size]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build go1.23
+
+package qrterminal
+
+import (
+	"testing"
+
+	"rsc.io/qr"
+)
+
+func TestBitmapAll(t *testing.T) {
+	code, _ := qr.Encode("https://github.com/mdp/qrterminal", M.QR())
+	bm := bitmapFromCode(code)
+
+	count := 0
+	for p, black := range bm.All() {
+		if black != code.Black(p.X, p.Y) {
+			t.Fatalf("Module %v differs from the encoded code", p)
+		}
+		count++
+	}
+	if count != bm.Size*bm.Size {
+		t.Errorf("Expected %d modules, got %d", bm.Size*bm.Size, count)
+	}
+
+	// Breaking out of the loop should stop the iteration
+	count = 0
+	for range bm.All() {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Errorf("Expected the iteration to stop after 5 modules, got %d", count)
+	}
+}
+
+func TestBitmapRowsIter(t *testing.T) {
+	code, _ := qr.Encode("test", L.QR())
+	bm := bitmapFromCode(code)
+
+	rows := 0
+	for y, row := range bm.RowsIter() {
+		if y != rows {
+			t.Fatalf("Expected row %d, got %d", rows, y)
+		}
+		if len(row) != bm.Size {
+			t.Fatalf("Expected %d modules in row %d, got %d", bm.Size, y, len(row))
+		}
+		for x, black := range row {
+			if black != code.Black(x, y) {
+				t.Fatalf("Module (%d, %d) differs from the encoded code", x, y)
+			}
+		}
+		rows++
+	}
+	if rows != bm.Size {
+		t.Errorf("Expected %d rows, got %d", bm.Size, rows)
+	}
+}
@@ -0,0 +1,24 @@
+package qrterminal
+
+import "testing"
+
+func TestBitmapImage(t *testing.T) {
+	bm := NewBitmap(3)
+	bm.Set(0, 0, true)
+	bm.Set(2, 1, true)
+	img := bm.Image(2)
+	if b := img.Bounds(); b.Dx() != 7 || b.Dy() != 7 {
+		t.Fatalf("Expected a 7x7 image, got %v", b)
+	}
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 7; x++ {
+			want := uint8(1)
+			if bm.Black(x-2, y-2) {
+				want = 0
+			}
+			if got := img.ColorIndexAt(x, y); got != want {
+				t.Errorf("Pixel (%d, %d) is %d, expected %d", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package qrterminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeBits writes the code with its quiet zone as rows of 0 (white) and
+// 1 (black) digits, separated into groups of BitsGroup digits if set
+func (c *Config) writeBits(w io.Writer, bm *Bitmap) {
+	size := bm.Size + 2*c.QuietZone
+	var line strings.Builder
+	for y := 0; y < size; y++ {
+		line.Reset()
+		for x := 0; x < size; x++ {
+			if c.BitsGroup > 0 && x > 0 && x%c.BitsGroup == 0 {
+				line.WriteByte(' ')
+			}
+			if bm.Black(x-c.QuietZone, y-c.QuietZone) {
+				line.WriteByte('1')
+			} else {
+				line.WriteByte('0')
+			}
+		}
+		line.WriteByte('\n')
+		w.Write([]byte(line.String()))
+	}
+}
+
+// ErrInvalidBits is returned by ParseBits for input that is not a grid of
+// 0 and 1 digits holding a QR code
+var ErrInvalidBits = errors.New("qrterminal: invalid bits grid")
+
+// ParseBits reads a code written in FormatBits back into a Bitmap. Spaces
+// and tabs between digits and blank lines are ignored, and the quiet zone
+// is removed.
+func ParseBits(s string) (*Bitmap, error) {
+	var rows [][]bool
+	for _, line := range strings.Split(s, "\n") {
+		var row []bool
+		for _, r := range line {
+			switch r {
+			case '0':
+				row = append(row, false)
+			case '1':
+				row = append(row, true)
+			case ' ', '\t', '\r':
+			default:
+				return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidBits, r)
+			}
+		}
+		if len(row) == 0 {
+			continue
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("%w: rows have different lengths", ErrInvalidBits)
+		}
+		rows = append(rows, row)
+	}
+
+	// Trim the quiet zone, the finder patterns guarantee that the first and
+	// last row and column of a QR code contain black modules
+	top, bottom, left, right := -1, -1, -1, -1
+	for y, row := range rows {
+		for x, black := range row {
+			if !black {
+				continue
+			}
+			if top < 0 {
+				top = y
+			}
+			bottom = y
+			if left < 0 || x < left {
+				left = x
+			}
+			if x > right {
+				right = x
+			}
+		}
+	}
+	if top < 0 {
+		return nil, fmt.Errorf("%w: no black modules", ErrInvalidBits)
+	}
+	size := bottom - top + 1
+	if right-left+1 != size || size < 21 || (size-21)%4 != 0 {
+		return nil, fmt.Errorf("%w: %dx%d is not a QR code size", ErrInvalidBits, right-left+1, size)
+	}
+
+	bm := NewBitmap(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			bm.Set(x, y, rows[top+y][left+x])
+		}
+	}
+	return bm, nil
+}
@@ -0,0 +1,84 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+func TestBitsRoundTrip(t *testing.T) {
+	code, err := qr.Encode("https://github.com/mdp/qrterminal", M.QR())
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	want := bitmapFromCode(code)
+
+	for _, group := range []int{0, 4, 8} {
+		var buf bytes.Buffer
+		GenerateWithConfig("https://github.com/mdp/qrterminal", Config{
+			Level:     M,
+			Writer:    &buf,
+			Format:    FormatBits,
+			BitsGroup: group,
+			QuietZone: 2,
+		})
+		output := buf.String()
+
+		if strings.Trim(output, "01 \n") != "" {
+			t.Errorf("Bits output should only contain digits, spaces and newlines")
+		}
+		lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+		if len(lines) != want.Size+4 {
+			t.Errorf("Expected %d lines, got %d", want.Size+4, len(lines))
+		}
+		if group > 0 && !strings.HasPrefix(lines[0], strings.Repeat("0", group)+" ") {
+			t.Errorf("Expected digits in groups of %d, got %q", group, lines[0])
+		}
+
+		got, err := ParseBits(output)
+		if err != nil {
+			t.Fatalf("ParseBits failed: %v", err)
+		}
+		if got.Size != want.Size {
+			t.Fatalf("Expected size %d, got %d", want.Size, got.Size)
+		}
+		for y := 0; y < want.Size; y++ {
+			for x := 0; x < want.Size; x++ {
+				if got.Black(x, y) != want.Black(x, y) {
+					t.Fatalf("Module (%d, %d) differs after the round trip", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestBitsIgnoresProfile(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, Format: FormatBits, Profile: ProfileRemote})
+	if strings.Contains(buf.String(), "\033") {
+		t.Errorf("Bits output should never contain escape sequences")
+	}
+}
+
+func TestParseBitsInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"Empty", ""},
+		{"AllWhite", "000\n000\n000\n"},
+		{"BadCharacter", "0101\n01x1\n"},
+		{"RaggedRows", "0101\n011\n"},
+		{"NotQRSize", "11\n11\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseBits(tc.input); !errors.Is(err, ErrInvalidBits) {
+				t.Errorf("Expected ErrInvalidBits, got %v", err)
+			}
+		})
+	}
+}
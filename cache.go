@@ -0,0 +1,60 @@
+package qrterminal
+
+import "sync"
+
+// EncodeCache keeps the codes of the payloads encoded last, so drawing the
+// same data again skips encoding it, e.g. in a display that redraws its
+// code or keeps cycling through a few. Codes are kept by symbology, level
+// and data. It is safe for concurrent use.
+type EncodeCache struct {
+	mu   sync.Mutex
+	size int
+	// entries are ordered from the least to the most recently used
+	entries []cacheEntry
+}
+
+type cacheEntry struct {
+	key string
+	bm  *Bitmap
+}
+
+// NewEncodeCache returns a cache of the codes of the last size payloads,
+// at least one
+func NewEncodeCache(size int) *EncodeCache {
+	if size < 1 {
+		size = 1
+	}
+	return &EncodeCache{size: size}
+}
+
+// get returns a copy of the code stored under key, or nil
+func (c *EncodeCache) get(key string) *Bitmap {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, e := range c.entries {
+		if e.key == key {
+			copy(c.entries[i:], c.entries[i+1:])
+			c.entries[len(c.entries)-1] = e
+			return e.bm.clone()
+		}
+	}
+	return nil
+}
+
+// put stores a copy of bm under key, dropping the least recently used code
+// if the cache is full
+func (c *EncodeCache) put(key string, bm *Bitmap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == c.size {
+		c.entries = append(c.entries[:0], c.entries[1:]...)
+	}
+	c.entries = append(c.entries, cacheEntry{key, bm.clone()})
+}
+
+// clone returns a copy of b that can be changed without changing b
+func (b *Bitmap) clone() *Bitmap {
+	c := *b
+	c.pix = append([]bool(nil), b.pix...)
+	return &c
+}
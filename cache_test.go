@@ -0,0 +1,61 @@
+package qrterminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeCache(t *testing.T) {
+	encoded := 0
+	RegisterSymbology("cache-test", EncoderFunc(func(data []byte, level Level) (*Bitmap, error) {
+		encoded++
+		return encodeQR(data, level)
+	}))
+	config := Config{Symbology: "cache-test", Cache: NewEncodeCache(2)}
+	encode := func(data string) *Bitmap {
+		bm, err := Encode([]byte(data), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bm
+	}
+
+	first := encode("a")
+	if again := encode("a"); encoded != 1 || !equalBitmaps(first, again) {
+		t.Errorf("Expected the same code from the cache, encoded %d times", encoded)
+	}
+	// A code taken from the cache can be changed without changing the cache
+	first.Set(0, 0, false)
+	if !encode("a").Black(0, 0) {
+		t.Errorf("Changing a cached code changed the cache")
+	}
+
+	// The least recently used code is dropped
+	encode("b")
+	encode("a")
+	encode("c")
+	encoded = 0
+	encode("a")
+	encode("c")
+	if encoded != 0 {
+		t.Errorf("Expected a and c to be cached, encoded %d", encoded)
+	}
+	encode("b")
+	if encoded != 1 {
+		t.Errorf("Expected b to be dropped, encoded %d", encoded)
+	}
+
+	// Levels are cached apart
+	config.Level = H
+	if bm := encode("a"); encoded != 2 || bm.Size == 0 {
+		t.Errorf("Expected level H to be encoded, encoded %d", encoded)
+	}
+
+	// Rendering goes through the cache as well
+	var buf bytes.Buffer
+	config.Writer = &buf
+	GenerateWithConfig("a", config)
+	if encoded != 2 || buf.Len() == 0 {
+		t.Errorf("Expected the code to be drawn from the cache, encoded %d", encoded)
+	}
+}
@@ -0,0 +1,250 @@
+package qrterminal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Capabilities describes what a terminal is able to display
+type Capabilities struct {
+	// Sixel is set when the terminal renders sixel graphics
+	Sixel bool
+	// Hyperlink is set when the terminal makes OSC 8 hyperlinks clickable
+	Hyperlink bool
+	// Unicode is set when the terminal draws block elements, as used by
+	// FormatHalfBlocks and the default glyphs
+	Unicode bool
+	// Color is set when the terminal interprets ANSI color sequences
+	Color bool
+}
+
+// Configure sets the format and glyphs of c to the richest that caps can
+// show: sixel, then half blocks, then full blocks drawn with ANSI colors,
+// then ASCII
+func (caps Capabilities) Configure(c *Config) {
+	for _, m := range DefaultFallbacks {
+		if caps.supports(m) {
+			m.configure(c)
+			return
+		}
+	}
+}
+
+// Terminal is the environment a Prober inspects
+type Terminal interface {
+	// Getenv returns the value of the environment variable key
+	Getenv(key string) string
+	// IsTerminal reports whether the output is an interactive terminal
+	IsTerminal() bool
+	// Query writes a control sequence to the terminal and returns its reply
+	Query(seq string) ([]byte, error)
+}
+
+// Prober detects capabilities of a terminal and records them in caps.
+// Probers run in registration order, so a later Prober sees and may
+// override the findings of the ones before it.
+type Prober interface {
+	Probe(t Terminal, caps *Capabilities)
+}
+
+// ProberFunc adapts an ordinary function to the Prober interface
+type ProberFunc func(t Terminal, caps *Capabilities)
+
+// Probe calls f(t, caps)
+func (f ProberFunc) Probe(t Terminal, caps *Capabilities) {
+	f(t, caps)
+}
+
+// ErrNotTerminal is returned by Query when the output is not a terminal,
+// and by ReadSecret when the input is not
+var ErrNotTerminal = errors.New("qrterminal: not a terminal")
+
+var (
+	probersMu sync.Mutex
+	probers   = []Prober{ProberFunc(probeSixel), ProberFunc(probeHyperlink), ProberFunc(probeText)}
+)
+
+// RegisterProber adds p to the probers run by DetectCapabilities, e.g. to
+// recognize a web terminal that can not be detected by querying it
+func RegisterProber(p Prober) {
+	probersMu.Lock()
+	defer probersMu.Unlock()
+	probers = append(probers, p)
+}
+
+// ErrUnknownCapability is returned by QueryTermcap for a capability the
+// terminal does not have
+var ErrUnknownCapability = errors.New("qrterminal: unknown terminal capability")
+
+// QueryTermcap asks the terminal for the terminfo capability name with
+// XTGETTCAP, e.g. "TN" for its name or "Co" for its number of colors, and
+// returns the value, empty for a boolean capability. It is meant for a
+// Prober that knows the terminal implements XTGETTCAP, as xterm, kitty and
+// WezTerm do: other terminals, such as tmux and Windows Terminal, do not
+// reply at all.
+func QueryTermcap(t Terminal, name string) (string, error) {
+	key := hex.EncodeToString([]byte(name))
+	reply, err := t.Query("\x1BP+q" + key + "\x1B\\")
+	if err != nil {
+		return "", err
+	}
+	body, dcs := bytes.CutPrefix(reply, []byte("\x1BP"))
+	body, st := bytes.CutSuffix(body, []byte("\x1B\\"))
+	if !dcs || !st {
+		return "", fmt.Errorf("qrterminal: malformed XTGETTCAP reply %q", reply)
+	}
+	if bytes.HasPrefix(body, []byte("0+r")) {
+		return "", ErrUnknownCapability
+	}
+	fields, ok := bytes.CutPrefix(body, []byte("1+r"))
+	if !ok {
+		return "", fmt.Errorf("qrterminal: malformed XTGETTCAP reply %q", reply)
+	}
+	// The reply may list more than the capability asked for
+	for _, field := range strings.Split(string(fields), ";") {
+		k, v, _ := strings.Cut(field, "=")
+		if !strings.EqualFold(k, key) {
+			continue
+		}
+		value, err := hex.DecodeString(v)
+		if err != nil {
+			return "", fmt.Errorf("qrterminal: malformed XTGETTCAP reply %q", reply)
+		}
+		return string(value), nil
+	}
+	return "", ErrUnknownCapability
+}
+
+// DetectCapabilities runs the registered probers against the terminal
+// behind w. Only os.Stdout is queried, any other writer is treated as a
+// non-interactive output.
+func DetectCapabilities(w io.Writer) Capabilities {
+	return detectCapabilities(newTerminal(w))
+}
+
+// DetectCapabilitiesFromEnv is DetectCapabilities without querying the
+// terminal: probers see every query fail, so they report only what the
+// environment tells, and sixel support is never found. Use it when the
+// sixel format is not wanted, a terminal that does not answer a query
+// makes it wait for the reply.
+func DetectCapabilitiesFromEnv(w io.Writer) Capabilities {
+	return detectCapabilities(envOnly{newTerminal(w)})
+}
+
+// envOnly is a Terminal that is never queried
+type envOnly struct {
+	Terminal
+}
+
+// Query fails without writing seq
+func (envOnly) Query(seq string) ([]byte, error) {
+	return nil, ErrNotTerminal
+}
+
+func detectCapabilities(t Terminal) Capabilities {
+	probersMu.Lock()
+	ps := append([]Prober(nil), probers...)
+	probersMu.Unlock()
+
+	var caps Capabilities
+	for _, p := range ps {
+		p.Probe(t, &caps)
+	}
+	return caps
+}
+
+// probeSixel asks the terminal for its primary device attributes (DA1),
+// attribute 4 announces sixel graphics
+func probeSixel(t Terminal, caps *Capabilities) {
+	if !t.IsTerminal() {
+		return
+	}
+	reply, err := t.Query("\x1B[c")
+	if err != nil {
+		return
+	}
+	caps.Sixel = hasDeviceAttribute(reply, "4")
+}
+
+// hyperlinkPrograms are the TERM_PROGRAM values of terminals known to
+// support OSC 8 hyperlinks
+var hyperlinkPrograms = map[string]bool{
+	"iTerm.app": true,
+	"WezTerm":   true,
+	"vscode":    true,
+	"ghostty":   true,
+	"Hyper":     true,
+	"Tabby":     true,
+}
+
+// probeHyperlink recognizes terminals with OSC 8 support from their
+// environment, there is no query that reports it
+func probeHyperlink(t Terminal, caps *Capabilities) {
+	if !t.IsTerminal() {
+		return
+	}
+	switch {
+	case hyperlinkPrograms[t.Getenv("TERM_PROGRAM")],
+		t.Getenv("WT_SESSION") != "",                // Windows Terminal
+		t.Getenv("KITTY_WINDOW_ID") != "",           // kitty
+		t.Getenv("KONSOLE_VERSION") != "",           // Konsole
+		vteVersion(t.Getenv("VTE_VERSION")) >= 5000: // GNOME Terminal and other VTE based terminals
+		caps.Hyperlink = true
+	}
+}
+
+// vteVersion parses VTE_VERSION, e.g. "6003" for VTE 0.60.3, or returns 0
+func vteVersion(s string) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// probeText finds out whether the terminal draws colors and block elements.
+// Every terminal but a dumb one is assumed to handle colors, block
+// elements need Windows Terminal or a UTF-8 locale.
+func probeText(t Terminal, caps *Capabilities) {
+	if !t.IsTerminal() || t.Getenv("TERM") == "dumb" {
+		return
+	}
+	caps.Color = true
+	caps.Unicode = t.Getenv("WT_SESSION") != "" || utf8Locale(t)
+}
+
+// utf8Locale reports whether the locale in effect uses UTF-8
+func utf8Locale(t Terminal) bool {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := strings.ToUpper(t.Getenv(key)); v != "" {
+			return strings.Contains(v, "UTF-8") || strings.Contains(v, "UTF8")
+		}
+	}
+	return false
+}
+
+// hasDeviceAttribute reports whether a DA1 reply such as "\x1B[?62;4;22c"
+// lists attr
+func hasDeviceAttribute(reply []byte, attr string) bool {
+	start := bytes.Index(reply, []byte("\x1B[?"))
+	if start < 0 {
+		return false
+	}
+	reply = reply[start+3:]
+	end := bytes.IndexByte(reply, 'c')
+	if end < 0 {
+		return false
+	}
+	for _, a := range bytes.Split(reply[:end], []byte(";")) {
+		if string(a) == attr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !tinygo
+
+package qrterminal
+
+import "os"
+
+// queryInput returns the file the replies to queries written to out are
+// read from, the terminal device itself
+func queryInput(out *os.File) *os.File {
+	return out
+}
+
+// canQuery reports whether the terminal answers queries
+func canQuery() bool {
+	return true
+}
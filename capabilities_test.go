@@ -0,0 +1,280 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/katzenpost/qrterminal/v3/internal/ttytest"
+)
+
+// fakeTerminal answers queries from a fixed table
+type fakeTerminal struct {
+	env     map[string]string
+	replies map[string]string
+}
+
+func (t fakeTerminal) Getenv(key string) string { return t.env[key] }
+func (t fakeTerminal) IsTerminal() bool         { return t.replies != nil }
+func (t fakeTerminal) Query(seq string) ([]byte, error) {
+	if t.replies == nil {
+		return nil, ErrNotTerminal
+	}
+	return []byte(t.replies[seq]), nil
+}
+
+func TestHasDeviceAttribute(t *testing.T) {
+	testCases := []struct {
+		reply string
+		want  bool
+	}{
+		{"\x1B[?62;4;22c", true},
+		{"\x1B[?4c", true},
+		{"\x1B[?62;22c", false},
+		{"\x1B[?64;22c", false}, // 64 is not 4
+		{"", false},
+		{"garbage", false},
+	}
+	for _, tc := range testCases {
+		if got := hasDeviceAttribute([]byte(tc.reply), "4"); got != tc.want {
+			t.Errorf("hasDeviceAttribute(%q) = %v, want %v", tc.reply, got, tc.want)
+		}
+	}
+}
+
+func TestProbeSixel(t *testing.T) {
+	sixel := fakeTerminal{replies: map[string]string{"\x1B[c": "\x1B[?62;4;22c"}}
+	if caps := detectCapabilities(sixel); !caps.Sixel {
+		t.Errorf("Expected sixel support to be detected")
+	}
+
+	if caps := detectCapabilities(fakeTerminal{}); caps.Sixel {
+		t.Errorf("A non-interactive output should never report sixel support")
+	}
+}
+
+func TestRegisterProber(t *testing.T) {
+	saved := probers
+	defer func() { probers = saved }()
+
+	RegisterProber(ProberFunc(func(t Terminal, caps *Capabilities) {
+		if t.Getenv("WEB_TERMINAL") == "sixel" {
+			caps.Sixel = true
+		}
+	}))
+
+	web := fakeTerminal{env: map[string]string{"WEB_TERMINAL": "sixel"}}
+	if caps := detectCapabilities(web); !caps.Sixel {
+		t.Errorf("Registered prober should have enabled sixel")
+	}
+	if caps := detectCapabilities(fakeTerminal{}); caps.Sixel {
+		t.Errorf("Registered prober should not affect other terminals")
+	}
+}
+
+func TestDetectCapabilitiesNonTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	if caps := DetectCapabilities(&buf); caps.Sixel {
+		t.Errorf("A buffer is not a terminal and should not support sixel")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Probing a non-terminal should not write to it, got %q", buf.String())
+	}
+}
+
+func TestProbeHyperlink(t *testing.T) {
+	testCases := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{map[string]string{"WT_SESSION": "7d3a"}, true},
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, true},
+		{map[string]string{"VTE_VERSION": "6003"}, true},
+		{map[string]string{"VTE_VERSION": "4205"}, false},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, false},
+		{nil, false},
+	}
+	for _, tc := range testCases {
+		term := fakeTerminal{env: tc.env, replies: map[string]string{}}
+		if caps := detectCapabilities(term); caps.Hyperlink != tc.want {
+			t.Errorf("Hyperlink for %v = %v, want %v", tc.env, caps.Hyperlink, tc.want)
+		}
+	}
+
+	piped := fakeTerminal{env: map[string]string{"TERM_PROGRAM": "iTerm.app"}}
+	if caps := detectCapabilities(piped); caps.Hyperlink {
+		t.Errorf("A non-interactive output should never report hyperlink support")
+	}
+}
+
+// Primary device attribute replies recorded from Windows consoles
+const (
+	da1WindowsTerminal122 = "\x1B[?61;4;6;7;14;21;22;23;24;28;32;42;52c"
+	da1WindowsTerminal121 = "\x1B[?61;6;7;14;21;22;23;24;28;32;42c"
+	da1Conhost            = "\x1B[?1;0c"
+)
+
+func TestWindowsFallbackChain(t *testing.T) {
+	wt := map[string]string{"WT_SESSION": "0d5b1b4e-8f0e-4c2b-9d6a-3f7c2e1a9b55"}
+	testCases := []struct {
+		name   string
+		term   fakeTerminal
+		format Format
+		glyphs Glyphs
+		theme  *Theme
+	}{
+		{"WindowsTerminal122", fakeTerminal{env: wt, replies: map[string]string{"\x1B[c": da1WindowsTerminal122}},
+			FormatSixel, Glyphs{}, nil},
+		{"WindowsTerminal121", fakeTerminal{env: wt, replies: map[string]string{"\x1B[c": da1WindowsTerminal121}},
+			FormatHalfBlocks, Glyphs{}, nil},
+		{"Conhost", fakeTerminal{replies: map[string]string{"\x1B[c": da1Conhost}},
+			FormatBlocks, Glyphs{Black: BLACK, White: WHITE}, nil},
+		{"DumbTerminal", fakeTerminal{env: map[string]string{"TERM": "dumb"}, replies: map[string]string{}},
+			FormatBlocks, Glyphs{}, ThemeASCII},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{BlackChar: "x", Theme: ThemeShade}
+			detectCapabilities(tc.term).Configure(&c)
+			if c.Format != tc.format {
+				t.Errorf("Expected format %s, got %s", tc.format, c.Format)
+			}
+			if c.BlackChar != tc.glyphs.Black || c.WhiteChar != tc.glyphs.White {
+				t.Errorf("Expected glyphs %q and %q, got %q and %q", tc.glyphs.Black, tc.glyphs.White, c.BlackChar, c.WhiteChar)
+			}
+			if c.Theme != tc.theme {
+				t.Errorf("Expected theme %v, got %v", tc.theme, c.Theme)
+			}
+		})
+	}
+}
+
+func TestProbeText(t *testing.T) {
+	testCases := []struct {
+		env            map[string]string
+		unicode, color bool
+	}{
+		{map[string]string{"LANG": "en_US.UTF-8"}, true, true},
+		{map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, false, true},
+		{map[string]string{"LC_CTYPE": "de_DE.utf8"}, true, true},
+		{map[string]string{"WT_SESSION": "1"}, true, true},
+		{nil, false, true},
+		{map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"}, false, false},
+	}
+	for _, tc := range testCases {
+		caps := detectCapabilities(fakeTerminal{env: tc.env, replies: map[string]string{}})
+		if caps.Unicode != tc.unicode || caps.Color != tc.color {
+			t.Errorf("%v: expected unicode %v and color %v, got %v and %v", tc.env, tc.unicode, tc.color, caps.Unicode, caps.Color)
+		}
+	}
+
+	if caps := detectCapabilities(fakeTerminal{env: map[string]string{"LANG": "en_US.UTF-8"}}); caps.Unicode || caps.Color {
+		t.Errorf("A non-interactive output should report no text capabilities")
+	}
+}
+
+func TestRecordedTerminals(t *testing.T) {
+	recs, err := ttytest.Load("testdata/terminals")
+	if err != nil {
+		t.Fatalf("Loading recordings failed: %v", err)
+	}
+	if len(recs) == 0 {
+		t.Fatalf("No recordings found")
+	}
+	for _, rec := range recs {
+		t.Run(rec.Name, func(t *testing.T) {
+			caps := detectCapabilities(rec)
+			got := map[string]bool{
+				"sixel":     caps.Sixel,
+				"hyperlink": caps.Hyperlink,
+				"unicode":   caps.Unicode,
+				"color":     caps.Color,
+			}
+			for name, want := range rec.Want {
+				if v, ok := got[name]; !ok {
+					t.Errorf("Unknown capability %q in recording", name)
+				} else if v != want {
+					t.Errorf("Expected %s %v, got %v", name, want, v)
+				}
+			}
+
+			profile := ""
+			if p := detectProfile(rec); p != nil {
+				profile = p.Name
+			}
+			if profile != rec.Profile {
+				t.Errorf("Expected profile %q, got %q", rec.Profile, profile)
+			}
+
+			for _, q := range rec.Queries {
+				if _, ok := rec.Replies[q]; !ok {
+					t.Errorf("Sent query %q that the recording has no reply for", q)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordedTermcap(t *testing.T) {
+	recs, err := ttytest.Load("testdata/terminals")
+	if err != nil {
+		t.Fatalf("Loading recordings failed: %v", err)
+	}
+	replayed := 0
+	for _, rec := range recs {
+		for name, want := range rec.Termcap {
+			if got, err := QueryTermcap(rec, name); err != nil || got != want {
+				t.Errorf("%s: expected %s %q, got %q, %v", rec.Name, name, want, got, err)
+			}
+			replayed++
+		}
+	}
+	if replayed == 0 {
+		t.Error("No XTGETTCAP replies recorded")
+	}
+}
+
+func TestQueryTermcap(t *testing.T) {
+	term := fakeTerminal{replies: map[string]string{
+		"\x1BP+q544e\x1B\\":   "\x1BP1+r436f=323536;544e=78746572\x1B\\",
+		"\x1BP+q5858\x1B\\":   "\x1BP0+r5858\x1B\\",
+		"\x1BP+q524742\x1B\\": "\x1BP1+r524742=zz\x1B\\",
+		"\x1BP+q436f\x1B\\":   "\x1B[?62;c",
+	}}
+	if got, err := QueryTermcap(term, "TN"); err != nil || got != "xter" {
+		t.Errorf("Expected TN from a reply with two capabilities, got %q, %v", got, err)
+	}
+	if _, err := QueryTermcap(term, "XX"); !errors.Is(err, ErrUnknownCapability) {
+		t.Errorf("Expected ErrUnknownCapability, got %v", err)
+	}
+	for _, name := range []string{"RGB", "Co"} {
+		if _, err := QueryTermcap(term, name); err == nil || errors.Is(err, ErrUnknownCapability) {
+			t.Errorf("%s: expected a malformed reply error, got %v", name, err)
+		}
+	}
+	if _, err := QueryTermcap(fakeTerminal{}, "TN"); !errors.Is(err, ErrNotTerminal) {
+		t.Errorf("Expected ErrNotTerminal, got %v", err)
+	}
+}
+
+func TestDetectCapabilitiesFromEnv(t *testing.T) {
+	recs, err := ttytest.Load("testdata/terminals")
+	if err != nil {
+		t.Fatalf("Loading recordings failed: %v", err)
+	}
+	for _, rec := range recs {
+		t.Run(rec.Name, func(t *testing.T) {
+			caps := detectCapabilities(envOnly{rec})
+			if len(rec.Queries) > 0 {
+				t.Errorf("Expected no queries, sent %q", rec.Queries)
+			}
+			if caps.Sixel {
+				t.Error("Expected no sixel support without a query")
+			}
+			if want, ok := rec.Want["hyperlink"]; ok && caps.Hyperlink != want {
+				t.Errorf("Expected hyperlink %v, got %v", want, caps.Hyperlink)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !tinygo
+
+package qrterminal
+
+import "os"
+
+// queryInput returns the file the replies to queries written to out are
+// read from. A console output handle can not be read, replies arrive on the
+// input handle once term.MakeRaw has put it in virtual terminal input mode.
+func queryInput(out *os.File) *os.File {
+	return os.Stdin
+}
+
+// canQuery reports whether the console answers queries. Only Windows
+// Terminal is queried, the console host of older Windows versions does not
+// reply and the read would block.
+func canQuery() bool {
+	return os.Getenv("WT_SESSION") != ""
+}
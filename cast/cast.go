@@ -0,0 +1,91 @@
+// Package cast writes terminal output as an asciinema v2 recording, which
+// asciinema play and the web player replay, e.g. for documentation.
+//
+// A recording is a JSON header line followed by one JSON array per output
+// event: the time in seconds since the start, "o" and the text written.
+package cast
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// Header describes a recording
+type Header struct {
+	// Width and Height are the size of the terminal in columns and lines
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Timestamp is the start of the recording in Unix seconds, omitted if
+	// zero
+	Timestamp int64 `json:"timestamp,omitempty"`
+	// Title is shown by the player, omitted if empty
+	Title string `json:"title,omitempty"`
+	// Env records environment variables such as TERM and SHELL
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Writer turns every Write into an output event, timed from the creation
+// of the Writer
+type Writer struct {
+	w       io.Writer
+	start   time.Time
+	now     func() time.Time
+	cr      bool   // the last byte written was a carriage return
+	pending []byte // the start of a rune split over writes
+}
+
+// NewWriter writes the header h to w and returns a Writer for the events
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	line, err := json.Marshal(struct {
+		Version int `json:"version"`
+		Header
+	}{2, h})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, start: time.Now(), now: time.Now}, nil
+}
+
+// Write records p as one output event. A terminal sends "\r\n" where a
+// program writes "\n", so lone newlines are recorded that way.
+func (c *Writer) Write(p []byte) (int, error) {
+	data := append(c.pending, p...)
+	n := len(data)
+	// Hold back a rune split over writes, an event must be valid UTF-8
+	for i := n - 1; i >= 0 && i >= n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				n = i
+			}
+			break
+		}
+	}
+	c.pending = append([]byte(nil), data[n:]...)
+	if n == 0 {
+		return len(p), nil
+	}
+
+	text := make([]byte, 0, n)
+	for _, b := range data[:n] {
+		if b == '\n' && !c.cr {
+			text = append(text, '\r')
+		}
+		text = append(text, b)
+		c.cr = b == '\r'
+	}
+	elapsed := math.Round(c.now().Sub(c.start).Seconds()*1e6) / 1e6
+	line, err := json.Marshal([]interface{}{elapsed, "o", string(text)})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.w.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,70 @@
+package cast
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := NewWriter(&buf, Header{Width: 29, Height: 15, Env: map[string]string{"TERM": "xterm-256color"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := c.start
+	c.now = func() time.Time { return clock }
+
+	c.Write([]byte("\033[47m \033[0m\n"))
+	clock = clock.Add(1500 * time.Millisecond)
+	c.Write([]byte("a\r\nb\n"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two events, got %q", lines)
+	}
+	want := `{"version":2,"width":29,"height":15,"env":{"TERM":"xterm-256color"}}`
+	if lines[0] != want {
+		t.Errorf("Header\n got %s\nwant %s", lines[0], want)
+	}
+	for i, want := range []struct {
+		time float64
+		text string
+	}{
+		{0, "\033[47m \033[0m\r\n"},
+		{1.5, "a\r\nb\r\n"},
+	} {
+		var event []interface{}
+		if err := json.Unmarshal([]byte(lines[i+1]), &event); err != nil {
+			t.Fatalf("Event %d: %v", i, err)
+		}
+		if len(event) != 3 || event[0] != want.time || event[1] != "o" || event[2] != want.text {
+			t.Errorf("Event %d is %q, want [%v \"o\" %q]", i, event, want.time, want.text)
+		}
+	}
+}
+
+func TestWriterSplitRune(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := NewWriter(&buf, Header{Width: 1, Height: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := []byte("█")
+	c.Write(block[:1])
+	c.Write(block[1:])
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one event for the completed rune, got %q", lines[1:])
+	}
+	var event []interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event[2] != "█" {
+		t.Errorf("Expected the rune in one piece, got %q", event[2])
+	}
+}
@@ -0,0 +1,127 @@
+//go:build js && wasm
+
+// Command qrterminal-wasm exposes the encoder and renderers to JavaScript,
+// so web terminals and documentation sites draw codes exactly like the
+// library does. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o qrterminal.wasm ./cmd/qrterminal-wasm
+//
+// and load it with the wasm_exec.js of the Go distribution. It defines a
+// global qrterminal object:
+//
+//	qrterminal.render(text, {level: "M", format: "halfblocks", quietZone: 2}) // string
+//	qrterminal.imageData(text, {level: "M", quietZone: 4, scale: 8})         // ImageData
+//
+// Go can not throw JavaScript exceptions, so for invalid options or data that
+// can not be encoded both return an Error object instead:
+//
+//	const out = qrterminal.render(text)
+//	if (out instanceof Error) { ... }
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"syscall/js"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+func main() {
+	js.Global().Set("qrterminal", js.ValueOf(map[string]interface{}{
+		"render":    js.FuncOf(wrap(render)),
+		"imageData": js.FuncOf(wrap(imageData)),
+	}))
+	// Keep the functions callable
+	select {}
+}
+
+// wrap turns an error returned by f into a JavaScript Error
+func wrap(f func(text string, opts js.Value) (interface{}, error)) func(this js.Value, args []js.Value) interface{} {
+	return func(this js.Value, args []js.Value) interface{} {
+		if len(args) == 0 || args[0].Type() != js.TypeString {
+			return js.Global().Get("Error").New("qrterminal: the first argument must be the text to encode")
+		}
+		opts := js.Undefined()
+		if len(args) > 1 {
+			opts = args[1]
+		}
+		v, err := f(args[0].String(), opts)
+		if err != nil {
+			return js.Global().Get("Error").New(err.Error())
+		}
+		return v
+	}
+}
+
+// config reads the level, format and quietZone options
+func config(opts js.Value) (qrterminal.Config, error) {
+	cfg := qrterminal.Config{Level: qrterminal.L, QuietZone: qrterminal.QUIET_ZONE}
+	if opts.Type() != js.TypeObject {
+		return cfg, nil
+	}
+	if v := opts.Get("level"); v.Type() == js.TypeString {
+		if err := cfg.Level.Set(v.String()); err != nil {
+			return cfg, err
+		}
+	}
+	if v := opts.Get("format"); v.Type() == js.TypeString {
+		if err := cfg.Format.Set(v.String()); err != nil {
+			return cfg, err
+		}
+	}
+	if v := opts.Get("quietZone"); v.Type() == js.TypeNumber {
+		cfg.QuietZone = v.Int()
+	}
+	return cfg, nil
+}
+
+// render returns the code drawn in a text format, blocks by default
+func render(text string, opts js.Value) (interface{}, error) {
+	cfg, err := config(opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	cfg.Writer = &buf
+	if err := qrterminal.GenerateFromReader(bytes.NewReader([]byte(text)), 0, cfg); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// imageData returns the code as an ImageData of scale pixels per module,
+// black on white
+func imageData(text string, opts js.Value) (interface{}, error) {
+	cfg, err := config(opts)
+	if err != nil {
+		return nil, err
+	}
+	scale := 1
+	if opts.Type() == js.TypeObject && opts.Get("scale").Type() == js.TypeNumber {
+		scale = opts.Get("scale").Int()
+	}
+	if scale < 1 || scale > 64 {
+		return nil, fmt.Errorf("qrterminal: scale must be from 1 to 64, not %d", scale)
+	}
+	bm, err := qrterminal.Encode([]byte(text), cfg)
+	if err != nil {
+		return nil, err
+	}
+	img := bm.Image(cfg.QuietZone)
+	side := img.Bounds().Dx() * scale
+	pix := make([]byte, side*side*4)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			v := byte(0)
+			if img.ColorIndexAt(x/scale, y/scale) == 1 {
+				v = 0xff
+			}
+			i := (y*side + x) * 4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = v, v, v, 0xff
+		}
+	}
+	data := js.Global().Get("Uint8ClampedArray").New(len(pix))
+	js.CopyBytesToJS(data, pix)
+	return js.Global().Get("ImageData").New(data, side, side), nil
+}
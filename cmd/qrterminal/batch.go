@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/batch"
+	"golang.org/x/term"
+)
+
+// runBatch renders one code per record of a CSV or JSON lines input, with
+// the payload expanded from a template
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	level := qrterminal.L
+	fs.Var(&level, "l", levelUsage)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	format := qrterminal.FormatBlocks
+	fs.Var(&format, "format", "output `format`: "+formatNames())
+	inputFlag := fs.String("input", "csv", "input format: csv (with a header row) or jsonl")
+	framingFlag := fs.String("stdin-framing", "", "read binary payloads instead of records: eof (the whole input), line (newline delimited), null (NUL delimited) or len (each preceded by a 4 byte big-endian length), in the field data")
+	templateFlag := fs.String("template", "", "payload template, e.g. 'https://example.com/activate?token={{.token}}', {{.data}} with -stdin-framing")
+	outFlag := fs.String("out", "", "write each code to a file named by this template, e.g. 'codes/{{.id}}.pdf', in the -dir directory")
+	hashFlag := fs.Bool("name-by-hash", false, "name each file by a hash of its payload, in the -dir directory")
+	dirFlag := fs.String("dir", ".", "directory for the files of -out and -name-by-hash")
+	execFlag := fs.String("exec", "", "run this shell command for every code, with the file name from -out or -name-by-hash on its stdin and in $QRTERMINAL_FILE, or else the code itself")
+	manifestFlag := fs.String("manifest", "", "with -out or -name-by-hash, list the files written in this CSV file, or JSON if it ends in .json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s batch -template TEMPLATE [flags] [file]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Render one code per record of the file, or stdin.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *templateFlag == "" && *framingFlag != "" {
+		*templateFlag = "{{." + batch.FrameField + "}}"
+	}
+	if *templateFlag == "" {
+		fmt.Fprintln(os.Stderr, "batch: -template is required")
+		os.Exit(1)
+	}
+	payloadTemplate, err := batch.ParseTemplate(*templateFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *hashFlag && *outFlag != "" {
+		fmt.Fprintln(os.Stderr, "batch: -out and -name-by-hash are mutually exclusive")
+		os.Exit(1)
+	}
+	var name namer
+	switch {
+	case *outFlag != "":
+		outTemplate, err := batch.ParseTemplate(*outFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		name = func(rec batch.Record, data string) (string, error) {
+			name, err := outTemplate.Execute(rec)
+			if err != nil {
+				return "", err
+			}
+			// The fields come from the input, which must not pick where
+			// files are written
+			name, err = batch.LocalName(name)
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(*dirFlag, name), nil
+		}
+	case *hashFlag:
+		ext := formatExtensions[format]
+		name = func(rec batch.Record, data string) (string, error) {
+			return filepath.Join(*dirFlag, batch.HashName([]byte(data))+ext), nil
+		}
+	}
+	if *manifestFlag != "" && name == nil {
+		fmt.Fprintln(os.Stderr, "batch: -manifest requires -out or -name-by-hash")
+		os.Exit(1)
+	}
+
+	var input io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		input = f
+	}
+	var records batch.Reader
+	if strings.EqualFold(*framingFlag, "line") && fs.NArg() == 0 && term.IsTerminal(int(os.Stdin.Fd())) {
+		// Payloads typed or pasted at the terminal
+		records = editorRecords{newLineEditor("Payload: ")}
+	} else if *framingFlag != "" {
+		records, err = batch.NewFramedReader(*framingFlag, input)
+	} else {
+		records, err = batch.NewReader(*inputFlag, input)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cfg := qrterminal.Config{
+		Level:     level,
+		QuietZone: *quietZoneFlag,
+		Format:    format,
+	}
+	if format == qrterminal.FormatBlocks {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+	// Codes for files and -exec are not shown in this terminal
+	if name == nil && *execFlag == "" {
+		cfg.Profile = qrterminal.DetectProfileFromEnv()
+	}
+
+	// Without files, codes for -exec are rendered into hookInput
+	var hookInput bytes.Buffer
+	stdout := io.Writer(os.Stdout)
+	if *execFlag != "" && name == nil {
+		stdout = &hookInput
+	}
+
+	var manifest batch.Manifest
+	for n := 1; ; n++ {
+		rec, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		var data, file string
+		var bm *qrterminal.Bitmap
+		if err == nil {
+			if name == nil && stdout == os.Stdout {
+				fmt.Printf("\nrecord %d:\n", n)
+			}
+			data, file, bm, err = renderRecord(rec, payloadTemplate, name, cfg, stdout)
+		}
+		if err == nil && *execFlag != "" {
+			err = runRecordHook(*execFlag, n, file, &hookInput)
+		}
+		if err == nil && *manifestFlag != "" {
+			addManifestEntry(&manifest, n, file, data, bm, cfg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "record %d: %v\n", n, err)
+			os.Exit(1)
+		}
+	}
+
+	if *manifestFlag != "" {
+		if err := writeManifest(&manifest, *manifestFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// addManifestEntry records the code bm drawn for the payload data into name
+func addManifestEntry(m *batch.Manifest, n int, name, data string, bm *qrterminal.Bitmap, cfg qrterminal.Config) {
+	version := 0
+	if cfg.Symbology == "" || cfg.Symbology == qrterminal.SymbologyQR {
+		version = (bm.Size - 17) / 4 // a version v QR code has 4v+17 modules on a side
+	}
+	m.Add(batch.Entry{
+		Record:  n,
+		File:    name,
+		Version: version,
+		Level:   cfg.Level.String(),
+		SHA256:  batch.PayloadHash([]byte(data)),
+	})
+}
+
+// writeManifest saves m as JSON if name ends in .json, CSV otherwise
+func writeManifest(m *batch.Manifest, name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = m.WriteJSON(f)
+	} else {
+		err = m.WriteCSV(f)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// namer returns the output file for a record and its payload
+type namer func(rec batch.Record, data string) (string, error)
+
+// formatExtensions are the file name extensions used by -name-by-hash
+var formatExtensions = map[qrterminal.Format]string{
+	qrterminal.FormatBlocks:      ".txt",
+	qrterminal.FormatHalfBlocks:  ".txt",
+	qrterminal.FormatDoubleSize:  ".txt",
+	qrterminal.FormatBits:        ".txt",
+	qrterminal.FormatSixel:       ".six",
+	qrterminal.FormatPDF:         ".pdf",
+	qrterminal.FormatZPL:         ".zpl",
+	qrterminal.FormatEPL:         ".epl",
+	qrterminal.FormatLinePrinter: ".txt",
+}
+
+// runRecordHook runs the -exec command for record n, with the name of its
+// file on stdin, or the code in rendered if there is no file
+func runRecordHook(command string, n int, file string, rendered *bytes.Buffer) error {
+	env := []string{"QRTERMINAL_RECORD=" + strconv.Itoa(n)}
+	if file == "" {
+		defer rendered.Reset()
+		return runHook(command, rendered, env...)
+	}
+	env = append(env, "QRTERMINAL_FILE="+file)
+	return runHook(command, strings.NewReader(file+"\n"), env...)
+}
+
+// renderRecord writes the code for rec to the file named by out, or to
+// stdout if out is nil. It returns the payload, the name of the file and
+// the code as drawn, for the manifest.
+func renderRecord(rec batch.Record, payload *batch.Template, out namer, cfg qrterminal.Config, stdout io.Writer) (data, name string, bm *qrterminal.Bitmap, err error) {
+	data, err = payload.Execute(rec)
+	if err != nil {
+		return "", "", nil, err
+	}
+	cfg.Use(func(next qrterminal.RenderFunc) qrterminal.RenderFunc {
+		return func(w io.Writer, drawn *qrterminal.Bitmap) error {
+			bm = drawn
+			return next(w, drawn)
+		}
+	})
+	if out == nil {
+		cfg.Writer = stdout
+		err = qrterminal.GenerateFromReader(strings.NewReader(data), 0, cfg)
+		return data, "", bm, err
+	}
+
+	name, err = out(rec, data)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", "", nil, err
+		}
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return "", "", nil, err
+	}
+	cfg.Writer = f
+	if err := qrterminal.GenerateFromReader(strings.NewReader(data), 0, cfg); err != nil {
+		f.Close()
+		return "", "", nil, err
+	}
+	return data, name, bm, f.Close()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// builderCommand draws the code of a payload built from flags
+type builderCommand struct {
+	fs        *flag.FlagSet
+	level     qrterminal.Level
+	quietZone *int
+	format    qrterminal.Format
+	print     *bool
+}
+
+// newBuilderCommand returns the flags every builder subcommand shares,
+// with usage describing its argument
+func newBuilderCommand(name, arg, usage string) *builderCommand {
+	c := &builderCommand{fs: flag.NewFlagSet(name, flag.ExitOnError), level: qrterminal.L}
+	c.fs.Var(&c.level, "l", levelUsage)
+	c.quietZone = c.fs.Int("q", 2, "Size of quietzone border")
+	c.fs.Var(&c.format, "format", "output `format`: "+formatNames()+" (default blocks)")
+	c.print = c.fs.Bool("print", false, "write the payload instead of drawing it")
+	c.fs.Usage = func() {
+		fmt.Fprintf(c.fs.Output(), "Usage: %s %s [flags] %s\n\n%s\n\n", os.Args[0], name, arg, usage)
+		c.fs.PrintDefaults()
+	}
+	return c
+}
+
+// parse parses args and returns the one argument
+func (c *builderCommand) parse(args []string) string {
+	c.fs.Parse(args)
+	if c.fs.NArg() != 1 {
+		c.fs.Usage()
+		os.Exit(2)
+	}
+	return c.fs.Arg(0)
+}
+
+// draw checks p and draws its code, or writes it with -print
+func (c *builderCommand) draw(p payload.Parsed) {
+	data, err := p.Payload()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", c.fs.Name(), err)
+		os.Exit(1)
+	}
+	if *c.print {
+		fmt.Println(data)
+		return
+	}
+	cfg := qrterminal.Config{
+		Level:     c.level,
+		Writer:    os.Stdout,
+		QuietZone: *c.quietZone,
+		Format:    c.format,
+		Profile:   qrterminal.DetectProfileFromEnv(),
+	}
+	if c.format == "" || c.format == qrterminal.FormatBlocks || c.format == qrterminal.FormatDoubleSize {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+	if err := qrterminal.GenerateFromReader(strings.NewReader(data), 0, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", c.fs.Name(), err)
+		os.Exit(1)
+	}
+}
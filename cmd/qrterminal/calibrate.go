@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// calibrationVersions are the symbol versions of the test codes, a mode is
+// tried with ever larger codes until one does not scan
+var calibrationVersions = []int{2, 6, 10}
+
+// calibrationRatios are the cell aspect ratios offered for the blocks
+// format, ratios that draw the same modules as an earlier one are skipped.
+// 0 draws the modules as they are.
+var calibrationRatios = []float64{0, 0.25, 0.33, 0.4, 0.75, 1}
+
+// calibrationPrefix starts the text of every test code, so users can tell
+// it from whatever else their scanner picks up
+const calibrationPrefix = "QRTERMINAL CALIBRATION "
+
+// calibrationMode is a way to draw codes that calibrate offers
+type calibrationMode struct {
+	name     string
+	settings settings
+}
+
+// calibrationModes returns the modes to try, the richest first
+func calibrationModes(sixel bool) []calibrationMode {
+	var modes []calibrationMode
+	if sixel {
+		modes = append(modes, calibrationMode{"sixel", settings{Format: qrterminal.FormatSixel}})
+	}
+	return append(modes,
+		calibrationMode{"half blocks", settings{Format: qrterminal.FormatHalfBlocks}},
+		calibrationMode{"blocks", settings{Format: qrterminal.FormatBlocks}},
+		calibrationMode{"blocks on a white backdrop", settings{Format: qrterminal.FormatBlocks, Backdrop: true}},
+		calibrationMode{"ASCII", settings{Format: qrterminal.FormatBlocks, Theme: qrterminal.ThemeASCII.Name}},
+	)
+}
+
+// config returns the Config the main command draws with under s
+func (s settings) config() qrterminal.Config {
+	cfg := qrterminal.Config{
+		Level:       qrterminal.L,
+		Writer:      os.Stdout,
+		QuietZone:   qrterminal.QUIET_ZONE,
+		Format:      s.Format,
+		Theme:       qrterminal.ThemeByName(s.Theme),
+		AspectRatio: s.AspectRatio,
+	}
+	if s.Backdrop {
+		cfg.Backdrop = qrterminal.BackdropWhite
+	}
+	if cfg.Theme == nil && !s.Backdrop && (s.Format == "" || s.Format == qrterminal.FormatBlocks || s.Format == qrterminal.FormatDoubleSize) {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+	return cfg
+}
+
+// runCalibrate shows test codes in every mode the terminal may draw, asks
+// which of them scan and saves the best mode as the default of future runs
+func runCalibrate(args []string) {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	dryRun := fs.Bool("n", false, "print the settings found instead of saving them")
+	jsonFlag := fs.String("json", "", "append whether each test code scanned to this `file`, one JSON object per code")
+	scannerFlag := fs.String("scanner", "", "name of the scanner app, recorded with the results")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s calibrate [flags]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Find the output mode your scanner reads best and save it as the default.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	in := bufio.NewReader(os.Stdin)
+	profile := qrterminal.DetectProfileFromEnv()
+	sixel := qrterminal.IsSixelSupported(os.Stdout) && (profile == nil || !profile.NoSixel)
+
+	fmt.Printf("Scan each code with your phone. It reads %q followed by digits.\n", strings.TrimSpace(calibrationPrefix))
+	var best calibrationMode
+	bestVersion := 0
+	// The test codes hold no user data
+	log := resultLog{path: *jsonFlag, scanner: *scannerFlag, showPayload: true}
+	for _, mode := range calibrationModes(sixel) {
+		v := calibrateMode(in, mode, log)
+		if v > bestVersion {
+			best, bestVersion = mode, v
+		}
+	}
+	if bestVersion == 0 {
+		fmt.Fprintln(os.Stderr, "None of the codes scanned, the settings are left unchanged")
+		os.Exit(1)
+	}
+	fmt.Printf("\nUsing %s, codes up to version %d scanned.\n", best.name, bestVersion)
+
+	s := best.settings
+	if s.Format == qrterminal.FormatBlocks {
+		s.AspectRatio = calibrateAspect(in, s)
+	}
+
+	if *dryRun {
+		fmt.Printf("format=%s theme=%s backdrop=%t aspect-ratio=%g\n", s.Format, s.Theme, s.Backdrop, s.AspectRatio)
+		return
+	}
+	path, err := s.save()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save the settings: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved to %s, flags on the command line still take precedence.\n", path)
+}
+
+// calibrateMode shows codes of increasing version in mode and returns the
+// largest version that scanned, 0 if none did. Every answer is recorded in
+// log.
+func calibrateMode(in *bufio.Reader, mode calibrationMode, log resultLog) int {
+	scanned := 0
+	for _, version := range calibrationVersions {
+		data, err := calibrationPayload(version)
+		if err != nil {
+			break
+		}
+		fmt.Printf("\n%s, version %d:\n", mode.name, version)
+		qrterminal.GenerateBinaryWithConfig(data, mode.settings.config())
+		ok := askYes(in, "Did it scan? [y/N] ")
+		log.record(mode.name, fmt.Sprintf("version %d", version), data, ok)
+		if !ok {
+			break
+		}
+		scanned = version
+	}
+	return scanned
+}
+
+// calibrationPayload returns test data that encodes to a code of the given
+// version at level L
+func calibrationPayload(version int) ([]byte, error) {
+	data := []byte(calibrationPrefix)
+	for i := 0; ; i++ {
+		info, err := qrterminal.Info(data, qrterminal.Config{Level: qrterminal.L})
+		if err != nil {
+			return nil, err
+		}
+		if info.Version >= version {
+			return data, nil
+		}
+		data = append(data, byte('0'+i%10))
+	}
+}
+
+// calibrateAspect draws a checkerboard for every cell aspect ratio that
+// changes how s draws modules and returns the one the user finds square
+func calibrateAspect(in *bufio.Reader, s settings) float64 {
+	var ratios []float64
+	seen := map[string]bool{}
+	for _, r := range calibrationRatios {
+		s.AspectRatio = r
+		cfg := s.config()
+		var board strings.Builder
+		cfg.Writer = &board
+		cfg.QuietZone = 1
+		cfg.Use(checkerboard)
+		qrterminal.GenerateWithConfig("calibrate", cfg)
+		if seen[board.String()] {
+			continue
+		}
+		seen[board.String()] = true
+		ratios = append(ratios, r)
+		fmt.Printf("\n%d:\n%s", len(ratios), board.String())
+	}
+	if len(ratios) < 2 {
+		return 0
+	}
+	for {
+		answer := ask(in, fmt.Sprintf("Which pattern is closest to a square? [1-%d] ", len(ratios)))
+		if answer == "" {
+			return 0
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(ratios) {
+			return ratios[n-1]
+		}
+	}
+}
+
+// checkerboard draws a checkerboard of 2 by 2 modules in place of the code
+func checkerboard(next qrterminal.RenderFunc) qrterminal.RenderFunc {
+	return func(w io.Writer, _ *qrterminal.Bitmap) error {
+		board := qrterminal.NewBitmap(8)
+		for y := 0; y < board.Size; y++ {
+			for x := 0; x < board.Size; x++ {
+				board.Set(x, y, (x/2+y/2)%2 == 0)
+			}
+		}
+		return next(w, board)
+	}
+}
+
+// ask prints prompt and returns the next line of input, trimmed. The end
+// of the input reads as an empty answer.
+func ask(in *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+	}
+	return strings.TrimSpace(line)
+}
+
+// askYes asks a yes or no question, anything but yes is no
+func askYes(in *bufio.Reader, prompt string) bool {
+	answer := strings.ToLower(ask(in, prompt))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/cast"
+)
+
+// writeCast saves the rendering out of data as an asciinema recording at
+// path, in a terminal just large enough to show it
+func writeCast(path string, data []byte, cfg qrterminal.Config, out []byte) error {
+	width, height, err := qrterminal.RenderedSize(data, cfg)
+	if err != nil {
+		return err
+	}
+	env := map[string]string{}
+	for _, key := range []string{"TERM", "SHELL"} {
+		if v := os.Getenv(key); v != "" {
+			env[key] = v
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	c, err := cast.NewWriter(f, cast.Header{Width: width, Height: height, Timestamp: time.Now().Unix(), Env: env})
+	if err == nil {
+		_, err = c.Write(out)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// runCompare draws two payloads labeled A and B, side by side or one after
+// the other, and asks which of them scanned
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	level := qrterminal.L
+	fs.Var(&level, "l", levelUsage)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	var format qrterminal.Format
+	fs.Var(&format, "format", "output `format`: blocks, halfblocks or doublesize, and sixel with -alternate (default blocks)")
+	alternate := fs.Bool("alternate", false, "draw the codes one after the other instead of side by side")
+	jsonFlag := fs.String("json", "", "append the results to this `file`, one JSON object per code")
+	scannerFlag := fs.String("scanner", "", "name of the scanner app, recorded with the results")
+	showPayloadFlag := fs.Bool("show-payload", false, "record the payloads in the results in full, not only their length and hash")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s compare [flags] A B\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Draw two payloads and record which of them your scanner reads.\n")
+		fmt.Fprintf(fs.Output(), "Give the same payload twice to compare scanner apps or flags.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := qrterminal.Config{
+		Level:     level,
+		Writer:    os.Stdout,
+		QuietZone: *quietZoneFlag,
+		Format:    format,
+		Profile:   qrterminal.DetectProfileFromEnv(),
+	}
+	if format == "" || format == qrterminal.FormatBlocks || format == qrterminal.FormatDoubleSize {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+	mode := string(format)
+	if mode == "" {
+		mode = string(qrterminal.FormatBlocks)
+	}
+
+	labels := []qrterminal.Label{
+		{Data: []byte(fs.Arg(0)), Caption: "A"},
+		{Data: []byte(fs.Arg(1)), Caption: "B"},
+	}
+	log := resultLog{path: *jsonFlag, scanner: *scannerFlag, showPayload: *showPayloadFlag}
+	in := bufio.NewReader(os.Stdin)
+	scanned := make([]bool, len(labels))
+	if *alternate {
+		for i, l := range labels {
+			fmt.Printf("\n%s:\n", l.Caption)
+			qrterminal.GenerateBinaryWithConfig(l.Data, cfg)
+			scanned[i] = askYes(in, fmt.Sprintf("Did %s scan? [y/N] ", l.Caption))
+		}
+	} else {
+		fmt.Println()
+		if err := qrterminal.GenerateSideBySide(labels, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "compare:", err)
+			os.Exit(1)
+		}
+		for {
+			answer := strings.ToLower(ask(in, "Which scanned? [a/b/both/none] "))
+			switch answer {
+			case "a", "b", "both", "none", "":
+				scanned[0] = answer == "a" || answer == "both"
+				scanned[1] = answer == "b" || answer == "both"
+			default:
+				continue
+			}
+			break
+		}
+	}
+	for i, l := range labels {
+		log.record(mode, l.Caption, l.Data, scanned[i])
+	}
+}
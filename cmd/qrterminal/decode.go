@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/manualcode"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// backupLine matches a line of a backup code as -backup-text prints it, in
+// groups of four characters, with the digits manualcode.Decode reads as
+// the letters they are mistaken for
+var backupLine = regexp.MustCompile(`^(?:[A-Z2-7018]{4}[ -])+[A-Z2-7018]{1,4}$`)
+
+// isBackupCode reports whether every line of data is shaped like a line of
+// a backup code
+func isBackupCode(data []byte) bool {
+	lines := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if !backupLine.MatchString(line) {
+			return false
+		}
+		lines++
+	}
+	return lines > 0
+}
+
+// runDecode prints the type and fields of the text of a code, as a
+// scanner or a decoder such as zbarimg read it
+func runDecode(args []string) {
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "write the type and fields as a JSON object")
+	textFlag := fs.Bool("text", false, "read the input as it is, even if it looks like a typed backup code")
+	fs.StringVar(&passphraseFileFlag, "passphrase-file", "", "open an encrypted envelope with the passphrase read from this file")
+	fs.StringVar(&verifyKeyFlag, "verify-key", "", "verify a signed envelope with this PEM encoded Ed25519 public key")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s decode [flags] [text]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Recognize the payload of a scanned code, or stdin, and print its fields:\n")
+		fmt.Fprintf(fs.Output(), "URLs, WiFi networks, otpauth URIs, vCards, EPC payments and geo URIs.\n")
+		fmt.Fprintf(fs.Output(), "Encrypted, signed and expiring envelopes are opened first, and a backup code\n")
+		fmt.Fprintf(fs.Output(), "typed in from -backup-text output is checked and decoded.\n")
+		fmt.Fprintf(fs.Output(), "Images and -format bits output are not decoded, pipe in the text a\ndecoder read, e.g.\n")
+		fmt.Fprintf(fs.Output(), "zbarimg -q --raw code.png | %s decode\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var data, raw []byte
+	if fs.NArg() > 0 {
+		data = []byte(strings.Join(fs.Args(), " "))
+		raw = data
+	} else {
+		var err error
+		raw, err = readStdin(defaultMaxStdin)
+		if err != nil {
+			exitStdinError(err)
+		}
+		// Decoders end their output with a newline that is not part of
+		// the payload
+		data = bytes.TrimSuffix(bytes.TrimSuffix(raw, []byte("\n")), []byte("\r"))
+	}
+
+	if _, err := qrterminal.ParseBits(string(data)); err == nil && bytes.IndexByte(data, '\n') >= 0 {
+		fmt.Fprintln(os.Stderr, "decode: the input is a bits grid, not the text of a code; decode an image of it instead")
+		os.Exit(1)
+	}
+
+	if !*textFlag && isBackupCode(data) {
+		decoded, err := manualcode.Decode(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "decode: backup code: %v; check it for typos, or give -text to read the input as it is\n", err)
+			os.Exit(1)
+		}
+		data, raw = decoded, decoded
+	}
+
+	kinds, opened, err := openEnvelopes(data)
+	if err != nil && len(raw) != len(data) {
+		// The newline stripped above may have been the last byte of the
+		// envelope
+		if k, o, rawErr := openEnvelopes(raw); rawErr == nil {
+			kinds, opened, err = k, o, nil
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "decode:", err)
+		os.Exit(1)
+	}
+	var envelopes []string
+	for _, kind := range kinds {
+		envelopes = append(envelopes, envelopeNames[kind])
+	}
+
+	p, err := payload.Parse(opened)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "decode:", err)
+		os.Exit(1)
+	}
+	if *jsonFlag {
+		out := struct {
+			Envelopes []string       `json:"envelopes,omitempty"`
+			Type      payload.Type   `json:"type"`
+			Payload   payload.Parsed `json:"payload"`
+		}{envelopes, p.Type(), p}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(out)
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	if len(envelopes) > 0 {
+		fmt.Fprintf(tw, "Envelope:\t%s\n", strings.Join(envelopes, ", "))
+	}
+	fmt.Fprintf(tw, "Type:\t%s\n", p.Type())
+	for _, f := range p.Fields() {
+		// Keep multi-line values, such as notes, in the value column
+		value := strings.ReplaceAll(f.Value, "\n", "\n\t")
+		fmt.Fprintf(tw, "%s:\t%s\n", f.Name, value)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3/batch"
+	"golang.org/x/term"
+)
+
+// lineEditor reads entries typed at the terminal on stdin with a line
+// editor, one per Enter. The terminal brackets pastes, so a paste of
+// several lines is read as one entry, up to the Enter that follows it,
+// instead of as an entry per line.
+type lineEditor struct {
+	fd     int
+	t      *term.Terminal
+	prompt string
+}
+
+// newLineEditor starts reading entries from the terminal on stdin
+func newLineEditor(prompt string) *lineEditor {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stderr}, prompt)
+	fd := int(os.Stdin.Fd())
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		t.SetSize(width, height)
+	}
+	return &lineEditor{fd: fd, t: t, prompt: prompt}
+}
+
+// readEntry reads the next entry, reporting whether it is a paste of
+// several lines. Ctrl-C and Ctrl-D on an empty line return io.EOF. The
+// terminal is only in raw mode and bracketing pastes while the entry is
+// read, so output in between is drawn as usual and an exit leaves the
+// terminal as it was.
+func (e *lineEditor) readEntry() (entry string, multiline bool, err error) {
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to read the terminal: %v", err)
+	}
+	defer term.Restore(e.fd, state)
+	e.t.SetBracketedPasteMode(true)
+	defer e.t.SetBracketedPasteMode(false)
+	defer e.t.SetPrompt(e.prompt)
+
+	var lines []string
+	for {
+		line, err := e.t.ReadLine()
+		if err == term.ErrPasteIndicator {
+			lines = append(lines, line)
+			e.t.SetPrompt("")
+			continue
+		}
+		if err == io.EOF {
+			fmt.Fprint(os.Stderr, "\r\n")
+			return "", false, io.EOF
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("unable to read the terminal: %v", err)
+		}
+		// A paste that ended with a newline is followed by an empty line
+		if line != "" || len(lines) == 0 {
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n"), len(lines) > 1, nil
+	}
+}
+
+// read reads the next entry. A paste of several lines is only returned
+// once the user confirms it is meant as one entry, having seen its size.
+func (e *lineEditor) read() (string, error) {
+	for {
+		entry, multiline, err := e.readEntry()
+		if err != nil || !multiline {
+			return entry, err
+		}
+		e.t.SetPrompt(fmt.Sprintf("Pasted %d bytes in %d lines, encode them as one code? [y/N] ", len(entry), strings.Count(entry, "\n")+1))
+		answer, _, err := e.readEntry()
+		if err != nil {
+			return "", err
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+			return entry, nil
+		}
+	}
+}
+
+// promptLine asks a user who runs the command without arguments at a
+// terminal for the text, rather than leaving them waiting for end of file
+func promptLine(max int64) ([]byte, error) {
+	entry, err := newLineEditor("Text to encode: ").read()
+	if err == io.EOF {
+		return nil, errInterrupted
+	}
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 && int64(len(entry)) > max {
+		return nil, fmt.Errorf("the input is larger than %d bytes, raise -max-stdin-bytes to encode it anyway", max)
+	}
+	return []byte(entry), nil
+}
+
+// editorRecords reads batch records typed at the terminal, one per entry
+// of a lineEditor, so a pasted payload of several lines is one record
+// rather than one per line
+type editorRecords struct {
+	e *lineEditor
+}
+
+func (r editorRecords) Read() (batch.Record, error) {
+	entry, err := r.e.read()
+	if err != nil {
+		return nil, err
+	}
+	return batch.Record{batch.FrameField: entry}, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+var passphraseFileFlag string
+var signKeyFlag string
+var verifyKeyFlag string
+var expiresFlag time.Duration
+
+// envelopeRequested reports whether the input should be wrapped in an envelope
+func envelopeRequested() bool {
+	return passphraseFileFlag != "" || signKeyFlag != "" || expiresFlag > 0
+}
+
+// wrapEnvelopes limits the validity, signs and then encrypts data as
+// requested on the command line. Signing after setting the expiry keeps the
+// validity window from being altered, and encrypting last hides the
+// signature.
+func wrapEnvelopes(data []byte) ([]byte, error) {
+	if expiresFlag > 0 {
+		now := time.Now()
+		data = payload.Expire(data, now, now.Add(expiresFlag))
+	}
+	if signKeyFlag != "" {
+		priv, err := loadPrivateKey(signKeyFlag)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load signing key: %v", err)
+		}
+		data = payload.Sign(data, priv)
+	}
+	if passphraseFileFlag != "" {
+		passphrase, err := os.ReadFile(passphraseFileFlag)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read passphrase: %v", err)
+		}
+		secret := payload.Secret(bytes.TrimRight(passphrase, "\r\n"))
+		data, err = payload.Encrypt(data, secret)
+		secret.Zero()
+		if err != nil {
+			return nil, fmt.Errorf("unable to encrypt input: %v", err)
+		}
+	}
+	return data, nil
+}
+
+// verifyEnvelope checks a scanned signed envelope against the key given
+// with -verify-key and returns the signed data. An expiring envelope inside
+// the signature is checked against the current time as well.
+func verifyEnvelope(env []byte) ([]byte, error) {
+	data, err := verifySignature(env)
+	if err != nil {
+		return nil, err
+	}
+	if kind, _ := payload.KindOf(data); kind == payload.KindExpiring {
+		data, err = payload.CheckExpiry(data, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("verification failed: %v", err)
+		}
+	}
+	return data, nil
+}
+
+// verifySignature checks a signed envelope against the key given with
+// -verify-key and returns the signed data
+func verifySignature(env []byte) ([]byte, error) {
+	if verifyKeyFlag == "" {
+		return nil, errors.New("the code is signed, check it with -verify-key")
+	}
+	// Verify would only say the signature is wrong for a code that was
+	// encrypted or never signed
+	if kind, err := payload.KindOf(env); err != nil {
+		return nil, fmt.Errorf("verification failed: the code is not an envelope: %v", err)
+	} else if kind != payload.KindSigned {
+		return nil, fmt.Errorf("verification failed: the code is %s, not signed", envelopeName(kind))
+	}
+	pub, err := loadPublicKey(verifyKeyFlag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load verification key: %v", err)
+	}
+	data, err := payload.Verify(env, pub)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %v", err)
+	}
+	return data, nil
+}
+
+// envelopeNames are the names decode prints for the kinds of envelopes
+var envelopeNames = map[payload.Kind]string{
+	payload.KindEncrypted: "encrypted",
+	payload.KindSigned:    "signed",
+	payload.KindExpiring:  "expiring",
+}
+
+// envelopeName returns the name of kind for messages
+func envelopeName(kind payload.Kind) string {
+	if name := envelopeNames[kind]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("an envelope of unknown kind %q", byte(kind))
+}
+
+// isEnvelope reports whether data starts with the header of an envelope
+func isEnvelope(data []byte) bool {
+	kind, err := payload.KindOf(data)
+	return err == nil && envelopeNames[kind] != ""
+}
+
+// openEnvelopes unwraps the envelopes of a scanned code, the reverse of
+// wrapEnvelopes, with the keys given by -passphrase-file and -verify-key.
+// It returns the data inside and the kinds of the envelopes opened. A key
+// that is given demands its envelope, so unsigned data is not taken for
+// verified and plain data not for a secret.
+func openEnvelopes(data []byte) ([]payload.Kind, []byte, error) {
+	var kinds []payload.Kind
+	for isEnvelope(data) {
+		kind, _ := payload.KindOf(data)
+		var err error
+		switch kind {
+		case payload.KindEncrypted:
+			data, err = decryptEnvelope(data)
+		case payload.KindSigned:
+			data, err = verifySignature(data)
+		case payload.KindExpiring:
+			data, err = payload.CheckExpiry(data, time.Now())
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		kinds = append(kinds, kind)
+	}
+	if passphraseFileFlag != "" && !hasKind(kinds, payload.KindEncrypted) {
+		return nil, nil, errors.New("the code is not encrypted, but -passphrase-file was given")
+	}
+	if verifyKeyFlag != "" && !hasKind(kinds, payload.KindSigned) {
+		return nil, nil, errors.New("verification failed: the code is not signed")
+	}
+	return kinds, data, nil
+}
+
+// hasKind reports whether kinds holds kind
+func hasKind(kinds []payload.Kind, kind payload.Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptEnvelope opens a scanned encrypted envelope with the passphrase
+// in the file given with -passphrase-file
+func decryptEnvelope(env []byte) ([]byte, error) {
+	if passphraseFileFlag == "" {
+		return nil, errors.New("the code is encrypted, open it with -passphrase-file")
+	}
+	passphrase, err := os.ReadFile(passphraseFileFlag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read passphrase: %v", err)
+	}
+	secret := payload.Secret(bytes.TrimRight(passphrase, "\r\n"))
+	defer secret.Zero()
+	data, err := payload.Decrypt(env, secret)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// estimateFormats are the renderers estimate reports the size of, in order
+var estimateFormats = []qrterminal.Format{
+	qrterminal.FormatBlocks,
+	qrterminal.FormatHalfBlocks,
+	qrterminal.FormatDoubleSize,
+	qrterminal.FormatSixel,
+}
+
+// runEstimate reports, for every error correction level, the symbol the
+// input needs and the size it is drawn at by each renderer, without
+// drawing it
+func runEstimate(args []string) {
+	fs := flag.NewFlagSet("estimate", flag.ExitOnError)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s estimate [flags] [text]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Report the version and size of the code of the text, or stdin, at every level.\n")
+		fmt.Fprintf(fs.Output(), "Text formats are measured in columns x lines, sixel images in pixels and in\n")
+		fmt.Fprintf(fs.Output(), "columns x lines of 10x20 pixel cells.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var data []byte
+	if fs.NArg() > 0 {
+		data = []byte(strings.Join(fs.Args(), " "))
+	} else {
+		var err error
+		data, err = readStdin(defaultMaxStdin)
+		if err != nil {
+			exitStdinError(err)
+		}
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		fmt.Fprintln(os.Stderr, "Nothing to encode, the input is empty")
+		os.Exit(1)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "Level\tVersion\tModules")
+	for _, format := range estimateFormats {
+		fmt.Fprintf(tw, "\t%s", format)
+	}
+	fmt.Fprintln(tw)
+	fits := false
+	for _, level := range []qrterminal.Level{qrterminal.L, qrterminal.M, qrterminal.Q, qrterminal.H} {
+		cfg := qrterminal.Config{Level: level, QuietZone: *quietZoneFlag}
+		info, err := qrterminal.Info(data, cfg)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\ttoo long\n", level)
+			continue
+		}
+		fits = true
+		fmt.Fprintf(tw, "%s\t%d\t%dx%d", level, info.Version, info.Modules, info.Modules)
+		for _, format := range estimateFormats {
+			cfg.Format = format
+			cfg.BlackChar, cfg.WhiteChar = "", ""
+			if format == qrterminal.FormatBlocks || format == qrterminal.FormatDoubleSize {
+				// The glyphs the main command draws these formats with
+				cfg.BlackChar, cfg.WhiteChar = qrterminal.BLACK, qrterminal.WHITE
+			}
+			cells, lines, err := qrterminal.RenderedSize(data, cfg)
+			if err != nil {
+				fmt.Fprint(tw, "\t-")
+				continue
+			}
+			if format == qrterminal.FormatSixel {
+				w, h, _ := qrterminal.SixelPixels(data, cfg)
+				fmt.Fprintf(tw, "\t%dx%dpx (%dx%d)", w, h, cells, lines)
+				continue
+			}
+			fmt.Fprintf(tw, "\t%dx%d", cells, lines)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	if !fits {
+		fmt.Fprintln(os.Stderr, "The input is too long for a QR code at any level")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// runHook runs the shell command of -exec with stdin as its input and env
+// added to its environment. Its output goes to ours.
+func runHook(command string, stdin io.Reader, env ...string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.Run()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// runDOI draws the doi.org link of a DOI
+func runDOI(args []string) {
+	c := newBuilderCommand("doi", "DOI", "Draw the https://doi.org/ link of a DOI, given bare, as doi:10.1000/182 or as a link.")
+	d, err := payload.ParseDOI(c.parse(args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "doi:", err)
+		os.Exit(1)
+	}
+	c.draw(d)
+}
+
+// runISBN draws the Open Library link of an ISBN
+func runISBN(args []string) {
+	c := newBuilderCommand("isbn", "ISBN", "Draw the Open Library link of a book, by its ISBN-13 or ISBN-10, whose check digit is checked.")
+	i, err := payload.ParseISBN(c.parse(args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "isbn:", err)
+		os.Exit(1)
+	}
+	c.draw(i)
+}
+
+// runEAN draws the GS1 Digital Link of a product barcode number
+func runEAN(args []string) {
+	c := newBuilderCommand("ean", "CODE", "Draw the GS1 Digital Link of a product, by its EAN-13, EAN-8, UPC-A or GTIN-14, whose check digit is checked.")
+	e, err := payload.ParseEAN(c.parse(args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ean:", err)
+		os.Exit(1)
+	}
+	c.draw(e)
+}
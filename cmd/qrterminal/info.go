@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// printInfo writes the -dry-run report, one "Name: value" line per fact
+func printInfo(w io.Writer, info qrterminal.EncodeInfo) {
+	fmt.Fprintf(w, "Symbology: %s\n", info.Symbology)
+	if info.Version > 0 {
+		fmt.Fprintf(w, "Version: %d\n", info.Version)
+	}
+	fmt.Fprintf(w, "Level: %s\n", info.Level)
+	if info.Mode != "" {
+		fmt.Fprintf(w, "Mode: %s\n", info.Mode)
+	}
+	fmt.Fprintf(w, "Modules: %dx%d\n", info.Modules, info.Modules)
+	if info.CapacityBits > 0 {
+		fmt.Fprintf(w, "Capacity: %d of %d bits used (%.0f%%)\n", info.DataBits, info.CapacityBits, 100*info.Utilization())
+	}
+	fmt.Fprintf(w, "Format: %s\n", info.Format)
+	if info.RenderMode != "" {
+		fmt.Fprintf(w, "Render mode: %s\n", info.RenderMode)
+	}
+	if info.Lines > 0 {
+		fmt.Fprintf(w, "Size: %d columns, %d lines\n", info.Cells, info.Lines)
+	}
+}
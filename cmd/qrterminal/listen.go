@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/batch"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// stringsFlag collects the values of a repeatable flag
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// listenCacheSize is the number of recent payloads whose codes listen
+// keeps, so a daemon cycling through a few codes does not encode them anew
+const listenCacheSize = 8
+
+// runListen accepts payloads on a UNIX socket and draws each one in place
+// of the previous one, so daemons can show codes on this terminal. Under
+// systemd the socket may be passed with socket activation, and readiness
+// is reported with sd_notify.
+func runListen(args []string) {
+	fs := flag.NewFlagSet("listen", flag.ExitOnError)
+	level := qrterminal.L
+	fs.Var(&level, "l", levelUsage)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	var format qrterminal.Format
+	fs.Var(&format, "format", "output `format`: "+formatNames()+" (default blocks)")
+	socketFlag := fs.String("socket", "", "`path` of the UNIX socket to listen on, unless systemd passes one with socket activation")
+	modeFlag := fs.String("socket-mode", "0600", "permissions of the socket, in octal")
+	var allowPrefixes stringsFlag
+	fs.Var(&allowPrefixes, "allow-prefix", "only draw payloads starting with this `prefix`, e.g. https://example.com/pair/ (repeatable)")
+	allowRegexpFlag := fs.String("allow-regexp", "", "only draw payloads matching this regular expression in full")
+	refreshFlag := fs.Duration("refresh", 0, "redraw the current code this often even if no new payload arrives, e.g. 1m, to repair a screen other output drew over")
+	framingFlag := fs.String("framing", "line", "how payloads are separated: line (newline delimited), null (NUL delimited), len (each preceded by a 4 byte big-endian length) or eof (one per connection)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s listen [-socket PATH] [flags]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Draw every payload sent to the socket, replacing the code shown before.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	mode, err := strconv.ParseUint(*modeFlag, 8, 32)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "listen: invalid -socket-mode %q\n", *modeFlag)
+		os.Exit(1)
+	}
+	if _, err := batch.NewFramedReader(*framingFlag, nil); err != nil {
+		fmt.Fprintln(os.Stderr, "listen:", err)
+		os.Exit(1)
+	}
+
+	var allow *payload.Allowlist
+	if len(allowPrefixes) > 0 || *allowRegexpFlag != "" {
+		allow = &payload.Allowlist{Prefixes: allowPrefixes}
+		if *allowRegexpFlag != "" {
+			allow.Pattern, err = regexp.Compile("^(?:" + *allowRegexpFlag + ")$")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "listen: -allow-regexp:", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	cfg := qrterminal.Config{
+		Level:     level,
+		Writer:    os.Stdout,
+		QuietZone: *quietZoneFlag,
+		Format:    format,
+		Profile:   qrterminal.DetectProfileFromEnv(),
+		Cache:     qrterminal.NewEncodeCache(listenCacheSize),
+	}
+	if format == "" || format == qrterminal.FormatBlocks {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+
+	l, err := activationListener()
+	if err == nil && l == nil {
+		if *socketFlag == "" {
+			fmt.Fprintln(os.Stderr, "listen: -socket is required without socket activation")
+			os.Exit(1)
+		}
+		l, err = listenSocket(*socketFlag, os.FileMode(mode))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "listen:", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	d := &display{w: os.Stdout, config: cfg, allow: allow}
+	d.clear()
+	if err := sdNotify("READY=1"); err != nil {
+		fmt.Fprintln(os.Stderr, "listen: notifying the service manager:", err)
+	}
+	if *refreshFlag > 0 {
+		go d.refresh(ctx, *refreshFlag)
+	}
+	var wg sync.WaitGroup
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintln(os.Stderr, "listen:", err)
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			if err := d.serve(conn, *framingFlag); err != nil {
+				fmt.Fprintln(os.Stderr, "listen:", err)
+			}
+		}()
+	}
+	sdNotify("STOPPING=1")
+	wg.Wait()
+}
+
+// listenSocket listens on the UNIX socket at path with the permissions
+// mode. A socket left behind by an earlier run is replaced, any other file
+// is not.
+func listenSocket(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	// Created with the umask, the socket could be connected to before the
+	// Chmod below
+	restore := restrictUmask()
+	l, err := net.Listen("unix", path)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// display draws one code at a time on a terminal, each replacing the last
+type display struct {
+	mu     sync.Mutex
+	w      io.Writer
+	config qrterminal.Config
+	allow  *payload.Allowlist
+	// shown is the code on screen and sum the hash of its payload, a
+	// payload with the same hash is not drawn again
+	shown []byte
+	sum   [sha256.Size]byte
+}
+
+// clear homes the cursor and clears the screen
+func (d *display) clear() {
+	io.WriteString(d.w, "\033[H\033[2J")
+}
+
+// serve draws every payload read from r with the framing
+func (d *display) serve(r io.Reader, framing string) error {
+	frames, err := batch.NewFramedReader(framing, r)
+	if err != nil {
+		return err
+	}
+	for {
+		rec, err := frames.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data := []byte(rec[batch.FrameField].(string))
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if err := d.draw(data); err != nil {
+			fmt.Fprintln(os.Stderr, "listen:", err)
+		}
+	}
+}
+
+// draw replaces the code on screen with the code of data, unless data is
+// the payload already shown. The code is rendered before the screen is
+// cleared, so data that does not fit or is not allowed leaves the previous
+// code in place.
+func (d *display) draw(data []byte) error {
+	if err := d.allow.Check(data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	d.mu.Lock()
+	unchanged := d.shown != nil && sum == d.sum
+	d.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	cfg := d.config
+	cfg.Writer = &buf
+	if err := qrterminal.GenerateFromReader(bytes.NewReader(data), 0, cfg); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.shown, d.sum = buf.Bytes(), sum
+	return d.redraw()
+}
+
+// redraw draws the code on screen again, d.mu must be held
+func (d *display) redraw() error {
+	d.clear()
+	_, err := d.w.Write(d.shown)
+	return err
+}
+
+// refresh redraws the code on screen every interval until ctx is done
+func (d *display) refresh(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			d.mu.Lock()
+			if d.shown != nil {
+				d.redraw()
+			}
+			d.mu.Unlock()
+		}
+	}
+}
@@ -1,50 +1,208 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
 	"github.com/mattn/go-colorable"
-	"rsc.io/qr"
+	"golang.org/x/term"
 )
 
 var verboseFlag bool
-var levelFlag string
+var levelFlag qrterminal.Level
 var quietZoneFlag int
 var sixelDisableFlag bool
 var binaryFlag bool
+var fallbackFlag string
+var formatFlag qrterminal.Format
+var bitsGroupFlag int
+var themeFlag string
+var aspectRatioFlag float64
+var hyperlinkFlag bool
+var backupTextFlag bool
+var debugOverlayFlag bool
+var forceFlag bool
+var lowMemoryFlag bool
+var strictFlag bool
+var showPayloadFlag bool
+var widthFlag, heightFlag int
+var pageFlag string
+var marginFlag float64
+var captionFlag string
+var dotsFlag int
+var overstrikeFlag string
+var doubleStrikeFlag bool
+var symbologyFlag string
+var backdropFlag bool
+var transparentFlag bool
+var execFlag string
+var asciinemaFlag bool
+var castFlag string
+var dryRunFlag bool
+var urlFlag bool
+var teeFlags teeFlag
+var maxStdinFlag int64
 
-func getLevel(s string) qr.Level {
-	switch l := strings.ToLower(s); l {
-	case "l":
-		return qr.L
-	case "m":
-		return qr.M
-	case "h":
-		return qr.H
-	default:
-		return -1
+// levelUsage is the help of the -l flags, L is the zero Level so the flag
+// package does not print it as the default
+const levelUsage = "Error correction `level`: L, M, Q or H (default L)"
+
+// formatNames lists the available output formats for help and error messages
+func formatNames() string {
+	var names []string
+	for _, f := range qrterminal.Formats() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+func validSymbology(name string) bool {
+	for _, known := range qrterminal.Symbologies() {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// subcommands are the built in commands, each run with the arguments that
+// follow its name. Text that is the name of one is encoded when it follows
+// "--".
+var subcommands = map[string]func(args []string){
+	"preview":   runPreview,
+	"sheet":     runSheet,
+	"batch":     runBatch,
+	"listen":    runListen,
+	"calibrate": runCalibrate,
+	"compare":   runCompare,
+	"estimate":  runEstimate,
+	"decode":    runDecode,
+	"bitcoin":   runBitcoin,
+	"ethereum":  runEthereum,
+	"lightning": runLightning,
+	"doi":       runDOI,
+	"isbn":      runISBN,
+	"ean":       runEAN,
+}
+
+// subcommandNames returns the names of the built in commands, sorted
+func subcommandNames() []string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
+	saved, settingsErr := loadSettings()
+	if settingsErr != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring the saved settings: %v\n", settingsErr)
+	}
+	formatFlag, themeFlag, backdropFlag, aspectRatioFlag = saved.Format, saved.Theme, saved.Backdrop, saved.AspectRatio
+
 	flag.BoolVar(&verboseFlag, "v", false, "Output debugging information")
-	flag.StringVar(&levelFlag, "l", "L", "Error correction level")
+	flag.BoolVar(&stdinSecretFlag, "stdin-secret", false, "read a secret payload from stdin or a prompt that does not show it, never from the command line, and wipe it once drawn")
+	flag.IntVar(&secretFDFlag, "secret-fd", 0, "read the -stdin-secret payload from this file `descriptor` instead, e.g. 3 with 3< seed.txt")
+	flag.BoolVar(&showPayloadFlag, "show-payload", false, "print the input in full in -v output, not only its length and hash")
+	flag.Var(&levelFlag, "l", levelUsage)
 	flag.IntVar(&quietZoneFlag, "q", 2, "Size of quietzone border")
 	flag.BoolVar(&sixelDisableFlag, "s", false, "disable sixel format for output")
 	flag.BoolVar(&binaryFlag, "b", false, "treat input as binary data (preserves exact byte values)")
+	flag.Var(&formatFlag, "format", "output `format`: "+formatNames()+" (default blocks, or sixel when supported)")
+	flag.IntVar(&bitsGroupFlag, "bits-group", 0, "separate the digits of the bits format into groups of this size")
+	flag.StringVar(&symbologyFlag, "symbology", qrterminal.SymbologyQR, "symbology to encode with: "+strings.Join(qrterminal.Symbologies(), ", "))
+	flag.BoolVar(&backdropFlag, "backdrop", backdropFlag, "draw the text formats dark on a white background, for terminals with a dark background")
+	flag.BoolVar(&transparentFlag, "transparent", false, "draw only the black modules of the text formats and leave the rest to the terminal background, which must be light")
+	flag.Float64Var(&aspectRatioFlag, "aspect-ratio", aspectRatioFlag, "width of a terminal cell divided by its height, to draw square modules with the blocks format in fonts with unusual metrics, e.g. 0.45")
+	flag.StringVar(&themeFlag, "theme", themeFlag, "draw the blocks format with a built in theme: shade, ascii")
+	flag.StringVar(&pageFlag, "page", "a4", "page size of the pdf format: a4 or letter")
+	flag.Float64Var(&marginFlag, "margin", 36, "page margin of the pdf format in points (1/72 inch)")
+	flag.StringVar(&captionFlag, "caption", "", "text printed under the code by the pdf format")
+	flag.IntVar(&dotsFlag, "dots", 4, "size of a module in printer dots for the zpl and epl formats")
+	flag.StringVar(&overstrikeFlag, "overstrike", "M", "`characters` struck on top of each other for a module by the lineprinter format, e.g. MW#")
+	flag.BoolVar(&doubleStrikeFlag, "double-strike", false, "strike every line twice in the lineprinter format")
+	flag.BoolVar(&hyperlinkFlag, "hyperlink", true, "print a clickable link under the code for URLs when the terminal supports it")
+	flag.BoolVar(&backupTextFlag, "backup-text", false, "print the input under the code as a checksummed Base32 backup code, to type in when scanning fails")
+	flag.StringVar(&execFlag, "exec", "", "pipe the rendered code to this shell command instead of printing it, e.g. 'lpr'")
+	flag.BoolVar(&debugOverlayFlag, "debug-overlay", false, "color the finder, timing and alignment patterns and the format and version information apart from the data, in the blocks and halfblocks formats")
+	flag.BoolVar(&asciinemaFlag, "asciinema", false, "keep to output asciinema records faithfully: no sixel, no hyperlinks (automatic while recording)")
+	flag.StringVar(&castFlag, "cast", "", "write the code to this `file` as an asciinema v2 recording instead of printing it")
+	flag.BoolVar(&lowMemoryFlag, "low-memory", false, "keep memory use down on small devices: write lines as they are drawn and skip the -debug-overlay map")
+	flag.BoolVar(&strictFlag, "strict", false, "fail instead of writing glyphs that are not ASCII where only ASCII is shown reliably, such as CI logs")
+	flag.BoolVar(&forceFlag, "force", false, "print the code even if it is wider than the terminal, which wraps it")
+	flag.IntVar(&widthFlag, "width", 0, "`columns` of the terminal, overriding $COLUMNS and the size the terminal reports")
+	flag.IntVar(&heightFlag, "height", 0, "`lines` of the terminal, overriding $LINES and the size the terminal reports")
+	flag.BoolVar(&dryRunFlag, "dry-run", false, "encode the input and report the symbol and its size without drawing it, exit with an error if it does not fit")
+	flag.BoolVar(&urlFlag, "url", false, "check that the input is a web URL and normalize it, warning about hosts a phone can not reach")
+	flag.Var(&teeFlags, "tee", "also write the code to this `file`, in another format if preceded by one and a colon, e.g. pdf:code.pdf (repeatable)")
+	flag.Int64Var(&maxStdinFlag, "max-stdin-bytes", defaultMaxStdin, "refuse input from stdin longer than this many bytes, 0 for no limit")
+	flag.StringVar(&fallbackFlag, "fallback", "", "treat the input as an app deep link and wrap it in this https fallback URL")
+	flag.StringVar(&passphraseFileFlag, "passphrase-file", "", "encrypt the input with the passphrase read from this file")
+	flag.StringVar(&signKeyFlag, "sign-key", "", "sign the input with this PEM encoded Ed25519 private key")
+	flag.DurationVar(&expiresFlag, "expires", 0, "make the code expire after this duration, e.g. 5m")
+	flag.StringVar(&verifyKeyFlag, "verify-key", "", "verify a scanned signed envelope with this PEM encoded Ed25519 public key and print its data")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags] [--] [text]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s COMMAND [flags] [args]\n\n", os.Args[0])
+		fmt.Fprintf(out, "Draw the text, or stdin, as a QR code. Text that is the name of a command\n")
+		fmt.Fprintf(out, "is drawn when it follows --, e.g. %s -- bitcoin\n\n", os.Args[0])
+		fmt.Fprintf(out, "Commands: %s\n\n", strings.Join(subcommandNames(), ", "))
+		flag.PrintDefaults()
+	}
 
 	flag.Parse()
-	level := getLevel(levelFlag)
+	level, format := levelFlag, formatFlag
 
-	if level < 0 {
-		fmt.Fprintf(os.Stderr, "Invalid error correction level: %s\n", levelFlag)
-		fmt.Fprintf(os.Stderr, "Valid options are [L, M, H]\n")
+	// A first argument that is not a built in subcommand runs the plugin
+	// of that name, if there is one, unless it follows "--"
+	if flag.NArg() > 0 && os.Args[len(os.Args)-flag.NArg()-1] != "--" {
+		if path := findPlugin(flag.Arg(0)); path != "" {
+			runPlugin(path, flag.Args()[1:])
+		}
+	}
+
+	if !validSymbology(symbologyFlag) {
+		fmt.Fprintf(os.Stderr, "Invalid symbology: %s\n", symbologyFlag)
+		fmt.Fprintf(os.Stderr, "Valid options are [%s]\n", strings.Join(qrterminal.Symbologies(), ", "))
+		os.Exit(1)
+	}
+
+	theme := qrterminal.ThemeByName(themeFlag)
+	if themeFlag != "" && theme == nil {
+		fmt.Fprintf(os.Stderr, "Invalid theme: %s\n", themeFlag)
+		os.Exit(1)
+	}
+
+	if castFlag != "" && execFlag != "" {
+		fmt.Fprintln(os.Stderr, "-cast and -exec can not be combined")
+		os.Exit(1)
+	}
+	if castFlag != "" && format.Document() {
+		fmt.Fprintf(os.Stderr, "The %s format can not be recorded with -cast\n", format)
+		os.Exit(1)
+	}
+
+	page, ok := getPageSize(pageFlag)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid page size: %s\n", pageFlag)
 		os.Exit(1)
 	}
 
@@ -53,11 +211,32 @@ func main() {
 	var err error
 
 	args := flag.Args()
-	if len(args) < 1 {
-		// Get input from stdin until EOF
-		binaryData, err = io.ReadAll(os.Stdin)
+	var secret payload.Secret
+	if secretFDFlag > 0 {
+		stdinSecretFlag = true
+	}
+	if stdinSecretFlag {
+		if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "-stdin-secret: refusing to take the payload from the command line, where the shell history and the process list keep it")
+			os.Exit(1)
+		}
+		secret, err = readSecret(maxStdinFlag)
 		if err != nil {
-			panic(err)
+			exitStdinError(err)
+		}
+		// Encoded as is, without being turned into a string that can not
+		// be wiped
+		binaryData, binaryFlag = secret, true
+	} else if len(args) < 1 {
+		// Ask a user at a terminal for the text, otherwise read stdin
+		// until EOF
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			binaryData, err = promptLine(maxStdinFlag)
+		} else {
+			binaryData, err = readStdin(maxStdinFlag)
+		}
+		if err != nil {
+			exitStdinError(err)
 		}
 		if !binaryFlag {
 			content = string(binaryData)
@@ -69,15 +248,145 @@ func main() {
 		}
 	}
 
+	empty := strings.TrimSpace(content) == ""
+	switch {
+	case stdinSecretFlag:
+		empty = len(bytes.TrimSpace(binaryData)) == 0
+	case binaryFlag:
+		// Whitespace bytes are binary data like any other
+		empty = len(binaryData) == 0
+	}
+	if empty {
+		fmt.Fprintln(os.Stderr, "Nothing to encode, the input is empty")
+		os.Exit(1)
+	}
+
+	// Both work on strings, which can not be wiped
+	if stdinSecretFlag && (urlFlag || fallbackFlag != "") {
+		fmt.Fprintln(os.Stderr, "-stdin-secret can not be combined with -url or -fallback")
+		os.Exit(1)
+	}
+
+	if urlFlag {
+		raw := content
+		if binaryFlag {
+			raw = string(binaryData)
+		}
+		normalized, warnings, err := payload.URL(raw, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+		content, binaryData = normalized, []byte(normalized)
+	}
+
+	if fallbackFlag != "" {
+		link := content
+		if binaryFlag {
+			link = string(binaryData)
+		}
+		content, err = payload.DeepLink(strings.TrimSpace(link), fallbackFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		binaryData = []byte(content)
+	}
+
+	if verifyKeyFlag != "" {
+		if !binaryFlag {
+			binaryData = []byte(content)
+		}
+		data, err := verifyEnvelope(binaryData)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+
+	if envelopeRequested() {
+		if !binaryFlag {
+			binaryData = []byte(content)
+		}
+		binaryData, err = wrapEnvelopes(binaryData)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		// Envelopes are binary, so they are always encoded as such
+		binaryFlag = true
+	}
+
+	data := binaryData
+	if !binaryFlag {
+		data = []byte(content)
+	}
+
 	cfg := qrterminal.Config{
-		Level:     level,
-		Writer:    os.Stdout,
-		QuietZone: quietZoneFlag,
-		BlackChar: qrterminal.BLACK,
-		WhiteChar: qrterminal.WHITE,
+		Level:         level,
+		Writer:        os.Stdout,
+		QuietZone:     quietZoneFlag,
+		Format:        format,
+		BitsGroup:     bitsGroupFlag,
+		Profile:       qrterminal.DetectProfileFromEnv(),
+		Theme:         theme,
+		PDF:           &qrterminal.PDFOptions{Page: page, Margin: marginFlag, Caption: captionFlag},
+		DotsPerModule: dotsFlag,
+		LinePrinter:   &qrterminal.LinePrinterOptions{Overstrike: overstrikeFlag, DoubleStrike: doubleStrikeFlag},
+		Symbology:     symbologyFlag,
+		BackupText:    backupTextFlag,
+		AspectRatio:   aspectRatioFlag,
+		DebugOverlay:  debugOverlayFlag,
+		LowMemory:     lowMemoryFlag,
+		Strict:        strictFlag,
+	}
+	if asciinemaFlag || castFlag != "" {
+		cfg.Profile = qrterminal.ProfileAsciinema
+	}
+	if backdropFlag {
+		cfg.Backdrop = qrterminal.BackdropWhite
 	}
-	if !sixelDisableFlag {
-		cfg.WithSixel = qrterminal.IsSixelSupported(os.Stdout)
+	if transparentFlag {
+		cfg.Backdrop = qrterminal.BackdropTransparent
+	}
+	if theme == nil && cfg.Backdrop == nil && (format == "" || format == qrterminal.FormatBlocks || format == qrterminal.FormatDoubleSize) {
+		cfg.BlackChar = qrterminal.BLACK
+		cfg.WhiteChar = qrterminal.WHITE
+	}
+	// Output for -exec and -cast is not shown in this terminal, so it is
+	// not adapted to it
+	piped := execFlag != "" || castFlag != ""
+	if execFlag != "" {
+		cfg.Profile = nil
+	}
+	detectSixel := format == "" && theme == nil && !sixelDisableFlag && !piped && !debugOverlayFlag
+	detectHyperlink := hyperlinkFlag && format != qrterminal.FormatBits && !piped
+	// Profiles such as the Windows ones pick the format from what the
+	// terminal can draw
+	fallback := cfg.Profile != nil && len(cfg.Profile.Fallbacks) > 0 && format == "" && theme == nil && !piped && !debugOverlayFlag
+	if detectSixel || detectHyperlink || fallback {
+		// Only sixel support needs a query, which blocks until a terminal
+		// that does not answer times out
+		detect := qrterminal.DetectCapabilitiesFromEnv
+		if detectSixel {
+			detect = qrterminal.DetectCapabilities
+		}
+		caps := detect(os.Stdout)
+		caps.Sixel = detectSixel && caps.Sixel
+		caps.Hyperlink = detectHyperlink && caps.Hyperlink
+		cfg.WithSixel, cfg.Hyperlink = caps.Sixel, caps.Hyperlink
+		cfg.Capabilities = &caps
+	}
+	// The fallbacks pick a mode that fits the terminal, unless the size
+	// check is off
+	cols, lines := terminalSize()
+	if fallback && !forceFlag {
+		cfg.MaxColumns, cfg.MaxLines = cols, lines
 	}
 	if verboseFlag {
 		fmt.Fprintf(os.Stdout, "Level: %s \n", levelFlag)
@@ -86,22 +395,82 @@ func main() {
 		if binaryFlag {
 			fmt.Fprintf(os.Stdout, "Encoded data: %d bytes of binary data \n", len(binaryData))
 		} else {
-			fmt.Fprintf(os.Stdout, "Encoded data: %s \n", strings.Join(flag.Args(), "\n"))
+			fmt.Fprintf(os.Stdout, "Encoded data: %s \n", shownPayload([]byte(strings.Join(flag.Args(), "\n")), showPayloadFlag))
+		}
+		for _, w := range qrterminal.LintData(data, cfg) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
 		}
 		fmt.Println("")
 	}
 
-	if runtime.GOOS == "windows" {
-		cfg.Writer = colorable.NewColorableStdout()
-		cfg.BlackChar = qrterminal.BLACK
-		cfg.WhiteChar = qrterminal.WHITE
+	if dryRunFlag {
+		info, err := qrterminal.Info(data, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		printInfo(os.Stdout, info)
+		return
+	}
+
+	if !forceFlag && !piped && (widthFlag > 0 || term.IsTerminal(int(os.Stdout.Fd()))) {
+		if err := checkWidth(data, cfg, cols); err != nil {
+			fmt.Fprintln(os.Stderr, "Not printing the code:", err)
+			fmt.Fprintln(os.Stderr, "Widen the terminal, try -format halfblocks or a lower -l, or print it anyway with -force")
+			os.Exit(1)
+		}
+	}
+
+	outputs, closeOutputs, err := teeFlags.open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-tee: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Outputs = outputs
+
+	var rendered bytes.Buffer
+	if piped {
+		cfg.Writer = &rendered
 	}
 
-	fmt.Fprint(os.Stdout, "\n")
+	// Documents and printer languages are written as is, nothing may
+	// precede them
+	if !format.Document() && !piped {
+		if runtime.GOOS == "windows" {
+			cfg.Writer = colorable.NewColorableStdout()
+		}
+		fmt.Fprint(os.Stdout, "\n")
+	}
 
-	if binaryFlag {
-		qrterminal.GenerateBinaryWithConfig(binaryData, cfg)
+	if stdinSecretFlag {
+		err = qrterminal.GenerateSecret(data, cfg)
+		secret.Zero()
 	} else {
-		qrterminal.GenerateWithConfig(content, cfg)
+		err = qrterminal.GenerateFromReader(bytes.NewReader(data), 0, cfg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, qrterminal.ErrNotASCII) {
+			fmt.Fprintln(os.Stderr, "-strict: the terminal only shows ASCII, pick ASCII glyphs and labels or drop -strict")
+		}
+		closeOutputs()
+		os.Exit(1)
+	}
+	if err := closeOutputs(); err != nil {
+		fmt.Fprintf(os.Stderr, "-tee: %v\n", err)
+		os.Exit(1)
+	}
+
+	if castFlag != "" {
+		if err := writeCast(castFlag, data, cfg, rendered.Bytes()); err != nil {
+			fmt.Fprintf(os.Stderr, "-cast: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if execFlag != "" {
+		if err := runHook(execFlag, &rendered); err != nil {
+			fmt.Fprintf(os.Stderr, "-exec: %v\n", err)
+			os.Exit(1)
+		}
 	}
 }
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/batch"
+	"github.com/katzenpost/qrterminal/v3/manualcode"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// TestMain runs the command instead of the tests when the test binary is
+// started by run
+func TestMain(m *testing.M) {
+	if os.Getenv("QRTERMINAL_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// command returns the command with args and stdin, in a clean environment
+func command(t *testing.T, stdin string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], args...)
+	dir := t.TempDir()
+	cmd.Env = []string{"QRTERMINAL_TEST_MAIN=1", "HOME=" + dir, "XDG_CONFIG_HOME=" + dir, "TERM=dumb"}
+	cmd.Stdin = strings.NewReader(stdin)
+	return cmd
+}
+
+// run runs the command with args and stdin, and returns its output and
+// exit code
+func run(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	return runCommand(t, command(t, stdin, args...))
+}
+
+func runCommand(t *testing.T, cmd *exec.Cmd) (stdout, stderr string, code int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("Running the command failed: %v", err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+func TestDraw(t *testing.T) {
+	stdout, stderr, code := run(t, "", "-s", "-hyperlink=false", "https://example.com")
+	if code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "\x1b[40m") {
+		t.Errorf("Expected a code, got %q", stdout)
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	// More than the largest symbol holds
+	stdout, stderr, code := run(t, strings.Repeat("x", 3000), "-s", "-hyperlink=false")
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "exceeds the size limit") {
+		t.Errorf("Expected the encode error, got %q", stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("Expected no code, got %q", stdout)
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	_, stderr, code := run(t, " \n", "-s", "-hyperlink=false")
+	if code != 1 || !strings.Contains(stderr, "empty") {
+		t.Errorf("Expected an error about the empty input, got %d, %q", code, stderr)
+	}
+}
+
+func TestBinaryWhitespace(t *testing.T) {
+	stdout, stderr, code := run(t, " \n\t", "-s", "-hyperlink=false", "-b")
+	if code != 0 || !strings.Contains(stdout, "\x1b[40m") {
+		t.Errorf("Expected whitespace bytes to be encoded, got %d, %q", code, stderr)
+	}
+	if _, _, code := run(t, "", "-s", "-hyperlink=false", "-b"); code != 1 {
+		t.Errorf("Expected no input to be refused, got %d", code)
+	}
+}
+
+func TestSecretFDNoLimit(t *testing.T) {
+	// -max-stdin-bytes 0 is no limit, for -secret-fd too
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("correct horse battery staple\n")
+	w.Close()
+	cmd := command(t, "", "-s", "-hyperlink=false", "-max-stdin-bytes", "0", "-secret-fd", "3")
+	cmd.ExtraFiles = []*os.File{r}
+	stdout, stderr, code := runCommand(t, cmd)
+	r.Close()
+	if code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "\x1b[40m") {
+		t.Errorf("Expected a code, got %q", stdout)
+	}
+}
+
+func TestSecretRefusesStrings(t *testing.T) {
+	for _, flag := range []string{"-url", "-fallback=https://example.com/app"} {
+		_, stderr, code := run(t, "https://example.com\n", "-s", "-hyperlink=false", "-stdin-secret", flag)
+		if code != 1 || !strings.Contains(stderr, "can not be combined") {
+			t.Errorf("%s: expected an error, got %d, %q", flag, code, stderr)
+		}
+	}
+}
+
+func TestBatchOutTraversal(t *testing.T) {
+	dir := t.TempDir()
+	cmd := command(t, "id,url\n../escaped,https://example.com\n", "batch", "-template", "{{.url}}", "-out", "{{.id}}.txt", "-dir", dir+"/codes")
+	_, stderr, code := runCommand(t, cmd)
+	if code != 1 || !strings.Contains(stderr, "leaves the output directory") {
+		t.Errorf("Expected the name to be refused, got %d, %q", code, stderr)
+	}
+	if _, err := os.Stat(dir + "/escaped.txt"); err == nil {
+		t.Error("Expected no file outside -dir")
+	}
+
+	cmd = command(t, "id,url\nfirst,https://example.com\n", "batch", "-template", "{{.url}}", "-out", "{{.id}}.txt", "-dir", dir)
+	if _, stderr, code := runCommand(t, cmd); code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	if _, err := os.Stat(dir + "/first.txt"); err != nil {
+		t.Errorf("Expected the code in -dir: %v", err)
+	}
+}
+
+func TestBatchManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.json")
+	cmd := command(t, "id,url\nfirst,https://example.com\n", "batch", "-l", "H", "-template", "{{.url}}", "-out", "{{.id}}.txt", "-dir", dir, "-manifest", manifest)
+	if _, stderr, code := runCommand(t, cmd); code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	b, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []batch.Entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		t.Fatal(err)
+	}
+	want := batch.Entry{Record: 1, File: filepath.Join(dir, "first.txt"), Version: 3, Level: "H", SHA256: batch.PayloadHash([]byte("https://example.com"))}
+	if len(entries) != 1 || entries[0] != want {
+		t.Errorf("Manifest %+v, expected %+v", entries, want)
+	}
+}
+
+// writePublicKey writes pub as a -verify-key file in dir
+func writePublicKey(t *testing.T, dir string, pub ed25519.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(dir, "key.pub.pem")
+	os.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644)
+	return key
+}
+
+func TestDecodeEnvelopes(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := filepath.Join(dir, "passphrase")
+	os.WriteFile(passphrase, []byte("hunter2\n"), 0o600)
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := writePublicKey(t, dir, pub)
+
+	// As wrapEnvelopes builds them
+	now := time.Now()
+	env := payload.Expire([]byte("WIFI:S:home;T:WPA;P:secret;;"), now, now.Add(time.Hour))
+	env = payload.Sign(env, priv)
+	env, err = payload.Encrypt(env, []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := run(t, string(env)+"\n", "decode", "-passphrase-file", passphrase, "-verify-key", key)
+	if code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Envelope: encrypted, signed, expiring") || !strings.Contains(stdout, "Type:     wifi") {
+		t.Errorf("Expected the WiFi network inside the envelopes, got %q", stdout)
+	}
+
+	for _, args := range [][]string{
+		{"decode"},
+		{"decode", "-passphrase-file", passphrase},
+	} {
+		_, stderr, code := run(t, string(env), args...)
+		if code != 1 || !strings.Contains(stderr, "open it with") && !strings.Contains(stderr, "check it with") {
+			t.Errorf("%v: expected a missing key error, got %d, %q", args, code, stderr)
+		}
+	}
+}
+
+func TestDecodeDemandsEnvelopes(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := filepath.Join(dir, "passphrase")
+	os.WriteFile(passphrase, []byte("hunter2\n"), 0o600)
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := writePublicKey(t, dir, pub)
+	now := time.Now()
+	expiring := payload.Expire([]byte("https://evil.example/"), now, now.Add(time.Hour))
+
+	for _, tc := range []struct {
+		input string
+		args  []string
+		want  string
+	}{
+		{"https://evil.example/\n", []string{"-verify-key", key}, "not signed"},
+		{string(expiring), []string{"-verify-key", key}, "not signed"},
+		{"https://evil.example/\n", []string{"-passphrase-file", passphrase}, "not encrypted"},
+	} {
+		stdout, stderr, code := run(t, tc.input, append([]string{"decode"}, tc.args...)...)
+		if code != 1 || !strings.Contains(stderr, tc.want) || stdout != "" {
+			t.Errorf("%v: expected %q and no fields, got %d, %q, %q", tc.args, tc.want, code, stderr, stdout)
+		}
+	}
+}
+
+func TestDecodeBackupCode(t *testing.T) {
+	code := manualcode.Encode([]byte("https://example.com/a/longer/path"))
+	stdout, stderr, exit := run(t, code+"\n", "decode")
+	if exit != 0 || !strings.Contains(stdout, "https://example.com/a/longer/path") {
+		t.Errorf("Expected the URL of the backup code, got %d, %q, %q", exit, stdout, stderr)
+	}
+
+	typo := []byte(code)
+	if typo[5] == 'A' {
+		typo[5] = 'B'
+	} else {
+		typo[5] = 'A'
+	}
+	_, stderr, exit = run(t, string(typo), "decode")
+	if exit != 1 || !strings.Contains(stderr, "line 1: manualcode: checksum mismatch") {
+		t.Errorf("Expected a checksum error, got %d, %q", exit, stderr)
+	}
+	// Text in lower case is not taken for a backup code
+	for _, input := range []string{string(typo), strings.ToLower(string(typo))} {
+		args := []string{"decode"}
+		if input == string(typo) {
+			args = append(args, "-text")
+		}
+		stdout, _, exit = run(t, input, args...)
+		if exit != 0 || !strings.Contains(stdout, "Type: text") {
+			t.Errorf("%v: expected the input read as text, got %d, %q", args, exit, stdout)
+		}
+	}
+}
+
+func TestDecodeBits(t *testing.T) {
+	bits, stderr, code := run(t, "", "-s", "-hyperlink=false", "-format", "bits", "https://example.com")
+	if code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	_, stderr, code = run(t, bits, "decode")
+	if code != 1 || !strings.Contains(stderr, "bits grid") {
+		t.Errorf("Expected bits input to be refused, got %d, %q", code, stderr)
+	}
+}
+
+func TestVerifyKeyKind(t *testing.T) {
+	dir := t.TempDir()
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := writePublicKey(t, dir, pub)
+	encrypted, err := payload.Encrypt([]byte("hello"), []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]string{
+		string(encrypted): "the code is encrypted, not signed",
+		"hello":           "the code is not an envelope",
+	} {
+		_, stderr, code := run(t, input, "-verify-key", key)
+		if code != 1 || !strings.Contains(stderr, want) {
+			t.Errorf("Expected %q, got %d, %q", want, code, stderr)
+		}
+	}
+}
+
+func TestPreviewFormats(t *testing.T) {
+	stdout, stderr, code := run(t, "", "preview", "https://example.com")
+	if code != 0 {
+		t.Fatalf("Exited with %d: %s", code, stderr)
+	}
+	for _, f := range qrterminal.Formats() {
+		shown := strings.Contains(stdout, "\n"+string(f)+":\n")
+		if want := !f.Document() && f != qrterminal.FormatBits; shown != want {
+			t.Errorf("Format %s: shown %v, expected %v", f, shown, want)
+		}
+	}
+}
+
+func TestListenSocketMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "qr.sock")
+	os.WriteFile(filepath.Join(dir, "before"), nil, 0o644)
+	l, err := listenSocket(path, 0o600)
+	if err != nil {
+		t.Skipf("UNIX sockets are not available: %v", err)
+	}
+	defer l.Close()
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v, %v", fi.Mode(), err)
+	}
+	// The umask of the process is left as it was
+	os.WriteFile(filepath.Join(dir, "after"), nil, 0o644)
+	before, _ := os.Stat(filepath.Join(dir, "before"))
+	after, _ := os.Stat(filepath.Join(dir, "after"))
+	if before.Mode() != after.Mode() {
+		t.Errorf("Expected the umask to be restored, files have mode %v before and %v after", before.Mode(), after.Mode())
+	}
+}
+
+func TestSubcommandNameAsText(t *testing.T) {
+	for _, name := range subcommandNames() {
+		want, _, _ := run(t, name, "-s", "-hyperlink=false", "-format", "bits")
+		got, stderr, code := run(t, "", "-s", "-hyperlink=false", "-format", "bits", "--", name)
+		if code != 0 || got != want || want == "" {
+			t.Errorf("%s: expected the text drawn after --, got %d, %q", name, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// runBitcoin draws a BIP 21 payment request
+func runBitcoin(args []string) {
+	c := newBuilderCommand("bitcoin", "ADDRESS", "Draw a bitcoin: payment request to ADDRESS, whose checksum is checked.")
+	amount := c.fs.String("amount", "", "amount in BTC, e.g. 0.001")
+	label := c.fs.String("label", "", "name of the recipient")
+	message := c.fs.String("message", "", "description of the payment")
+	address := c.parse(args)
+	c.draw(&payload.Bitcoin{Address: address, Amount: *amount, Label: *label, Message: *message})
+}
+
+// runEthereum draws an EIP-681 payment request
+func runEthereum(args []string) {
+	c := newBuilderCommand("ethereum", "ADDRESS", "Draw an ethereum: payment request to ADDRESS, of ether or of an ERC-20 token.")
+	value := c.fs.String("value", "", "amount in wei, or the smallest unit of the token, e.g. 1000000000000000000 or 1e18")
+	chain := c.fs.Uint64("chain", 0, "chain ID of the network, e.g. 1 for mainnet (default: the wallet's)")
+	token := c.fs.String("token", "", "contract `address` of the ERC-20 token to transfer")
+	address := c.parse(args)
+	e := &payload.Ethereum{Address: address, ChainID: *chain, Token: *token}
+	if *value != "" {
+		// Take the notations ethereum: URIs accept
+		parsed, err := payload.ParseEthereum("ethereum:" + address + "?value=" + *value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ethereum:", err)
+			os.Exit(1)
+		}
+		e.Value = parsed.Value
+	}
+	c.draw(e)
+}
+
+// runLightning draws a BOLT 11 invoice in upper case
+func runLightning(args []string) {
+	c := newBuilderCommand("lightning", "INVOICE", "Draw a Lightning invoice, checked and in upper case for a smaller code.")
+	uri := c.fs.Bool("uri", false, "prefix the invoice with the lightning: scheme")
+	l, err := payload.ParseLightning(c.parse(args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lightning:", err)
+		os.Exit(1)
+	}
+	l.URI = l.URI || *uri
+	c.draw(l)
+}
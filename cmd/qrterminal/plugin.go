@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// pluginPrefix starts the name of the executables that provide external
+// subcommands, qrterminal-foo runs as "qrterminal foo"
+const pluginPrefix = "qrterminal-"
+
+// pluginName matches the subcommand names looked up on PATH, anything else,
+// such as a URL, is text to encode
+var pluginName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// findPlugin returns the path of the executable for the subcommand name, or
+// "" if there is none
+func findPlugin(name string) string {
+	if !pluginName.MatchString(name) {
+		return ""
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// pluginEnv passes the value of every global flag to a plugin, e.g.
+// -max-stdin-bytes as QRTERMINAL_MAX_STDIN_BYTES, together with the path
+// of this executable as QRTERMINAL, so the plugin can have it draw codes
+func pluginEnv() []string {
+	var env []string
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "QRTERMINAL="+self)
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		name := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		env = append(env, "QRTERMINAL_"+name+"="+f.Value.String())
+	})
+	return env
+}
+
+// runPlugin runs the plugin at path with args and exits with its status
+func runPlugin(path string, args []string) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv()...)
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.ExitCode())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
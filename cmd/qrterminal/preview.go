@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// previewFormats returns the formats shown by the preview command: those
+// drawn in the terminal, except bits, which are for tools and not scanners
+func previewFormats() []qrterminal.Format {
+	var formats []qrterminal.Format
+	for _, f := range qrterminal.Formats() {
+		if !f.Document() && f != qrterminal.FormatBits {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
+// runPreview renders the same payload in every terminal format, one after
+// another, so users can find the one their scanner reads best
+func runPreview(args []string) {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	level := qrterminal.L
+	fs.Var(&level, "l", levelUsage)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s preview [flags] [text]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Render the text, or stdin, in each output format.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var data []byte
+	if fs.NArg() > 0 {
+		data = []byte(strings.Join(fs.Args(), " "))
+	} else {
+		var err error
+		data, err = readStdin(defaultMaxStdin)
+		if err != nil {
+			exitStdinError(err)
+		}
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		fmt.Fprintln(os.Stderr, "Nothing to encode, the input is empty")
+		os.Exit(1)
+	}
+
+	profile := qrterminal.DetectProfileFromEnv()
+	sixel := qrterminal.IsSixelSupported(os.Stdout) && (profile == nil || !profile.NoSixel)
+	for _, format := range previewFormats() {
+		fmt.Printf("\n%s:\n", format)
+		if format == qrterminal.FormatSixel && !sixel {
+			fmt.Println("  not supported by this terminal")
+			continue
+		}
+		cfg := qrterminal.Config{
+			Level:     level,
+			Writer:    os.Stdout,
+			QuietZone: *quietZoneFlag,
+			Format:    format,
+			Profile:   profile,
+		}
+		if format == qrterminal.FormatBlocks {
+			cfg.BlackChar = qrterminal.BLACK
+			cfg.WhiteChar = qrterminal.WHITE
+		}
+		qrterminal.GenerateBinaryWithConfig(data, cfg)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// scanResult records whether a code scanned, one JSON object per line of
+// the -json file of compare and calibrate, so results can be collected
+// across terminals and scanner apps. The payload is only recorded as its
+// length and hash unless -show-payload is set.
+type scanResult struct {
+	Time     time.Time `json:"time"`
+	Terminal string    `json:"terminal,omitempty"`
+	Scanner  string    `json:"scanner,omitempty"`
+	Mode     string    `json:"mode"`
+	Label    string    `json:"label"`
+	Payload  string    `json:"payload"`
+	Scanned  bool      `json:"scanned"`
+}
+
+// resultLog appends scan results to a file, doing nothing if path is empty
+type resultLog struct {
+	path        string
+	scanner     string
+	showPayload bool
+}
+
+// terminalName names the terminal the codes are drawn on
+func terminalName() string {
+	if name := os.Getenv("TERM_PROGRAM"); name != "" {
+		return name
+	}
+	return os.Getenv("TERM")
+}
+
+// record appends the result for one code, reporting failures on stderr
+// rather than interrupting the session
+func (l resultLog) record(mode, label string, data []byte, scanned bool) {
+	if l.path == "" {
+		return
+	}
+	line, err := json.Marshal(scanResult{
+		Time:     time.Now().UTC(),
+		Terminal: terminalName(),
+		Scanner:  l.scanner,
+		Mode:     mode,
+		Label:    label,
+		Payload:  shownPayload(data, l.showPayload),
+		Scanned:  scanned,
+	})
+	if err == nil {
+		err = appendLine(l.path, line)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to record the result: %v\n", err)
+	}
+}
+
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// shownPayload returns data as verbose output and logs may show it: in
+// full if show is set, otherwise only its length and hash
+func shownPayload(data []byte, show bool) string {
+	if show {
+		return string(data)
+	}
+	return payload.Redact(data)
+}
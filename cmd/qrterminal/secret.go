@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+	"golang.org/x/term"
+)
+
+var stdinSecretFlag bool
+var secretFDFlag int
+
+// readSecret reads the payload of -stdin-secret from the file descriptor
+// of -secret-fd if it is set, otherwise from stdin, prompting for it with
+// echo off if stdin is a terminal. A trailing line ending is dropped. It
+// fails on input longer than max bytes, unless max is 0.
+//
+// The descriptor has to be given explicitly: one that is merely open may
+// have been inherited by accident, or opened by the Go runtime itself.
+func readSecret(max int64) (payload.Secret, error) {
+	if secretFDFlag > 0 {
+		f := os.NewFile(uintptr(secretFDFlag), fmt.Sprintf("fd %d", secretFDFlag))
+		defer f.Close()
+		r := io.Reader(f)
+		if max > 0 {
+			r = io.LimitReader(r, max+1)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file descriptor %d: %v", secretFDFlag, err)
+		}
+		if max > 0 && int64(len(data)) > max {
+			return nil, fmt.Errorf("the input is larger than %d bytes, raise -max-stdin-bytes to encode it anyway", max)
+		}
+		return trimLineEnding(data), nil
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return qrterminal.ReadSecret(os.Stdin, os.Stderr, "Secret to encode (not shown): ")
+	}
+	data, err := readStdin(max)
+	if err != nil {
+		return nil, err
+	}
+	return trimLineEnding(data), nil
+}
+
+// trimLineEnding drops one trailing "\n" or "\r\n" from data
+func trimLineEnding(data []byte) payload.Secret {
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return bytes.TrimSuffix(data, []byte("\r"))
+}
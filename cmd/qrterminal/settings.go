@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// settings are the defaults of the main command's flags that calibrate
+// finds for a terminal. Flags given on the command line override them.
+type settings struct {
+	Format      qrterminal.Format `json:"format,omitempty"`
+	Theme       string            `json:"theme,omitempty"`
+	Backdrop    bool              `json:"backdrop,omitempty"`
+	AspectRatio float64           `json:"aspect_ratio,omitempty"`
+}
+
+// settingsPath returns the settings file, qrterminal/settings.json in the
+// user's configuration directory
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "qrterminal", "settings.json"), nil
+}
+
+// loadSettings reads the settings file, a missing file gives the zero
+// settings
+func loadSettings() (settings, error) {
+	var s settings
+	path, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return settings{}, &fs.PathError{Op: "parse", Path: path, Err: err}
+	}
+	return s, nil
+}
+
+// save writes s to the settings file and returns its path
+func (s settings) save() (string, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// pageSizes are the page sizes accepted by -page
+var pageSizes = map[string]qrterminal.PageSize{
+	"a4":     qrterminal.PageA4,
+	"letter": qrterminal.PageLetter,
+}
+
+func getPageSize(s string) (qrterminal.PageSize, bool) {
+	p, ok := pageSizes[strings.ToLower(s)]
+	return p, ok
+}
+
+// runSheet writes a PDF label sheet with one code per line of input. A tab
+// separates the data of a line from its caption, lines without one are
+// their own caption.
+func runSheet(args []string) {
+	fs := flag.NewFlagSet("sheet", flag.ExitOnError)
+	level := qrterminal.L
+	fs.Var(&level, "l", levelUsage)
+	quietZoneFlag := fs.Int("q", 2, "Size of quietzone border")
+	colsFlag := fs.Int("cols", 3, "labels per row")
+	rowsFlag := fs.Int("rows", 8, "rows of labels per page")
+	pageFlag := fs.String("page", "a4", "page size: a4 or letter")
+	marginFlag := fs.Float64("margin", 36, "page margin in points (1/72 inch)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s sheet [flags] [file ...] > labels.pdf\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Write a PDF label sheet with one code per line of the files, or stdin.\n")
+		fmt.Fprintf(fs.Output(), "A tab separates the data of a line from its caption.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	page, ok := getPageSize(*pageFlag)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid page size: %s\n", *pageFlag)
+		os.Exit(1)
+	}
+
+	var inputs []io.Reader
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		inputs = append(inputs, f)
+	}
+	if len(inputs) == 0 {
+		inputs = append(inputs, os.Stdin)
+	}
+
+	var labels []qrterminal.Label
+	scanner := bufio.NewScanner(io.MultiReader(inputs...))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		data, caption, found := strings.Cut(line, "\t")
+		if !found {
+			caption = line
+		}
+		labels = append(labels, qrterminal.Label{Data: []byte(data), Caption: caption})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read input: %v\n", err)
+		os.Exit(1)
+	}
+
+	sheet := qrterminal.Sheet{
+		Columns:    *colsFlag,
+		Rows:       *rowsFlag,
+		PDFOptions: qrterminal.PDFOptions{Page: page, Margin: *marginFlag},
+	}
+	cfg := qrterminal.Config{Level: level, QuietZone: *quietZoneFlag}
+	if err := qrterminal.WritePDFSheet(os.Stdout, labels, sheet, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"golang.org/x/term"
+)
+
+// defaultMaxStdin is far more than any QR code holds, it only stops a large
+// file piped in by mistake from being read into memory
+const defaultMaxStdin = 64 << 10
+
+// errInterrupted is returned by readStdin when Ctrl-C is pressed while the
+// input is typed or pasted
+var errInterrupted = errors.New("interrupted, the input was discarded")
+
+// promptStdin tells an interactive user that the input is read from the
+// terminal, rather than silently waiting for it
+func promptStdin() {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	eof := "Ctrl-D"
+	if runtime.GOOS == "windows" {
+		eof = "Ctrl-Z and Enter"
+	}
+	fmt.Fprintf(os.Stderr, "Type the text to encode, then press %s\n", eof)
+}
+
+// readStdin reads the input until EOF, failing if it is longer than max
+// bytes unless max is 0. Input from a terminal is discarded as a whole if
+// Ctrl-C is pressed, a partial paste is never encoded.
+func readStdin(max int64) ([]byte, error) {
+	promptStdin()
+	r := io.Reader(os.Stdin)
+	if max > 0 {
+		r = io.LimitReader(r, max+1)
+	}
+	read := func() ([]byte, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read stdin: %v", err)
+		}
+		if max > 0 && int64(len(data)) > max {
+			return nil, fmt.Errorf("the input is larger than %d bytes, raise -max-stdin-bytes to encode it anyway", max)
+		}
+		return data, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return read()
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := read()
+		done <- result{data, err}
+	}()
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-interrupt:
+		return nil, errInterrupted
+	}
+}
+
+// exitStdinError reports an error of readStdin and exits, with the status
+// of a process killed by SIGINT if it was interrupted
+func exitStdinError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	if err == errInterrupted {
+		os.Exit(130)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by socket activation
+const listenFDsStart = 3
+
+// activationListener returns the socket systemd passed to this process
+// with socket activation, or nil if it did not pass one. Only the first
+// socket is used. The activation variables are unset so child processes
+// do not see them.
+func activationListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	name := "LISTEN_FD_" + strconv.Itoa(listenFDsStart)
+	if names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":"); names[0] != "" {
+		name = names[0]
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(listenFDsStart, name)
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// sdNotify sends state, e.g. "READY=1", to the service manager if it asked
+// for notifications with NOTIFY_SOCKET, and does nothing otherwise
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	// A leading @ names a socket in the abstract namespace
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// teeFlag collects the -tee destinations, each a file name optionally
+// preceded by a format and a colon, e.g. "pdf:code.pdf"
+type teeFlag []string
+
+func (t *teeFlag) String() string {
+	return strings.Join(*t, ", ")
+}
+
+func (t *teeFlag) Set(s string) error {
+	*t = append(*t, s)
+	return nil
+}
+
+// open creates the files of every destination and returns them as outputs,
+// with a function that closes them
+func (t teeFlag) open() ([]qrterminal.Output, func() error, error) {
+	var outputs []qrterminal.Output
+	var files []*os.File
+	closeAll := func() error {
+		var err error
+		for _, f := range files {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+	for _, dest := range t {
+		var format qrterminal.Format
+		// A prefix that is not a format, such as a Windows drive letter, is
+		// part of the file name
+		if name, file, ok := strings.Cut(dest, ":"); ok {
+			if f, err := qrterminal.ParseFormat(name); err == nil && f != "" {
+				format, dest = f, file
+			}
+		}
+		f, err := os.Create(dest)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		files = append(files, f)
+		outputs = append(outputs, qrterminal.Output{Writer: f, Format: format})
+	}
+	return outputs, closeAll, nil
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// restrictUmask makes the files created until restore is called accessible
+// to their owner only
+func restrictUmask() (restore func()) {
+	old := syscall.Umask(0o077)
+	return func() { syscall.Umask(old) }
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"golang.org/x/term"
+)
+
+// terminalSize returns the columns and lines of the terminal on stdout,
+// 0 where they are not known. -width and -height take precedence over the
+// COLUMNS and LINES environment variables, which take precedence over the
+// size the terminal reports, for containers and serial consoles that do
+// not report one or report it wrong.
+func terminalSize() (cols, lines int) {
+	cols, lines, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		cols, lines = 0, 0
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		lines = n
+	}
+	if widthFlag > 0 {
+		cols = widthFlag
+	}
+	if heightFlag > 0 {
+		lines = heightFlag
+	}
+	return cols, lines
+}
+
+// checkWidth returns an error if the code of data is wider than cols
+// columns. The terminal would wrap its lines, and a wrapped code still
+// looks like one but never scans. Sixel images are clipped rather than
+// wrapped and are not checked.
+func checkWidth(data []byte, cfg qrterminal.Config, cols int) error {
+	if cols <= 0 {
+		return nil
+	}
+	info, err := qrterminal.Info(data, cfg)
+	if errors.Is(err, qrterminal.ErrNoRenderMode) {
+		return fmt.Errorf("no output mode draws the code in %d columns and %d lines", cfg.MaxColumns, cfg.MaxLines)
+	}
+	if err != nil || info.Format == qrterminal.FormatSixel || info.Cells <= cols {
+		return nil
+	}
+	return fmt.Errorf("the code is %d columns wide but the terminal only has %d, and a wrapped code does not scan", info.Cells, cols)
+}
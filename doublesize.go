@@ -0,0 +1,49 @@
+package qrterminal
+
+import (
+	"bytes"
+	"io"
+)
+
+// DEC line attributes for the top and bottom half of a double-height line.
+// Double-height lines are also double width.
+const (
+	decDHLTop    = "\033#3"
+	decDHLBottom = "\033#4"
+)
+
+// writeDoubleSize draws the code like FormatBlocks, with every line
+// repeated as the top and bottom half of a double-height line
+func (c *Config) writeDoubleSize(w io.Writer, bm *Bitmap) {
+	c.writeFullBlocks(&doubleHeightWriter{w: w}, bm)
+}
+
+// doubleHeightWriter writes every complete line written to it twice, with
+// the DECDHL attributes for its top and bottom half
+type doubleHeightWriter struct {
+	w       io.Writer
+	partial []byte
+}
+
+func (d *doubleHeightWriter) Write(p []byte) (int, error) {
+	d.partial = append(d.partial, p...)
+	var out bytes.Buffer
+	for {
+		i := bytes.IndexByte(d.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := d.partial[:i+1]
+		out.WriteString(decDHLTop)
+		out.Write(line)
+		out.WriteString(decDHLBottom)
+		out.Write(line)
+		d.partial = d.partial[i+1:]
+	}
+	if out.Len() > 0 {
+		if _, err := d.w.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,45 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDoubleSize(t *testing.T) {
+	var blocks, double bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &blocks, BlackChar: BLACK, WhiteChar: WHITE})
+	GenerateWithConfig("test", Config{Level: L, Writer: &double, BlackChar: BLACK, WhiteChar: WHITE, Format: FormatDoubleSize})
+
+	var want strings.Builder
+	for _, line := range strings.SplitAfter(blocks.String(), "\n") {
+		if line != "" {
+			want.WriteString(decDHLTop + line + decDHLBottom + line)
+		}
+	}
+	if double.String() != want.String() {
+		t.Errorf("Expected every line as a top and a bottom half\n got %q\nwant %q", double.String(), want.String())
+	}
+}
+
+func TestDoubleSizeTheme(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, Format: FormatDoubleSize, Theme: ThemeASCII})
+	if !strings.Contains(buf.String(), GlyphsASCII.White) {
+		t.Errorf("Expected the blocks glyphs of the theme, got %q", buf.String())
+	}
+}
+
+func TestDoubleSizeProfile(t *testing.T) {
+	// The reset of ProfileXtermJS ends every half line
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, Format: FormatDoubleSize, Profile: ProfileXtermJS})
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, decDHLTop) && !strings.HasPrefix(line, decDHLBottom) {
+			t.Fatalf("Expected a line attribute at the start of %q", line)
+		}
+		if !strings.HasSuffix(line, sgrReset) {
+			t.Fatalf("Expected a reset at the end of %q", line)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package qrterminal
+
+import "errors"
+
+// RenderMode is a way of drawing a code that a terminal may not support,
+// see Config.Fallbacks
+type RenderMode string
+
+// Render modes, from the richest to the most widely supported
+const (
+	// ModeSixel draws FormatSixel, it needs Capabilities.Sixel
+	ModeSixel RenderMode = "sixel"
+	// ModeHalfBlocks draws FormatHalfBlocks, it needs Capabilities.Unicode
+	ModeHalfBlocks RenderMode = "halfblocks"
+	// ModeBlocks draws FormatBlocks with GlyphsANSI, it needs
+	// Capabilities.Color
+	ModeBlocks RenderMode = "blocks"
+	// ModeASCII draws FormatBlocks with ThemeASCII, which every terminal
+	// shows
+	ModeASCII RenderMode = "ascii"
+)
+
+// DefaultFallbacks tries every render mode, from the richest to the most
+// widely supported
+var DefaultFallbacks = []RenderMode{ModeSixel, ModeHalfBlocks, ModeBlocks, ModeASCII}
+
+// ErrNoRenderMode is returned when none of the Config.Fallbacks is
+// supported by the terminal and fits in MaxColumns and MaxLines
+var ErrNoRenderMode = errors.New("qrterminal: no fallback render mode is supported and fits")
+
+// supports reports whether a terminal with caps draws mode m
+func (caps Capabilities) supports(m RenderMode) bool {
+	switch m {
+	case ModeSixel:
+		return caps.Sixel
+	case ModeHalfBlocks:
+		return caps.Unicode
+	case ModeBlocks:
+		return caps.Color
+	case ModeASCII:
+		return true
+	}
+	return false
+}
+
+// configure sets the format and glyphs of c to draw in mode m
+func (m RenderMode) configure(c *Config) {
+	c.BlackChar, c.WhiteChar, c.BlackWhiteChar, c.WhiteBlackChar = "", "", "", ""
+	c.HalfBlocks, c.WithSixel = false, false
+	c.Theme = nil
+	switch m {
+	case ModeSixel:
+		c.Format = FormatSixel
+	case ModeHalfBlocks:
+		c.Format = FormatHalfBlocks
+	case ModeBlocks:
+		c.Format = FormatBlocks
+		c.BlackChar, c.WhiteChar = GlyphsANSI.Black, GlyphsANSI.White
+	default:
+		c.Format = FormatBlocks
+		c.Theme = ThemeASCII
+	}
+}
+
+// fallback returns config set up for the first of its Fallbacks that the
+// terminal supports and that draws data within MaxColumns and MaxLines,
+// together with that mode
+func (c Config) fallback(data []byte) (Config, RenderMode, error) {
+	caps := c.Capabilities
+	if caps == nil {
+		detected := DetectCapabilities(c.Writer)
+		caps = &detected
+	}
+	for _, m := range c.Fallbacks {
+		if !caps.supports(m) {
+			continue
+		}
+		fc := c
+		fc.Fallbacks = nil
+		m.configure(&fc)
+		if c.MaxColumns > 0 || c.MaxLines > 0 {
+			cols, lines, err := RenderedSize(data, fc)
+			if err != nil {
+				return c, "", err
+			}
+			if (c.MaxColumns > 0 && cols > c.MaxColumns) || (c.MaxLines > 0 && lines > c.MaxLines) {
+				continue
+			}
+		}
+		return fc, m, nil
+	}
+	return c, "", ErrNoRenderMode
+}
@@ -0,0 +1,67 @@
+package qrterminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFallbacks(t *testing.T) {
+	all := &Capabilities{Sixel: true, Hyperlink: true, Unicode: true, Color: true}
+	testCases := []struct {
+		name   string
+		config Config
+		mode   RenderMode
+		want   Config // renders the same as the chosen mode
+	}{
+		{"Sixel", Config{Capabilities: all}, ModeSixel, Config{Format: FormatSixel}},
+		{"HalfBlocks", Config{Capabilities: &Capabilities{Unicode: true, Color: true}}, ModeHalfBlocks, Config{Format: FormatHalfBlocks}},
+		{"Blocks", Config{Capabilities: &Capabilities{Color: true}}, ModeBlocks, Config{BlackChar: BLACK, WhiteChar: WHITE}},
+		{"NotATerminal", Config{}, ModeASCII, Config{Theme: ThemeASCII}},
+		{"TooWide", Config{Capabilities: all, MaxColumns: 28}, ModeHalfBlocks, Config{Format: FormatHalfBlocks}},
+		{"OwnOrder", Config{Capabilities: all, Fallbacks: []RenderMode{ModeASCII, ModeSixel}}, ModeASCII, Config{Theme: ThemeASCII}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got, want bytes.Buffer
+			config := tc.config
+			config.Level, config.QuietZone, config.Writer = L, 2, &got
+			config.BlackChar, config.Theme = "x", ThemeShade // replaced by the mode
+			if config.Fallbacks == nil {
+				config.Fallbacks = DefaultFallbacks
+			}
+			GenerateWithConfig("test", config)
+			tc.want.Level, tc.want.QuietZone, tc.want.Writer = L, 2, &want
+			GenerateWithConfig("test", tc.want)
+			if got.String() != want.String() {
+				t.Errorf("Expected the %s mode, got\n%s", tc.mode, got.String())
+			}
+
+			info, err := Info([]byte("test"), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.RenderMode != tc.mode {
+				t.Errorf("Expected Info to report the %s mode, got %q", tc.mode, info.RenderMode)
+			}
+		})
+	}
+}
+
+func TestFallbacksNoneFits(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{
+		Level:        L,
+		QuietZone:    2,
+		Writer:       &buf,
+		Fallbacks:    DefaultFallbacks,
+		Capabilities: &Capabilities{Sixel: true, Unicode: true, Color: true},
+		MaxColumns:   20,
+	}
+	if _, err := Info([]byte("test"), config); err != ErrNoRenderMode {
+		t.Errorf("Expected ErrNoRenderMode, got %v", err)
+	}
+	GenerateWithConfig("test", config)
+	if buf.Len() != 0 {
+		t.Errorf("Nothing should be drawn when no mode fits, got\n%s", buf.String())
+	}
+}
@@ -0,0 +1,177 @@
+package qrterminal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Format selects how a code is drawn
+type Format string
+
+// Built in formats
+const (
+	// FormatBlocks draws every module as BlackChar or WhiteChar
+	FormatBlocks Format = "blocks"
+	// FormatHalfBlocks draws two rows of modules per line of text
+	FormatHalfBlocks Format = "halfblocks"
+	// FormatDoubleSize draws FormatBlocks on double-height lines, twice as
+	// wide and high, for VT-compatible terminals that honor DECDHL
+	FormatDoubleSize Format = "doublesize"
+	// FormatSixel draws the code as sixel graphics
+	FormatSixel Format = "sixel"
+	// FormatBits writes one line of 0 (white) and 1 (black) digits per row
+	FormatBits Format = "bits"
+	// FormatPDF writes a PDF document with the code centered on a page
+	FormatPDF Format = "pdf"
+	// FormatZPL writes a ZPL II label for Zebra and compatible printers
+	FormatZPL Format = "zpl"
+	// FormatEPL writes an EPL2 label for older Zebra and Eltron printers
+	FormatEPL Format = "epl"
+	// FormatLinePrinter writes plain ASCII with overstrike for fixed-pitch
+	// line printers, see LinePrinterOptions
+	FormatLinePrinter Format = "lineprinter"
+)
+
+// FormatWriter writes a code in a format of its own, e.g. the language of
+// a label printer, see RegisterFormat. The Config is the one the code is
+// drawn with, its QuietZone is the number of white modules to put around
+// bm.
+type FormatWriter interface {
+	WriteFormat(w io.Writer, bm *Bitmap, config Config) error
+}
+
+// FormatWriterFunc adapts an ordinary function to the FormatWriter
+// interface
+type FormatWriterFunc func(w io.Writer, bm *Bitmap, config Config) error
+
+// WriteFormat calls f(w, bm, config)
+func (f FormatWriterFunc) WriteFormat(w io.Writer, bm *Bitmap, config Config) error {
+	return f(w, bm, config)
+}
+
+type formatSpec struct {
+	render func(c *Config, w io.Writer, bm *Bitmap)
+	// writer draws registered formats in place of render
+	writer FormatWriter
+	// text formats are subject to the line adjustments of the Profile
+	text bool
+	// annotate formats are meant to be looked at in the terminal, so a
+	// hyperlink caption may follow them
+	annotate bool
+	// document formats are files for other programs, they are not drawn
+	// in terminal cells
+	document bool
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   = map[Format]formatSpec{
+		FormatBlocks:      {render: (*Config).writeFullBlocks, text: true, annotate: true},
+		FormatHalfBlocks:  {render: (*Config).writeHalfBlocks, text: true, annotate: true},
+		FormatDoubleSize:  {render: (*Config).writeDoubleSize, text: true, annotate: true},
+		FormatSixel:       {render: (*Config).writeSixel, annotate: true},
+		FormatBits:        {render: (*Config).writeBits},
+		FormatPDF:         {render: (*Config).writePDF, document: true},
+		FormatZPL:         {render: (*Config).writeZPL, document: true},
+		FormatEPL:         {render: (*Config).writeEPL, document: true},
+		FormatLinePrinter: {render: (*Config).writeLinePrinter, document: true},
+	}
+)
+
+// RegisterFormat makes f available as Config.Format name, so other modules
+// can add formats, and the command line tool can draw them when built with
+// such a module. A registered format is a document, like FormatPDF: it is
+// written as f writes it and not measured in terminal cells. A format
+// registered under the name of another one replaces it. name is lower
+// cased, as ParseFormat accepts names in either case.
+func RegisterFormat(name string, f FormatWriter) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[Format(strings.ToLower(name))] = formatSpec{writer: f, document: true}
+}
+
+// formatSpecFor returns the spec of the format name
+func formatSpecFor(name Format) (formatSpec, bool) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	spec, ok := formats[name]
+	return spec, ok
+}
+
+// Document reports whether f is written as a file for other programs, such
+// as FormatPDF and registered formats, rather than drawn in the terminal
+func (f Format) Document() bool {
+	spec, _ := formatSpecFor(f)
+	return spec.document
+}
+
+// format returns the format to draw with, falling back to the HalfBlocks
+// and WithSixel switches when Format is not set
+func (c *Config) format() Format {
+	switch {
+	case c.Format != "":
+		return c.Format
+	case c.HalfBlocks:
+		return FormatHalfBlocks
+	case c.WithSixel:
+		return FormatSixel
+	default:
+		return FormatBlocks
+	}
+}
+
+// Formats returns the names of the available formats in sorted order
+func Formats() []Format {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	names := make([]Format, 0, len(formats))
+	for f := range formats {
+		names = append(names, f)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// ParseFormat returns the format named by s in either case. The empty
+// string is the empty Format, which leaves the choice to the HalfBlocks and
+// WithSixel switches.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	if _, ok := formatSpecFor(f); !ok && f != "" {
+		var names []string
+		for _, name := range Formats() {
+			names = append(names, string(name))
+		}
+		return "", fmt.Errorf("qrterminal: invalid format %q, expected one of %s", s, strings.Join(names, ", "))
+	}
+	return f, nil
+}
+
+// String returns the name of f
+func (f Format) String() string {
+	return string(f)
+}
+
+// Set parses s as with ParseFormat, so a *Format can be used as a
+// flag.Value
+func (f *Format) Set(s string) error {
+	v, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (f *Format) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
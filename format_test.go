@@ -0,0 +1,87 @@
+package qrterminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats() {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if got, err := ParseFormat("HalfBlocks"); err != nil || got != FormatHalfBlocks {
+		t.Errorf("Format names should be case insensitive, got %q, %v", got, err)
+	}
+	if got, err := ParseFormat(""); err != nil || got != "" {
+		t.Errorf("The empty format should be accepted, got %q, %v", got, err)
+	}
+	if _, err := ParseFormat("png"); err == nil {
+		t.Errorf("An unknown format should be rejected")
+	}
+}
+
+func TestFormatFlag(t *testing.T) {
+	f := FormatBlocks
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&f, "format", "format")
+	if err := fs.Parse([]string{"-format", "BITS"}); err != nil || f != FormatBits {
+		t.Errorf("Expected bits from the flag, got %q, %v", f, err)
+	}
+	if err := fs.Parse([]string{"-format", "png"}); err == nil {
+		t.Errorf("An unknown format should be rejected")
+	}
+
+	var config struct{ Format Format }
+	if err := json.Unmarshal([]byte(`{"Format": "Sixel"}`), &config); err != nil || config.Format != FormatSixel {
+		t.Errorf("Expected sixel from JSON, got %q, %v", config.Format, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Format": "png"}`), &config); err == nil {
+		t.Errorf("An unknown format should be rejected in JSON")
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	errLabel := errors.New("label printer offline")
+	RegisterFormat("Label-Test", FormatWriterFunc(func(w io.Writer, bm *Bitmap, config Config) error {
+		if config.Placeholder == "fail" {
+			return errLabel
+		}
+		_, err := fmt.Fprintf(w, "LABEL %d %d", bm.Size, config.QuietZone)
+		return err
+	}))
+
+	f, err := ParseFormat("LABEL-TEST")
+	if err != nil || f != "label-test" {
+		t.Fatalf("Expected the registered format to parse, got %q, %v", f, err)
+	}
+	if !f.Document() || FormatBlocks.Document() || !FormatPDF.Document() {
+		t.Errorf("Expected registered formats and FormatPDF to be documents, but not FormatBlocks")
+	}
+	found := false
+	for _, name := range Formats() {
+		found = found || name == f
+	}
+	if !found {
+		t.Errorf("Expected Formats to list %s", f)
+	}
+
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 2, Format: f, Hyperlink: true})
+	if buf.String() != "LABEL 21 2" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+	if _, _, err := RenderedSize([]byte("test"), Config{Format: f}); err == nil {
+		t.Errorf("Expected RenderedSize to refuse a document format")
+	}
+	if err := generate([]byte("test"), Config{Writer: io.Discard, Format: f, Placeholder: "fail"}); !errors.Is(err, errLabel) {
+		t.Errorf("Expected the error of the format writer, got %v", err)
+	}
+}
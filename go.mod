@@ -1,9 +1,10 @@
-module github.com/katzenpost/qrterminal
+module github.com/katzenpost/qrterminal/v3
 
 go 1.20
 
 require (
 	github.com/mattn/go-colorable v0.1.14
+	golang.org/x/crypto v0.14.0
 	rsc.io/qr v0.2.0
 )
 
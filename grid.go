@@ -0,0 +1,129 @@
+package qrterminal
+
+import "strings"
+
+// Color is the color of a cell of a CellGrid
+type Color uint8
+
+// Colors of the cells drawn by RenderInto
+const (
+	ColorBlack Color = iota
+	ColorWhite
+)
+
+// CellGrid is a grid of terminal cells, such as the back buffer of a TUI,
+// that RenderInto draws a code into. x counts columns and y lines.
+type CellGrid interface {
+	SetCell(x, y int, ch rune, fg, bg Color)
+}
+
+// RenderInto draws the code of data into grid with the top left corner of
+// its quiet zone at x, y, so TUIs can place it in their own buffers rather
+// than parse the text GenerateWithConfig writes. FormatHalfBlocks draws two
+// modules to a cell, as an upper half block in the color of the top one on
+// the color of the bottom one; anything else draws a module as two blank
+// cells of its color, taking as many cells as RenderedSize reports for
+// FormatHalfBlocks, or FormatBlocks with GlyphsANSI. Glyphs, themes and
+// the other output settings of config do not apply.
+func RenderInto(grid CellGrid, x, y int, data []byte, config Config) error {
+	spec, err := outputSpecFor(config.OutputVersion)
+	if err != nil {
+		return err
+	}
+	if config.QuietZone < spec.minQuietZone {
+		config.QuietZone = spec.minQuietZone
+	}
+	bm, err := config.encode(data)
+	if err != nil {
+		return err
+	}
+	q := config.QuietZone
+	side := bm.Size + 2*q
+	color := func(mx, my int) Color {
+		if bm.Black(mx-q, my-q) {
+			return ColorBlack
+		}
+		return ColorWhite
+	}
+
+	if config.format() == FormatHalfBlocks {
+		for my := 0; my < side; my += 2 {
+			for mx := 0; mx < side; mx++ {
+				bottom := ColorWhite
+				if my+1 < side {
+					bottom = color(mx, my+1)
+				}
+				grid.SetCell(x+mx, y+my/2, '▀', color(mx, my), bottom)
+			}
+		}
+		return nil
+	}
+	for my := 0; my < side; my++ {
+		for mx := 0; mx < side; mx++ {
+			c := color(mx, my)
+			grid.SetCell(x+2*mx, y+my, ' ', c, c)
+			grid.SetCell(x+2*mx+1, y+my, ' ', c, c)
+		}
+	}
+	return nil
+}
+
+// TextGrid is a CellGrid in memory whose String draws it as lines of text
+// with SGR colors, e.g. to compose a code with other content in the View of
+// a bubbletea model. Cells that are not set are blank.
+type TextGrid struct {
+	Width, Height int
+	cells         []textCell
+}
+
+type textCell struct {
+	ch     rune
+	fg, bg Color
+}
+
+// NewTextGrid returns a blank TextGrid of width columns and height lines
+func NewTextGrid(width, height int) *TextGrid {
+	return &TextGrid{Width: width, Height: height, cells: make([]textCell, width*height)}
+}
+
+// SetCell sets the cell at x, y, cells outside the grid are ignored
+func (g *TextGrid) SetCell(x, y int, ch rune, fg, bg Color) {
+	if 0 <= x && x < g.Width && 0 <= y && y < g.Height {
+		g.cells[y*g.Width+x] = textCell{ch, fg, bg}
+	}
+}
+
+// textCellColors are the SGR foreground and background colors of each
+// Color
+var textCellColors = [...][2]int{
+	ColorBlack: {30, 40},
+	ColorWhite: {37, 47},
+}
+
+// String returns the lines of g, each ending with a newline
+func (g *TextGrid) String() string {
+	var out strings.Builder
+	for y := 0; y < g.Height; y++ {
+		last := sgrReset
+		for _, c := range g.cells[y*g.Width : (y+1)*g.Width] {
+			seq := sgrReset
+			if c.ch != 0 {
+				seq = sgr(textCellColors[c.fg][0], textCellColors[c.bg][1])
+			}
+			if seq != last {
+				out.WriteString(seq)
+				last = seq
+			}
+			if c.ch == 0 {
+				out.WriteByte(' ')
+			} else {
+				out.WriteRune(c.ch)
+			}
+		}
+		if last != sgrReset {
+			out.WriteString(sgrReset)
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,83 @@
+package qrterminal
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordGrid records the cells set on it
+type recordGrid map[[2]int]textCell
+
+func (g recordGrid) SetCell(x, y int, ch rune, fg, bg Color) {
+	g[[2]int{x, y}] = textCell{ch, fg, bg}
+}
+
+func TestRenderInto(t *testing.T) {
+	data := []byte("https://example.com")
+	bm, _ := Encode(data, Config{Level: M})
+	q := 2
+
+	for _, format := range []Format{FormatBlocks, FormatHalfBlocks} {
+		config := Config{Level: M, QuietZone: q, Format: format}
+		grid := recordGrid{}
+		if err := RenderInto(grid, 3, 1, data, config); err != nil {
+			t.Fatal(err)
+		}
+		size := config
+		if format == FormatBlocks {
+			size.BlackChar, size.WhiteChar = GlyphsANSI.Black, GlyphsANSI.White
+		}
+		cols, lines, _ := RenderedSize(data, size)
+		if len(grid) != cols*lines {
+			t.Errorf("%s: expected %dx%d cells, got %d", format, cols, lines, len(grid))
+		}
+		if _, ok := grid[[2]int{3 + cols - 1, 1 + lines - 1}]; !ok {
+			t.Errorf("%s: bottom right cell not set", format)
+		}
+
+		// The top left module of the finder pattern is black, the quiet
+		// zone white
+		finder, quiet := grid[[2]int{3 + 2*q, 1 + q}], grid[[2]int{3, 1}]
+		if format == FormatHalfBlocks {
+			finder = grid[[2]int{3 + q, 1 + q/2}]
+		}
+		if finder.fg != ColorBlack || quiet.fg != ColorWhite || quiet.bg != ColorWhite {
+			t.Errorf("%s: unexpected finder %+v or quiet zone %+v", format, finder, quiet)
+		}
+		for y := 0; y < bm.Size; y++ {
+			for x := 0; x < bm.Size; x++ {
+				var c textCell
+				if format == FormatHalfBlocks {
+					c = grid[[2]int{3 + q + x, 1 + (q+y)/2}]
+					if (q+y)%2 == 1 {
+						c.fg = c.bg
+					}
+				} else {
+					c = grid[[2]int{3 + 2*(q+x), 1 + q + y}]
+				}
+				if (c.fg == ColorBlack) != bm.Black(x, y) {
+					t.Fatalf("%s: module %d,%d differs", format, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestTextGrid(t *testing.T) {
+	g := NewTextGrid(4, 2)
+	g.SetCell(1, 0, '▀', ColorBlack, ColorWhite)
+	g.SetCell(2, 0, '▀', ColorBlack, ColorWhite)
+	g.SetCell(9, 9, 'x', ColorBlack, ColorBlack)
+	want := " \033[30;47m▀▀\033[0m \n    \n"
+	if got := g.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	g = NewTextGrid(60, 30)
+	if err := RenderInto(g, 0, 0, []byte("test"), Config{Level: L, Format: FormatHalfBlocks}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(g.String(), "\n"); !strings.HasPrefix(lines[0], "\033[37;47m▀") {
+		t.Errorf("Unexpected first line %q", lines[0])
+	}
+}
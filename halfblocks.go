@@ -0,0 +1,50 @@
+package qrterminal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrHalfBlockGlyphs is returned when FormatHalfBlocks is given some custom
+// glyphs and the rest can not be derived from them, see OutputV2
+var ErrHalfBlockGlyphs = errors.New("qrterminal: incomplete half block glyphs")
+
+// halfBlockSets are the complete half block glyph sets the missing glyphs
+// of a partial set are derived from, keyed by their Black and White glyphs
+var halfBlockSets = []Glyphs{
+	{Black: BLACK_BLACK, White: WHITE_WHITE, BlackWhite: BLACK_WHITE, WhiteBlack: WHITE_BLACK},
+	// Inverted, for terminals with a light background
+	{Black: WHITE_WHITE, White: BLACK_BLACK, BlackWhite: WHITE_BLACK, WhiteBlack: BLACK_WHITE},
+}
+
+// deriveHalfBlocks completes the half block glyphs of c when BlackChar or
+// WhiteChar is set without both mixed glyphs, so a code is never drawn with
+// a mix of custom and default glyphs. Unset glyphs are taken from defaults.
+func (c *Config) deriveHalfBlocks(defaults Glyphs) error {
+	if c.BlackWhiteChar != "" && c.WhiteBlackChar != "" {
+		return nil
+	}
+	if c.BlackChar == "" && c.WhiteChar == "" {
+		return nil
+	}
+	black, white := c.BlackChar, c.WhiteChar
+	if black == "" {
+		black = defaults.Black
+	}
+	if white == "" {
+		white = defaults.White
+	}
+	for _, set := range halfBlockSets {
+		if set.Black == black && set.White == white {
+			if c.BlackWhiteChar == "" {
+				c.BlackWhiteChar = set.BlackWhite
+			}
+			if c.WhiteBlackChar == "" {
+				c.WhiteBlackChar = set.WhiteBlack
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: set BlackWhiteChar and WhiteBlackChar to go with BlackChar %q and WhiteChar %q",
+		ErrHalfBlockGlyphs, black, white)
+}
@@ -0,0 +1,96 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDeriveHalfBlocks(t *testing.T) {
+	defaults := outputSpecs[OutputV2].glyphs
+	testCases := []struct {
+		name   string
+		config Config
+		want   Glyphs
+		err    bool
+	}{
+		{"Defaults", Config{}, Glyphs{}, false},
+		{"Inverted", Config{BlackChar: WHITE_WHITE, WhiteChar: BLACK_BLACK},
+			Glyphs{Black: WHITE_WHITE, White: BLACK_BLACK, BlackWhite: WHITE_BLACK, WhiteBlack: BLACK_WHITE}, false},
+		{"ExplicitDefaults", Config{BlackChar: BLACK_BLACK},
+			Glyphs{Black: BLACK_BLACK, BlackWhite: BLACK_WHITE, WhiteBlack: WHITE_BLACK}, false},
+		{"OneMixedGlyphSet", Config{BlackChar: WHITE_WHITE, WhiteChar: BLACK_BLACK, BlackWhiteChar: "x"},
+			Glyphs{Black: WHITE_WHITE, White: BLACK_BLACK, BlackWhite: "x", WhiteBlack: BLACK_WHITE}, false},
+		{"Complete", Config{BlackChar: "a", WhiteChar: "b", BlackWhiteChar: "c", WhiteBlackChar: "d"},
+			Glyphs{Black: "a", White: "b", BlackWhite: "c", WhiteBlack: "d"}, false},
+		{"Underivable", Config{BlackChar: "a", WhiteChar: "b"}, Glyphs{}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.config
+			err := c.deriveHalfBlocks(defaults)
+			if tc.err {
+				if !errors.Is(err, ErrHalfBlockGlyphs) {
+					t.Errorf("Expected ErrHalfBlockGlyphs, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("deriveHalfBlocks failed: %v", err)
+			}
+			got := Glyphs{Black: c.BlackChar, White: c.WhiteChar, BlackWhite: c.BlackWhiteChar, WhiteBlack: c.WhiteBlackChar}
+			if got != tc.want {
+				t.Errorf("Expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHalfBlocksNotMixed(t *testing.T) {
+	// An inverted code only uses inverted glyphs
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, HalfBlocks: true, BlackChar: WHITE_WHITE, WhiteChar: BLACK_BLACK})
+	var inverted bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &inverted, HalfBlocks: true,
+		BlackChar: WHITE_WHITE, WhiteChar: BLACK_BLACK, BlackWhiteChar: WHITE_BLACK, WhiteBlackChar: BLACK_WHITE})
+	if buf.String() != inverted.String() {
+		t.Errorf("Expected the inverted mixed glyphs to be derived")
+	}
+
+	// Glyphs that can not be completed are rejected
+	buf.Reset()
+	config := Config{Level: L, Writer: &buf, Format: FormatHalfBlocks, BlackChar: "a", WhiteChar: "b"}
+	if err := GenerateFromReader(strings.NewReader("test"), 0, config); !errors.Is(err, ErrHalfBlockGlyphs) {
+		t.Errorf("Expected ErrHalfBlockGlyphs, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Nothing should be drawn with incomplete glyphs")
+	}
+	if !hasWarning(Lint(config), "half-blocks") {
+		t.Errorf("Expected a half-blocks warning")
+	}
+
+	// The functions that can not return the error draw the code, as before
+	var legacy bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &legacy, HalfBlocks: true, BlackChar: "X", WhiteChar: "."})
+	if !strings.Contains(legacy.String(), "X") || !strings.Contains(legacy.String(), BLACK_WHITE) {
+		t.Errorf("Expected GenerateWithConfig to mix the glyphs, got %q", legacy.String())
+	}
+	if err := GenerateText("test", Config{Level: L, Writer: io.Discard, HalfBlocks: true, BlackChar: "X", WhiteChar: "."}); !errors.Is(err, ErrHalfBlockGlyphs) {
+		t.Errorf("Expected GenerateText to return ErrHalfBlockGlyphs, got %v", err)
+	}
+
+	// OutputV1 keeps mixing them
+	config.OutputVersion = OutputV1
+	if err := GenerateFromReader(strings.NewReader("test"), 0, config); err != nil {
+		t.Errorf("OutputV1 should draw the code, got %v", err)
+	}
+	if !strings.Contains(buf.String(), BLACK_WHITE) {
+		t.Errorf("Expected OutputV1 to fall back to the default mixed glyphs")
+	}
+	if !hasWarning(Lint(config), "half-blocks") {
+		t.Errorf("Expected a half-blocks warning for mixed glyphs")
+	}
+}
@@ -0,0 +1,40 @@
+package qrterminal
+
+import (
+	"io"
+	"net/url"
+)
+
+// writeHyperlink writes data as an OSC 8 hyperlink on a line of its own if
+// it is a web URL, and reports whether it did. newline starts a new line
+// first, for formats whose output does not end with one.
+func writeHyperlink(w io.Writer, data []byte, newline bool) bool {
+	link, ok := webURL(data)
+	if !ok {
+		return false
+	}
+	if newline {
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, "\033]8;;"+link+"\033\\"+link+"\033]8;;\033\\\n")
+	return true
+}
+
+// webURL returns data as a string if it is an absolute http or https URL
+// made of printable ASCII only, which can be embedded in an escape sequence
+// without altering it
+func webURL(data []byte) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	for _, b := range data {
+		if b <= ' ' || b > '~' {
+			return "", false
+		}
+	}
+	u, err := url.Parse(string(data))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	return string(data), true
+}
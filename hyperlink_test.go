@@ -0,0 +1,64 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWebURL(t *testing.T) {
+	testCases := []struct {
+		data string
+		want bool
+	}{
+		{"https://github.com/mdp/qrterminal", true},
+		{"http://example.com/?q=1#top", true},
+		{"HTTPS://EXAMPLE.COM", true},
+		{"example.com", false},
+		{"ftp://example.com", false},
+		{"https://", false},
+		{"WIFI:S:home;T:WPA;P:secret;;", false},
+		{"https://example.com/\x1b]8;;evil", false},
+		{"https://example.com/a b", false},
+		{"https://example.com/ü", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		if _, got := webURL([]byte(tc.data)); got != tc.want {
+			t.Errorf("webURL(%q) = %v, want %v", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestHyperlinkCaption(t *testing.T) {
+	link := "https://github.com/mdp/qrterminal"
+	caption := "\033]8;;" + link + "\033\\" + link + "\033]8;;\033\\\n"
+
+	var plain, linked bytes.Buffer
+	GenerateWithConfig(link, Config{Level: L, Writer: &plain, QuietZone: 1})
+	GenerateWithConfig(link, Config{Level: L, Writer: &linked, QuietZone: 1, Hyperlink: true})
+	if linked.String() != plain.String()+caption {
+		t.Errorf("Expected the caption to follow the code, got %q", strings.TrimPrefix(linked.String(), plain.String()))
+	}
+
+	// The caption starts on a new line after sixel output
+	var sixel bytes.Buffer
+	GenerateWithConfig(link, Config{Level: L, Writer: &sixel, Format: FormatSixel, Hyperlink: true})
+	if !strings.HasSuffix(sixel.String(), SIXEL_END+"\n"+caption) {
+		t.Errorf("Expected the caption on its own line after the sixel image")
+	}
+
+	// Bits output stays machine readable
+	var bits bytes.Buffer
+	GenerateWithConfig(link, Config{Level: L, Writer: &bits, Format: FormatBits, Hyperlink: true})
+	if strings.Contains(bits.String(), "\033") {
+		t.Errorf("Bits output should never carry a hyperlink")
+	}
+
+	// Data that is not a web URL gets no caption
+	var text bytes.Buffer
+	GenerateWithConfig("hello", Config{Level: L, Writer: &text, Hyperlink: true})
+	if strings.Contains(text.String(), "\033]8") {
+		t.Errorf("Expected no caption for plain text")
+	}
+}
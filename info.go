@@ -0,0 +1,101 @@
+package qrterminal
+
+import "rsc.io/qr/coding"
+
+// EncodeInfo describes how data is encoded and drawn with a Config, see
+// Info
+type EncodeInfo struct {
+	// Symbology is the encoder used, SymbologyQR if Config.Symbology is
+	// empty
+	Symbology string
+	// Level is the error correction level
+	Level Level
+	// Modules is the number of modules on a side, without the quiet zone
+	Modules int
+	// Format is the renderer that draws the code
+	Format Format
+	// RenderMode is the one of Config.Fallbacks chosen to draw the code,
+	// empty without Fallbacks
+	RenderMode RenderMode
+	// Cells and Lines are the size of the code in the terminal as reported
+	// by RenderedSize, zero for formats not drawn in the terminal
+	Cells, Lines int
+
+	// The fields below are only set for SymbologyQR
+
+	// Version is the symbol version, 1 to 40
+	Version int
+	// Mode is the encoding of the data: "numeric", "alphanumeric" or
+	// "byte"
+	Mode string
+	// DataBits is the number of bits the data takes up in the symbol,
+	// CapacityBits the number the version holds at the level
+	DataBits, CapacityBits int
+}
+
+// Utilization returns the share of the capacity of the symbol taken up by
+// the data, from 0 to 1, or 0 if it is not known
+func (i EncodeInfo) Utilization() float64 {
+	if i.CapacityBits == 0 {
+		return 0
+	}
+	return float64(i.DataBits) / float64(i.CapacityBits)
+}
+
+// Info encodes data with config and reports the result without rendering
+// it, so a payload can be checked before it is displayed. It returns the
+// error rendering would fail with, e.g. for data too long for any version.
+func Info(data []byte, config Config) (EncodeInfo, error) {
+	bm, err := config.encode(data)
+	if err != nil {
+		return EncodeInfo{}, err
+	}
+	var mode RenderMode
+	config.Profile.adjust(&config)
+	if len(config.Fallbacks) > 0 {
+		if config, mode, err = config.fallback(data); err != nil {
+			return EncodeInfo{}, err
+		}
+	}
+	info := EncodeInfo{
+		Symbology:  config.Symbology,
+		Level:      config.Level,
+		Modules:    bm.Size,
+		RenderMode: mode,
+	}
+	if info.Symbology == "" {
+		info.Symbology = SymbologyQR
+	}
+
+	info.Format = config.format()
+	format, ok := formatSpecFor(info.Format)
+	if !ok {
+		info.Format = FormatBlocks
+		format, _ = formatSpecFor(FormatBlocks)
+	}
+	if !format.document {
+		if info.Cells, info.Lines, err = RenderedSize(data, config); err != nil {
+			return EncodeInfo{}, err
+		}
+	}
+
+	if info.Symbology == SymbologyQR {
+		payload, _ := config.payload(data)
+		// The same choice of encoding as rsc.io/qr makes
+		var enc coding.Encoding
+		switch text := string(payload); {
+		case coding.Num(text).Check() == nil:
+			enc, info.Mode = coding.Num(text), "numeric"
+		case coding.Alpha(text).Check() == nil:
+			enc, info.Mode = coding.Alpha(text), "alphanumeric"
+		default:
+			enc, info.Mode = coding.String(text), "byte"
+		}
+		// A version v QR code has 4v+17 modules on a side
+		v := coding.Version((bm.Size - 17) / 4)
+		info.Version = int(v)
+		info.DataBits = enc.Bits(v)
+		info.CapacityBits = v.DataBytes(coding.Level(config.Level.QR())) * 8
+	}
+	return info, nil
+}
@@ -0,0 +1,64 @@
+package qrterminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		config  Config
+		version int
+		mode    string
+		bits    int
+		format  Format
+	}{
+		{"numeric", "01234567", Config{Level: M}, 1, "numeric", 41, FormatBlocks},
+		{"alphanumeric", "HELLO WORLD", Config{Level: Q}, 1, "alphanumeric", 74, FormatBlocks},
+		{"byte", "https://github.com/mdp/qrterminal", Config{Level: L, Format: FormatHalfBlocks}, 3, "byte", 276, FormatHalfBlocks},
+		{"profile", "hello", Config{Level: L, WithSixel: true, Profile: ProfileXtermJS}, 1, "byte", 52, FormatBlocks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Info([]byte(tt.data), tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Symbology != SymbologyQR || info.Version != tt.version || info.Mode != tt.mode || info.DataBits != tt.bits || info.Format != tt.format {
+				t.Errorf("Got %+v, want version %d, %s mode, %d bits and format %s", info, tt.version, tt.mode, tt.bits, tt.format)
+			}
+			if info.Modules != 4*tt.version+17 {
+				t.Errorf("Expected %d modules, got %d", 4*tt.version+17, info.Modules)
+			}
+			if u := info.Utilization(); u <= 0 || u > 1 {
+				t.Errorf("Utilization %v out of range", u)
+			}
+			cells, lines, _ := RenderedSize([]byte(tt.data), tt.config)
+			if info.Cells != cells || info.Lines != lines {
+				t.Errorf("Size %dx%d differs from RenderedSize %dx%d", info.Cells, info.Lines, cells, lines)
+			}
+		})
+	}
+}
+
+func TestInfoDocument(t *testing.T) {
+	info, err := Info([]byte("hello"), Config{Level: L, Format: FormatPDF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != FormatPDF || info.Cells != 0 || info.Lines != 0 {
+		t.Errorf("Expected the pdf format without a terminal size, got %+v", info)
+	}
+}
+
+func TestInfoTooLong(t *testing.T) {
+	if _, err := Info([]byte(strings.Repeat("x", 3000)), Config{Level: H}); err == nil {
+		t.Error("Expected an error for data that does not fit")
+	}
+	// No data is a valid payload
+	if _, err := Info(nil, Config{Level: L}); err != nil {
+		t.Errorf("Expected the code of no data, got %v", err)
+	}
+}
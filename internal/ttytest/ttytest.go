@@ -0,0 +1,89 @@
+// Package ttytest replays terminals recorded as JSON files, so terminal
+// detection can be tested without a terminal.
+//
+// A recording lists the environment of a terminal session and the replies
+// the terminal gave to queries such as DA1 ("\x1b[c"), DA2 ("\x1b[>c") and
+// XTGETTCAP ("\x1bP+q...\x1b\\"), along with what detection is expected to
+// find:
+//
+//	{
+//		"name": "kitty 0.35",
+//		"env": {"TERM": "xterm-kitty"},
+//		"replies": {"\u001b[c": "\u001b[?62;c"},
+//		"termcap": {"TN": "xterm-kitty"},
+//		"want": {"sixel": false},
+//		"profile": ""
+//	}
+package ttytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ErrNoReply is returned by Query for a query the terminal did not answer
+var ErrNoReply = errors.New("ttytest: no reply recorded")
+
+// Recording is a recorded terminal, it implements the Terminal interface
+// of the qrterminal package
+type Recording struct {
+	Name    string            `json:"name"`
+	Env     map[string]string `json:"env"`
+	Replies map[string]string `json:"replies"`
+	// Termcap maps terminfo capabilities the terminal answers XTGETTCAP
+	// for to the values it reports
+	Termcap map[string]string `json:"termcap"`
+	// Want maps capability names, in lower case, to their expected value
+	Want map[string]bool `json:"want"`
+	// Profile is the name of the expected profile, empty for none
+	Profile string `json:"profile"`
+
+	// Queries lists the queries sent to the terminal, in order
+	Queries []string `json:"-"`
+}
+
+// Getenv returns the recorded environment variable key
+func (r *Recording) Getenv(key string) string {
+	return r.Env[key]
+}
+
+// IsTerminal is always true, recordings are made on terminals
+func (r *Recording) IsTerminal() bool {
+	return true
+}
+
+// Query returns the recorded reply to seq
+func (r *Recording) Query(seq string) ([]byte, error) {
+	r.Queries = append(r.Queries, seq)
+	reply, ok := r.Replies[seq]
+	if !ok {
+		return nil, ErrNoReply
+	}
+	return []byte(reply), nil
+}
+
+// Load reads every recording in dir, sorted by file name
+func Load(dir string) ([]*Recording, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var recs []*Recording
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var r Recording
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("ttytest: %s: %w", name, err)
+		}
+		recs = append(recs, &r)
+	}
+	return recs, nil
+}
@@ -0,0 +1,74 @@
+package qrterminal
+
+import (
+	"fmt"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// Level is the error correction level of a code, the share of the code
+// that can be damaged and still be read
+type Level int
+
+// Error correction levels
+const (
+	L Level = iota // 7%
+	M              // 15%
+	Q              // 25%
+	H              // 30%
+)
+
+var levelNames = [...]string{L: "L", M: "M", Q: "Q", H: "H"}
+
+// String returns the letter of l, e.g. "M"
+func (l Level) String() string {
+	if l < L || l > H {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named by s, one of L, M, Q or H in either
+// case
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("qrterminal: invalid error correction level %q, expected L, M, Q or H", s)
+}
+
+// QR returns l as a level of rsc.io/qr
+func (l Level) QR() qr.Level {
+	return qr.Level(l)
+}
+
+// LevelFromQR returns the Level of a level of rsc.io/qr
+func LevelFromQR(l qr.Level) Level {
+	return Level(l)
+}
+
+// Set parses s as with ParseLevel, so a *Level can be used as a flag.Value
+func (l *Level) Set(s string) error {
+	v, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	if l < L || l > H {
+		return nil, fmt.Errorf("qrterminal: invalid error correction level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
@@ -0,0 +1,66 @@
+package qrterminal
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range []Level{L, M, Q, H} {
+		for _, s := range []string{l.String(), string(l.String()[0] + 'a' - 'A')} {
+			got, err := ParseLevel(s)
+			if err != nil || got != l {
+				t.Errorf("ParseLevel(%q) = %v, %v, expected %v", s, got, err, l)
+			}
+		}
+	}
+	for _, s := range []string{"", "x", "LL", "Level(1)"} {
+		if _, err := ParseLevel(s); err == nil {
+			t.Errorf("ParseLevel(%q) should fail", s)
+		}
+	}
+}
+
+func TestLevelQR(t *testing.T) {
+	pairs := map[Level]qr.Level{L: qr.L, M: qr.M, Q: qr.Q, H: qr.H}
+	for l, want := range pairs {
+		if l.QR() != want {
+			t.Errorf("%v.QR() = %v, expected %v", l, l.QR(), want)
+		}
+		if LevelFromQR(want) != l {
+			t.Errorf("LevelFromQR(%v) = %v, expected %v", want, LevelFromQR(want), l)
+		}
+	}
+	if s := Level(7).String(); s != "Level(7)" {
+		t.Errorf("Unexpected name %q for an invalid level", s)
+	}
+}
+
+func TestLevelFlag(t *testing.T) {
+	var l Level
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&l, "l", "level")
+	if err := fs.Parse([]string{"-l", "q"}); err != nil || l != Q {
+		t.Errorf("Expected Q from the flag, got %v, %v", l, err)
+	}
+	if err := fs.Parse([]string{"-l", "x"}); err == nil {
+		t.Errorf("An invalid level should be rejected")
+	}
+
+	var config struct{ Level Level }
+	if err := json.Unmarshal([]byte(`{"Level": "h"}`), &config); err != nil || config.Level != H {
+		t.Errorf("Expected H from JSON, got %v, %v", config.Level, err)
+	}
+	out, err := json.Marshal(config)
+	if err != nil || string(out) != `{"Level":"H"}` {
+		t.Errorf("Unexpected JSON %s, %v", out, err)
+	}
+	if _, err := json.Marshal(struct{ Level Level }{Level(9)}); err == nil {
+		t.Errorf("An invalid level should not be marshaled")
+	}
+}
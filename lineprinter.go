@@ -0,0 +1,68 @@
+package qrterminal
+
+import (
+	"bytes"
+	"io"
+)
+
+// LinePrinterOptions tunes FormatLinePrinter output
+type LinePrinterOptions struct {
+	// Overstrike are the characters struck on top of each other to darken
+	// a black module, one pass over the line for each. More characters
+	// give denser modules, e.g. "MW#" on a faint ribbon. "M" if empty.
+	Overstrike string
+	// DoubleStrike makes every pass twice
+	DoubleStrike bool
+}
+
+// defaultOverstrike is a single pass of the darkest common character
+const defaultOverstrike = "M"
+
+// passes returns the characters of the passes over every line
+func (o *LinePrinterOptions) passes() []byte {
+	strikes := defaultOverstrike
+	if o != nil && o.Overstrike != "" {
+		strikes = o.Overstrike
+	}
+	var passes []byte
+	for i := 0; i < len(strikes); i++ {
+		passes = append(passes, strikes[i])
+		if o != nil && o.DoubleStrike {
+			passes = append(passes, strikes[i])
+		}
+	}
+	return passes
+}
+
+// writeLinePrinter writes the code as plain ASCII for fixed-pitch line
+// printers, which print dark on white paper: black modules are printed as
+// two characters, white ones and the quiet zone are left blank. Passes
+// after the first return the carriage to the start of the line and strike
+// it again. Lines end after their last black module.
+func (c *Config) writeLinePrinter(w io.Writer, bm *Bitmap) {
+	passes := c.LinePrinter.passes()
+	size := bm.Size + 2*c.QuietZone
+	line := make([]byte, 2*size)
+	var out bytes.Buffer
+	for y := 0; y < size; y++ {
+		for i, pass := range passes {
+			for x := 0; x < size; x++ {
+				ch := byte(' ')
+				if bm.Black(x-c.QuietZone, y-c.QuietZone) {
+					ch = pass
+				}
+				line[2*x], line[2*x+1] = ch, ch
+			}
+			printed := bytes.TrimRight(line, " ")
+			if len(printed) == 0 {
+				break
+			}
+			if i > 0 {
+				out.WriteByte('\r')
+			}
+			out.Write(printed)
+		}
+		out.WriteByte('\n')
+	}
+	w.Write(out.Bytes())
+}
@@ -0,0 +1,82 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLinePrinter(t *testing.T) {
+	var blocks, printed bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &blocks, QuietZone: 1, BlackChar: "  ", WhiteChar: "MM"})
+	GenerateWithConfig("test", Config{Level: L, Writer: &printed, QuietZone: 1, Format: FormatLinePrinter})
+	if strings.ContainsAny(printed.String(), "\033\r█") {
+		t.Errorf("Expected plain ASCII without overstrike, got %q", printed.String())
+	}
+
+	// Paper is white, so the modules are inverted compared to the terminal
+	// glyphs and trailing blanks are not printed
+	blockLines := strings.Split(blocks.String(), "\n")
+	for i, line := range strings.Split(printed.String(), "\n") {
+		want := strings.Map(func(r rune) rune {
+			if r == 'M' {
+				return ' '
+			}
+			return 'M'
+		}, blockLines[i])
+		if line != strings.TrimRight(want, " ") {
+			t.Fatalf("Line %d\n got %q\nwant %q", i, line, want)
+		}
+	}
+}
+
+func TestLinePrinterOverstrike(t *testing.T) {
+	var plain, struck bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &plain, QuietZone: 1, Format: FormatLinePrinter})
+	GenerateWithConfig("test", Config{
+		Level:       L,
+		Writer:      &struck,
+		QuietZone:   1,
+		Format:      FormatLinePrinter,
+		LinePrinter: &LinePrinterOptions{Overstrike: "MW", DoubleStrike: true},
+	})
+	plainLines := strings.Split(plain.String(), "\n")
+	for i, line := range strings.Split(struck.String(), "\n") {
+		if plainLines[i] == "" {
+			if line != "" {
+				t.Errorf("Line %d: expected a blank line to stay blank, got %q", i, line)
+			}
+			continue
+		}
+		w := strings.ReplaceAll(plainLines[i], "M", "W")
+		want := strings.Join([]string{plainLines[i], plainLines[i], w, w}, "\r")
+		if line != want {
+			t.Fatalf("Line %d\n got %q\nwant %q", i, line, want)
+		}
+	}
+}
+
+func TestProfileLinePrinter(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("https://github.com/mdp/qrterminal", Config{
+		Level:     L,
+		Writer:    &buf,
+		QuietZone: 1,
+		Hyperlink: true,
+		Profile:   ProfileLinePrinter,
+	})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 29+2*QUIET_ZONE {
+		t.Errorf("Expected the full quiet zone, got %d lines for a 29 module code", len(lines))
+	}
+	if strings.ContainsAny(buf.String(), "\033█") {
+		t.Errorf("Expected line printer output, got %q", buf.String())
+	}
+
+	// A format chosen by the Config wins
+	buf.Reset()
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, Format: FormatBits, Profile: ProfileLinePrinter})
+	if strings.Trim(buf.String(), "01\n") != "" {
+		t.Errorf("Expected bits output, got %q", buf.String())
+	}
+}
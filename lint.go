@@ -0,0 +1,88 @@
+package qrterminal
+
+import "fmt"
+
+// Warning is an advisory about a Config that may produce a code which is
+// hard to scan
+type Warning struct {
+	// Code is a short stable identifier for the kind of issue
+	Code    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Code + ": " + w.Message
+}
+
+// Lint checks cfg for settings that are known to hurt scannability. None of
+// them prevent generating a code.
+func Lint(cfg Config) []Warning {
+	var warnings []Warning
+	isQR := cfg.Symbology == "" || cfg.Symbology == SymbologyQR
+	if isQR && cfg.QuietZone < QUIET_ZONE && cfg.format() != FormatBits {
+		warnings = append(warnings, Warning{"quiet-zone",
+			fmt.Sprintf("a quiet zone of %d modules is narrower than the %d the QR specification asks for", cfg.QuietZone, QUIET_ZONE)})
+	}
+	if cfg.BlackChar != "" && cfg.BlackChar == cfg.WhiteChar {
+		warnings = append(warnings, Warning{"contrast",
+			fmt.Sprintf("black and white modules are both drawn as %q", cfg.BlackChar)})
+	}
+	spec, err := outputSpecFor(cfg.OutputVersion)
+	if err != nil {
+		warnings = append(warnings, Warning{"output-version", fmt.Sprintf("no code is drawn, %v", err)})
+	} else if cfg.format() == FormatHalfBlocks {
+		c := cfg
+		c.Theme.apply(&c, FormatHalfBlocks)
+		if err := c.deriveHalfBlocks(spec.glyphs); err != nil {
+			msg := "custom half block glyphs are mixed with the default ones"
+			if spec.deriveHalfBlocks {
+				msg = "no code is drawn"
+			}
+			warnings = append(warnings, Warning{"half-blocks", fmt.Sprintf("%s, %v", msg, err)})
+		}
+	}
+	warnings = append(warnings, lintQuietZone(cfg)...)
+	if cfg.Backdrop != nil && cfg.Backdrop.Transparent {
+		warnings = append(warnings, Warning{"transparent",
+			"the white modules and the quiet zone take the terminal background, the code only scans if it is light, plain and in contrast to the Ink"})
+	}
+	if cfg.Theme != nil {
+		for _, c := range cfg.Theme.Caveats {
+			warnings = append(warnings, Warning{"theme", cfg.Theme.Name + ": " + c})
+		}
+	}
+	return warnings
+}
+
+// LintData runs Lint and also checks data as encoded with cfg, e.g. for
+// text that would make a smaller code in upper case
+func LintData(data []byte, cfg Config) []Warning {
+	return append(Lint(cfg), lintAlphanumeric(data, cfg)...)
+}
+
+// lintQuietZone checks that a custom quiet zone is drawn and stays light
+func lintQuietZone(cfg Config) []Warning {
+	if cfg.QuietChar == "" && cfg.QuietColor == "" {
+		return nil
+	}
+	if cfg.format() != FormatBlocks {
+		return []Warning{{"quiet-zone-style",
+			fmt.Sprintf("QuietChar and QuietColor only apply to the blocks format, not %s", cfg.format())}}
+	}
+	var warnings []Warning
+	if cfg.QuietChar != "" && cfg.QuietChar == cfg.BlackChar {
+		warnings = append(warnings, Warning{"quiet-zone-style",
+			fmt.Sprintf("the quiet zone is drawn as %q like black modules", cfg.QuietChar)})
+	}
+	if cfg.QuietColor != "" {
+		rgb, ok := sgrBackground(cfg.QuietColor)
+		if !ok {
+			warnings = append(warnings, Warning{"quiet-zone-style",
+				fmt.Sprintf("QuietColor %q sets no background color, the quiet zone takes the terminal background", cfg.QuietColor)})
+		} else if ratio := contrastToBlack(rgb); ratio < minQuietContrast {
+			warnings = append(warnings, Warning{"quiet-zone-style",
+				fmt.Sprintf("the QuietColor background has a contrast of %.1f:1 to black modules, scanners need at least %.1f:1", ratio, minQuietContrast)})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,82 @@
+package qrterminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func hasWarning(warnings []Warning, code string) bool {
+	for _, w := range warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"Default", Config{QuietZone: QUIET_ZONE}, nil},
+		{"NarrowQuietZone", Config{QuietZone: 1}, []string{"quiet-zone"}},
+		{"BitsIgnoreQuietZone", Config{Format: FormatBits}, nil},
+		{"SameGlyphs", Config{QuietZone: QUIET_ZONE, BlackChar: "##", WhiteChar: "##"}, []string{"contrast"}},
+		{"ShadeTheme", Config{QuietZone: QUIET_ZONE, Theme: ThemeShade}, []string{"theme"}},
+		{"BrightQuietColor", Config{QuietZone: QUIET_ZONE, QuietColor: "107"}, nil},
+		{"TrueColorQuietColor", Config{QuietZone: QUIET_ZONE, QuietColor: "48;2;250;250;240"}, nil},
+		{"DarkQuietColor", Config{QuietZone: QUIET_ZONE, QuietColor: "44"}, []string{"quiet-zone-style"}},
+		{"ForegroundQuietColor", Config{QuietZone: QUIET_ZONE, QuietColor: "97"}, []string{"quiet-zone-style"}},
+		{"BlackQuietChar", Config{QuietZone: QUIET_ZONE, BlackChar: "  ", WhiteChar: "██", QuietChar: "  "}, []string{"quiet-zone-style"}},
+		{"WhiteBackdrop", Config{QuietZone: QUIET_ZONE, Backdrop: BackdropWhite}, nil},
+		{"TransparentBackdrop", Config{QuietZone: QUIET_ZONE, HalfBlocks: true, Backdrop: BackdropTransparent}, []string{"transparent"}},
+		{"HalfBlocksQuietChar", Config{QuietZone: QUIET_ZONE, HalfBlocks: true, QuietChar: "░"}, []string{"quiet-zone-style"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := Lint(tc.config)
+			if len(warnings) < len(tc.want) || (tc.want == nil && len(warnings) > 0) {
+				t.Errorf("Unexpected warnings %v", warnings)
+			}
+			for _, code := range tc.want {
+				if !hasWarning(warnings, code) {
+					t.Errorf("Expected a %q warning, got %v", code, warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestLintAlphanumeric(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		config Config
+		want   string // part of the message, empty for no warning
+	}{
+		{"Text", "hello world qrterm", Config{Level: L, QuietZone: QUIET_ZONE}, "version 1 instead of 2"},
+		{"URL", "https://example.com/T0K3N", Config{Level: L, QuietZone: QUIET_ZONE}, "AutoUppercaseURLs"},
+		{"URLPath", "https://example.com/token", Config{Level: L, QuietZone: QUIET_ZONE}, "case sensitive"},
+		{"AutoUppercase", "https://example.com/T0K3N", Config{Level: L, QuietZone: QUIET_ZONE, AutoUppercaseURLs: true}, ""},
+		{"SameVersion", "hello", Config{Level: L, QuietZone: QUIET_ZONE}, ""},
+		{"NotAlphanumeric", "hello, world qrterm", Config{Level: L, QuietZone: QUIET_ZONE}, ""},
+		{"Alphanumeric", "HELLO WORLD", Config{Level: L, QuietZone: QUIET_ZONE}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := LintData([]byte(tc.data), tc.config)
+			if tc.want == "" {
+				if len(warnings) > 0 {
+					t.Errorf("Unexpected warnings %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || warnings[0].Code != "alphanumeric" || !strings.Contains(warnings[0].Message, tc.want) {
+				t.Errorf("Expected an alphanumeric warning mentioning %q, got %v", tc.want, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package qrterminal
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+// lowMemoryBudget is the most a version 40 code may allocate when drawn
+// with LowMemory, as documented in the README
+const lowMemoryBudget = 1536 << 10
+
+func TestLowMemory(t *testing.T) {
+	// Keep the heap as small as a router would, the limit is soft so the
+	// test fails on the budget below rather than on the limit
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(4 << 20))
+
+	data := bytes.Repeat([]byte("x"), 2900) // version 40 at level L
+	for _, format := range []Format{FormatBlocks, FormatHalfBlocks} {
+		for _, profile := range []*Profile{nil, ProfileRemote} {
+			var normal, low bytes.Buffer
+			GenerateWithConfig(string(data), Config{Level: L, Writer: &normal, Format: format, Profile: profile})
+			config := Config{Level: L, Writer: &low, Format: format, Profile: profile, LowMemory: true}
+			GenerateWithConfig(string(data), config)
+			if !bytes.Equal(normal.Bytes(), low.Bytes()) && profile == nil {
+				t.Errorf("%s: LowMemory changed the output", format)
+			}
+
+			config.Writer = io.Discard
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			GenerateWithConfig(string(data), config)
+			runtime.ReadMemStats(&after)
+			if n := after.TotalAlloc - before.TotalAlloc; n > lowMemoryBudget {
+				t.Errorf("%s with profile %v: allocated %d KiB, the budget is %d KiB", format, profile != nil, n>>10, lowMemoryBudget>>10)
+			}
+		}
+	}
+
+	bm, _ := Encode(data, Config{Level: L, LowMemory: true})
+	if bm.Role(0, 0) != RoleUnknown {
+		t.Errorf("Expected no module roles with LowMemory")
+	}
+}
@@ -0,0 +1,168 @@
+// Package manualcode implements the manual-entry format qrterminal prints
+// under a code with Config.BackupText, for typing the data in by hand when
+// the code can not be scanned.
+//
+// The data is split into chunks of LineBytes bytes, one per line. A line is
+// the Base32 encoding of its chunk, without padding, followed by 4 check
+// characters, and is printed in groups of 4 characters. "hello world" is
+// a single line ending in the check characters FTJZ:
+//
+//	NBSW Y3DP EB3W 64TM MQFT JZ
+//
+// The check characters are the top 20 bits of the CRC-32 of the line number as
+// two big-endian bytes, a byte that is 1 on the last line and 0 on the
+// others, and the chunk. A typo is thus reported with its line, and lines
+// that were skipped, swapped or cut off do not check.
+package manualcode
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// LineBytes is the number of data bytes on every line but the last, which
+// encode to 24 Base32 characters
+const LineBytes = 15
+
+// Group is the number of characters in a group
+const Group = 4
+
+// checkSize is the number of check characters at the end of a line
+const checkSize = 4
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var (
+	// ErrChecksum is returned for a line whose check characters do not match,
+	// usually because of a typo
+	ErrChecksum = errors.New("manualcode: checksum mismatch")
+	// ErrTruncated is returned when the last line is missing
+	ErrTruncated = errors.New("manualcode: code is truncated")
+	// ErrInvalid is returned for a line that is not Base32 or too short to
+	// hold the check characters
+	ErrInvalid = errors.New("manualcode: invalid characters")
+)
+
+// LineError records the line, counted from 1, a decoding error was found on
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Encode formats data as a manual-entry code, one line per LineBytes bytes
+// and lines separated by "\n". Empty data encodes to the check characters
+// alone.
+func Encode(data []byte) string {
+	var lines []string
+	for i := 0; i == 0 || i*LineBytes < len(data); i++ {
+		end := (i + 1) * LineBytes
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[i*LineBytes : end]
+		text := encoding.EncodeToString(chunk) + check(i, end == len(data), chunk)
+		lines = append(lines, groups(text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Decode returns the data of a code written by Encode, checking every
+// line. Blank lines, spaces and dashes are ignored, lower case is
+// accepted, and the digits 0, 1 and 8, which Base32 does not use, are read
+// as the letters O, I and B they are mistaken for. Errors for a line are
+// reported as a *LineError.
+func Decode(s string) ([]byte, error) {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = normalize(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, ErrTruncated
+	}
+
+	var data []byte
+	for i, line := range lines {
+		if len(line) < checkSize {
+			return nil, &LineError{i + 1, ErrInvalid}
+		}
+		text, sum := line[:len(line)-checkSize], line[len(line)-checkSize:]
+		chunk, err := encoding.DecodeString(text)
+		if err != nil {
+			return nil, &LineError{i + 1, ErrInvalid}
+		}
+		last := i == len(lines)-1
+		if sum != check(i, last, chunk) {
+			if last && sum == check(i, false, chunk) {
+				return nil, ErrTruncated
+			}
+			return nil, &LineError{i + 1, ErrChecksum}
+		}
+		if !last && len(chunk) != LineBytes {
+			return nil, &LineError{i + 1, ErrInvalid}
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+// check returns the check characters of line n
+func check(n int, last bool, chunk []byte) string {
+	head := make([]byte, 3, 3+len(chunk))
+	binary.BigEndian.PutUint16(head, uint16(n))
+	if last {
+		head[2] = 1
+	}
+	sum := crc32.ChecksumIEEE(append(head, chunk...))
+	// 20 bits are 4 Base32 characters, encoded from the top 3 bytes
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], sum)
+	return encoding.EncodeToString(b[:3])[:checkSize]
+}
+
+// groups separates text into groups of Group characters
+func groups(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i += Group {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		end := i + Group
+		if end > len(text) {
+			end = len(text)
+		}
+		b.WriteString(text[i:end])
+	}
+	return b.String()
+}
+
+// normalize drops the separators from a typed line and undoes the common
+// misreadings
+func normalize(line string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '-':
+			return -1
+		case '0':
+			return 'O'
+		case '1':
+			return 'I'
+		case '8':
+			return 'B'
+		}
+		return r
+	}, strings.ToUpper(line))
+}
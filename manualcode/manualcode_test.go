@@ -0,0 +1,89 @@
+package manualcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	if got, want := Encode([]byte("hello world")), "NBSW Y3DP EB3W 64TM MQFT JZ"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	code := Encode(bytes.Repeat([]byte{0xAA}, 2*LineBytes+1))
+	lines := strings.Split(code, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", code)
+	}
+	if got := strings.Fields(lines[0]); len(got) != 7 {
+		t.Errorf("Expected a full line of 7 groups, got %q", lines[0])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 5, LineBytes - 1, LineBytes, LineBytes + 1, 3 * LineBytes, 1000} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		code := Encode(data)
+		got, err := Decode(code)
+		if err != nil {
+			t.Fatalf("%d bytes: Decode(%q) failed: %v", n, code, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: expected %x back, got %x", n, data, got)
+		}
+	}
+}
+
+func TestDecodeTyped(t *testing.T) {
+	data := []byte("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP")
+	code := Encode(data)
+
+	// Typed by hand: lower case, dashes instead of spaces, digits for the
+	// letters they look like and blank lines between the lines
+	typed := strings.ToLower(strings.NewReplacer(" ", "-", "\n", "\r\n\n", "O", "0", "I", "1", "B", "8").Replace(code))
+	if got, err := Decode(typed); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Expected %q from %q, got %q, %v", data, typed, got, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	code := Encode(bytes.Repeat([]byte("0123456789"), 5))
+	lines := strings.Split(code, "\n")
+
+	// A single wrong character is reported with its line
+	typo := []byte(lines[1])
+	if typo[0] == 'A' {
+		typo[0] = 'C'
+	} else {
+		typo[0] = 'A'
+	}
+	mistyped := strings.Join([]string{lines[0], string(typo), lines[2], lines[3]}, "\n")
+	var lineErr *LineError
+	if _, err := Decode(mistyped); !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("Expected a checksum error on line 2, got %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		code string
+		err  error
+	}{
+		{"Swapped", strings.Join([]string{lines[1], lines[0], lines[2], lines[3]}, "\n"), ErrChecksum},
+		{"Skipped", strings.Join([]string{lines[0], lines[2], lines[3]}, "\n"), ErrChecksum},
+		{"Truncated", strings.Join(lines[:3], "\n"), ErrTruncated},
+		{"Empty", " \n", ErrTruncated},
+		{"Short", "AB", ErrInvalid},
+		{"NotBase32", "!!!! AAAA", ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Decode(tc.code); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
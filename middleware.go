@@ -0,0 +1,44 @@
+package qrterminal
+
+import "io"
+
+// RenderFunc draws an encoded symbol to w
+type RenderFunc func(w io.Writer, bm *Bitmap) error
+
+// Middleware wraps a RenderFunc to add behavior around it, such as
+// centering, framing, logging or throttling the output
+type Middleware func(next RenderFunc) RenderFunc
+
+// Use appends middleware to the render pipeline. The first middleware added
+// is the outermost one, it sees the symbol first and the output last.
+// Copies of c made before the call keep their own middleware.
+func (c *Config) Use(mw ...Middleware) {
+	// Capping the capacity makes append copy instead of writing into an
+	// array shared with those copies
+	n := len(c.middleware)
+	c.middleware = append(c.middleware[:n:n], mw...)
+}
+
+// chain wraps render in the middleware of c
+func (c *Config) chain(render RenderFunc) RenderFunc {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		render = c.middleware[i](render)
+	}
+	return render
+}
+
+// errWriter remembers the first error returned by w and drops all writes
+// after it, so renderers do not need to check every write
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
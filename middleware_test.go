@@ -0,0 +1,177 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next RenderFunc) RenderFunc {
+			return func(w io.Writer, bm *Bitmap) error {
+				calls = append(calls, name+" before")
+				err := next(w, bm)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	config := Config{Level: L, Writer: &buf}
+	config.Use(trace("outer"), trace("middle"))
+	config.Use(trace("inner"))
+	GenerateWithConfig("test", config)
+
+	want := "outer before,middle before,inner before,inner after,middle after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if buf.Len() == 0 {
+		t.Errorf("The renderer should still have been called")
+	}
+}
+
+func TestMiddlewareCopies(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next RenderFunc) RenderFunc {
+			return func(w io.Writer, bm *Bitmap) error {
+				calls = append(calls, name)
+				return next(w, bm)
+			}
+		}
+	}
+
+	// Grow the slice so it has room to spare
+	base := Config{Level: L, Writer: io.Discard}
+	for _, name := range []string{"a", "b", "c"} {
+		base.Use(trace(name))
+	}
+	first, second := base, base
+	first.Use(trace("first"))
+	second.Use(trace("second"))
+
+	for _, tc := range []struct {
+		config Config
+		want   string
+	}{
+		{first, "a,b,c,first"},
+		{second, "a,b,c,second"},
+		{base, "a,b,c"},
+	} {
+		calls = nil
+		GenerateWithConfig("test", tc.config)
+		if got := strings.Join(calls, ","); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestMiddlewareWrapsWriter(t *testing.T) {
+	var plain, framed bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &plain})
+
+	config := Config{Level: L, Writer: &framed}
+	config.Use(func(next RenderFunc) RenderFunc {
+		return func(w io.Writer, bm *Bitmap) error {
+			fmt.Fprintf(w, "%d modules\n", bm.Size)
+			return next(w, bm)
+		}
+	})
+	GenerateWithConfig("test", config)
+
+	if framed.String() != "21 modules\n"+plain.String() {
+		t.Errorf("Middleware output should precede the unchanged code")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestMiddlewareSeesWriteErrors(t *testing.T) {
+	var got error
+	config := Config{Level: L, Writer: failingWriter{}}
+	config.Use(func(next RenderFunc) RenderFunc {
+		return func(w io.Writer, bm *Bitmap) error {
+			got = next(w, bm)
+			return got
+		}
+	})
+	GenerateWithConfig("test", config)
+
+	if got == nil || got.Error() != "disk full" {
+		t.Errorf("Expected the write error to reach the middleware, got %v", got)
+	}
+}
+
+// prefixer starts every line of the output with a prefix
+type prefixer struct {
+	w      io.Writer
+	prefix string
+	mid    bool // in the middle of a line
+}
+
+func (p *prefixer) Write(b []byte) (int, error) {
+	var out []byte
+	for _, c := range b {
+		if !p.mid {
+			out = append(out, p.prefix...)
+		}
+		out = append(out, c)
+		p.mid = c != '\n'
+	}
+	if _, err := p.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func ExampleConfig_Use() {
+	config := Config{
+		Level:     L,
+		Writer:    os.Stdout,
+		Format:    FormatBits,
+		QuietZone: 1,
+	}
+	// Draw a margin to the left of the code
+	config.Use(func(next RenderFunc) RenderFunc {
+		return func(w io.Writer, bm *Bitmap) error {
+			return next(&prefixer{w: w, prefix: "| "}, bm)
+		}
+	})
+	GenerateWithConfig("1", config)
+	// Output:
+	// | 00000000000000000000000
+	// | 01111111001011011111110
+	// | 01000001001110010000010
+	// | 01011101011011010111010
+	// | 01011101001010010111010
+	// | 01011101000101010111010
+	// | 01000001000001010000010
+	// | 01111111010101011111110
+	// | 00000000011011000000000
+	// | 01110111111110110001000
+	// | 00001010010100010001100
+	// | 01101001111001000100010
+	// | 00011010101000010001100
+	// | 00010111100001010101110
+	// | 00000000010110101010100
+	// | 01111111010010111011110
+	// | 01000001010011101110100
+	// | 01011101010010111011010
+	// | 01011101001000010001100
+	// | 01011101010001000100010
+	// | 01000001011000010001000
+	// | 01111111010101010101010
+	// | 00000000000000000000000
+}
@@ -0,0 +1,109 @@
+package qrterminal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Output versions pin the defaults a code is drawn with, so golden tests
+// and reproducible builds do not break when the defaults improve. Options
+// set explicitly on the Config, including its Theme and Profile, always
+// apply on top of the pinned defaults.
+const (
+	// OutputLatest always uses the current defaults
+	OutputLatest = 0
+	// OutputV1 is the output of release 3.2:
+	//   - QuietZone values below 1 are raised to 1
+	//   - BlackChar, WhiteChar, BlackWhiteChar and WhiteBlackChar default to
+	//     BLACK_BLACK, WHITE_WHITE, BLACK_WHITE and WHITE_BLACK
+	//   - text lines end with "\n"
+	OutputV1 = 1
+	// OutputV2 is OutputV1, except that FormatHalfBlocks glyphs are never
+	// mixed: when BlackChar or WhiteChar is set, missing BlackWhiteChar and
+	// WhiteBlackChar are derived from them, or ErrHalfBlockGlyphs is
+	// returned if they can not be
+	OutputV2 = 2
+	// OutputV3 is OutputV2, except that FormatSixel is drawn by the sixel
+	// package: the quiet zone is the same on every side and the image
+	// declares its size
+	OutputV3 = 3
+	// OutputV4 is OutputV3, except that Hyperlink writes its caption,
+	// earlier versions ignore it
+	OutputV4 = 4
+)
+
+// ErrOutputVersion is returned for an OutputVersion that is not one of the
+// OutputV constants or OutputLatest
+var ErrOutputVersion = errors.New("qrterminal: unknown output version")
+
+// outputSpec is the render table of one output version
+type outputSpec struct {
+	minQuietZone int
+	glyphs       Glyphs
+	// deriveHalfBlocks completes partial half block glyph sets
+	deriveHalfBlocks bool
+	// sixelImage draws FormatSixel with the sixel package
+	sixelImage bool
+	// hyperlink writes the caption of Config.Hyperlink
+	hyperlink bool
+}
+
+var outputSpecs = map[int]outputSpec{
+	OutputV1: {
+		minQuietZone: 1,
+		glyphs: Glyphs{
+			Black:      BLACK_BLACK,
+			White:      WHITE_WHITE,
+			BlackWhite: BLACK_WHITE,
+			WhiteBlack: WHITE_BLACK,
+		},
+	},
+	OutputV2: {
+		minQuietZone: 1,
+		glyphs: Glyphs{
+			Black:      BLACK_BLACK,
+			White:      WHITE_WHITE,
+			BlackWhite: BLACK_WHITE,
+			WhiteBlack: WHITE_BLACK,
+		},
+		deriveHalfBlocks: true,
+	},
+	OutputV3: {
+		minQuietZone: 1,
+		glyphs: Glyphs{
+			Black:      BLACK_BLACK,
+			White:      WHITE_WHITE,
+			BlackWhite: BLACK_WHITE,
+			WhiteBlack: WHITE_BLACK,
+		},
+		deriveHalfBlocks: true,
+		sixelImage:       true,
+	},
+	OutputV4: {
+		minQuietZone: 1,
+		glyphs: Glyphs{
+			Black:      BLACK_BLACK,
+			White:      WHITE_WHITE,
+			BlackWhite: BLACK_WHITE,
+			WhiteBlack: WHITE_BLACK,
+		},
+		deriveHalfBlocks: true,
+		sixelImage:       true,
+		hyperlink:        true,
+	},
+}
+
+// latestOutput is the version used for OutputLatest
+const latestOutput = OutputV4
+
+// outputSpecFor returns the render table for version v
+func outputSpecFor(v int) (outputSpec, error) {
+	if v == OutputLatest {
+		v = latestOutput
+	}
+	spec, ok := outputSpecs[v]
+	if !ok {
+		return outputSpec{}, fmt.Errorf("%w %d", ErrOutputVersion, v)
+	}
+	return spec, nil
+}
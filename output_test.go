@@ -0,0 +1,88 @@
+package qrterminal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Hashes of output that must never change for OutputV1
+func TestOutputV1Golden(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"Blocks", Config{Level: M, QuietZone: QUIET_ZONE, OutputVersion: OutputV1}, "15ae7342fa274ed6b712b30eb991c2ef021d6ae15276673bd195b47fcb7eb6c8"},
+		{"HalfBlocks", Config{Level: M, HalfBlocks: true, OutputVersion: OutputV1}, "f566bafaf1e5031fdfeb3ab32652d535e0b15d69e07121d836ec0cd233c66694"},
+		{"ANSIBlocks", Config{Level: L, BlackChar: BLACK, WhiteChar: WHITE, QuietZone: 3, OutputVersion: OutputV1}, "f361d15093e29396e1eec942aabd7063a6f404703ec9dfc14bd9b2949479e564"},
+		{"Sixel", Config{Level: M, Format: FormatSixel, QuietZone: 2, OutputVersion: OutputV1}, "e7fb350d55f406ff2e7937b0ce26a8988425b2978d35c97770849a1594fb7a7a"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.config.Writer = &buf
+			GenerateWithConfig("https://github.com/mdp/qrterminal", tc.config)
+			sum := sha256.Sum256(buf.Bytes())
+			if got := hex.EncodeToString(sum[:]); got != tc.want {
+				t.Errorf("OutputV1 changed, got hash %s", got)
+			}
+		})
+	}
+}
+
+func TestOutputLatest(t *testing.T) {
+	var latest, pinned bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &latest})
+	GenerateWithConfig("test", Config{Level: L, Writer: &pinned, OutputVersion: latestOutput})
+	if latest.String() != pinned.String() {
+		t.Errorf("OutputLatest should match the latest pinned version")
+	}
+}
+
+func TestOutputVersionUnknown(t *testing.T) {
+	for _, v := range []int{-1, latestOutput + 1} {
+		var buf bytes.Buffer
+		err := GenerateFromReader(strings.NewReader("test"), 0, Config{Level: L, Writer: &buf, OutputVersion: v})
+		if !errors.Is(err, ErrOutputVersion) || buf.Len() != 0 {
+			t.Errorf("Version %d: expected ErrOutputVersion and no output, got %v, %d bytes", v, err, buf.Len())
+		}
+	}
+}
+
+func TestOutputV4(t *testing.T) {
+	// Hyperlink did not exist before OutputV4
+	var v3, plain, v4 bytes.Buffer
+	GenerateWithConfig("https://example.com", Config{Level: L, Writer: &v3, OutputVersion: OutputV3, Hyperlink: true})
+	GenerateWithConfig("https://example.com", Config{Level: L, Writer: &plain, OutputVersion: OutputV3})
+	GenerateWithConfig("https://example.com", Config{Level: L, Writer: &v4, OutputVersion: OutputV4, Hyperlink: true})
+	if v3.String() != plain.String() {
+		t.Error("Expected OutputV3 to ignore Hyperlink")
+	}
+	if !strings.Contains(v4.String(), "\033]8;;https://example.com") {
+		t.Error("Expected OutputV4 to write the hyperlink caption")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config Config
+		err    error
+	}{
+		{Config{}, nil},
+		{Config{OutputVersion: latestOutput + 1}, ErrOutputVersion},
+		{Config{HalfBlocks: true, BlackChar: "X", WhiteChar: "."}, ErrHalfBlockGlyphs},
+		{Config{HalfBlocks: true, BlackChar: "X", WhiteChar: ".", OutputVersion: OutputV1}, nil},
+	} {
+		if err := tc.config.Validate(); !errors.Is(err, tc.err) {
+			t.Errorf("Validate(%+v) = %v, expected %v", tc.config, err, tc.err)
+		}
+	}
+	if err := (Config{Symbology: "nope"}).Validate(); err == nil {
+		t.Error("Expected an unknown symbology to be refused")
+	}
+}
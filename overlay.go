@@ -0,0 +1,93 @@
+package qrterminal
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// overlayColor are the SGR background colors of each role for the debug
+// overlay, black modules in the first and white ones in the second. The
+// quiet zone is drawn as unknown.
+var overlayColor = [...][2]int{
+	RoleUnknown:   {47, 47},
+	RoleFinder:    {41, 101},
+	RoleTiming:    {42, 102},
+	RoleAlignment: {44, 104},
+	RoleFormat:    {45, 105},
+	RoleVersion:   {46, 106},
+	RoleData:      {40, 47},
+	RoleECC:       {43, 103},
+}
+
+// overlayLegend are the roles in the order the legend lists them
+var overlayLegend = []ModuleRole{RoleFinder, RoleTiming, RoleAlignment, RoleFormat, RoleVersion, RoleData, RoleECC}
+
+// drawOverlay writes bm with every module colored by its role, two rows of
+// modules per line if half is set, followed by a legend. It reports false
+// and writes nothing if the roles of bm are not known, i.e. for other
+// symbologies and bitmaps substituted by middleware.
+func (c *Config) drawOverlay(w io.Writer, bm *Bitmap, half bool) bool {
+	if bm.Role(0, 0) == RoleUnknown {
+		return false
+	}
+	side := bm.Size + 2*c.QuietZone
+	color := func(x, y int) int {
+		x, y = x-c.QuietZone, y-c.QuietZone
+		if bm.Black(x, y) {
+			return overlayColor[bm.Role(x, y)][0]
+		}
+		return overlayColor[bm.Role(x, y)][1]
+	}
+
+	var out strings.Builder
+	if half {
+		for y := 0; y < side; y += 2 {
+			last := ""
+			for x := 0; x < side; x++ {
+				bottom := overlayColor[RoleUnknown][1]
+				if y+1 < side {
+					bottom = color(x, y+1)
+				}
+				// The top module is drawn in the foreground color of the
+				// upper half block, which is 10 below its background
+				if seq := sgr(color(x, y)-10, bottom); seq != last {
+					out.WriteString(seq)
+					last = seq
+				}
+				out.WriteString("▀")
+			}
+			out.WriteString(sgrReset + "\n")
+		}
+	} else {
+		for y := 0; y < side; y++ {
+			last := ""
+			for x := 0; x < side; x++ {
+				if seq := sgr(color(x, y)); seq != last {
+					out.WriteString(seq)
+					last = seq
+				}
+				out.WriteString("  ")
+			}
+			out.WriteString(sgrReset + "\n")
+		}
+	}
+	for i, role := range overlayLegend {
+		if i > 0 {
+			out.WriteString(" ")
+		}
+		out.WriteString(sgr(overlayColor[role][0]) + "  " + sgr(overlayColor[role][1]) + "  " + sgrReset + " " + role.String())
+	}
+	out.WriteString("\n")
+	io.WriteString(w, out.String())
+	return true
+}
+
+// sgr returns the escape sequence selecting the SGR parameters params
+func sgr(params ...int) string {
+	s := make([]string, len(params))
+	for i, p := range params {
+		s[i] = strconv.Itoa(p)
+	}
+	return "\033[" + strings.Join(s, ";") + "m"
+}
@@ -0,0 +1,42 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDebugOverlay(t *testing.T) {
+	for _, format := range []Format{FormatBlocks, FormatHalfBlocks} {
+		var buf bytes.Buffer
+		GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 1, Format: format, DebugOverlay: true})
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+		// 23 rows of modules and the legend
+		want := 23 + 1
+		if format == FormatHalfBlocks {
+			want = 12 + 1
+		}
+		if len(lines) != want {
+			t.Fatalf("%s: expected %d lines, got %d", format, want, len(lines))
+		}
+		// The top left finder module is black, drawn red
+		if format == FormatBlocks && !strings.HasPrefix(lines[1], "\033[47m  \033[41m  ") {
+			t.Errorf("%s: finder pattern not colored: %q", format, lines[1])
+		}
+		if !strings.Contains(lines[len(lines)-1], "finder") {
+			t.Errorf("%s: missing legend: %q", format, lines[len(lines)-1])
+		}
+	}
+
+	// Other symbologies are drawn as usual
+	RegisterSymbology("overlay-test", EncoderFunc(func(data []byte, level Level) (*Bitmap, error) {
+		return NewBitmap(5), nil
+	}))
+	var plain, overlay bytes.Buffer
+	GenerateWithConfig("test", Config{Writer: &plain, Symbology: "overlay-test"})
+	GenerateWithConfig("test", Config{Writer: &overlay, Symbology: "overlay-test", DebugOverlay: true})
+	if plain.String() != overlay.String() {
+		t.Errorf("Expected the overlay to leave other symbologies alone, got %q", overlay.String())
+	}
+}
@@ -0,0 +1,246 @@
+// Package pairing pairs a terminal with a scanning device over an
+// untrusted channel, with the code on screen and a short code typed back
+// as the only trusted paths between them.
+//
+// The Initiator shows a code holding an ephemeral X25519 public key and a
+// one-time secret. The device scans it and calls Respond, which returns
+// its own public key, to be sent to the Initiator over the untrusted
+// channel, and a short confirmation code to show the operator. Both sides
+// derive the same session key from the key exchange and the secret. The
+// operator types the confirmation code into the terminal, and the
+// Initiator only hands out the key if it matches, which proves that the
+// public key it received comes from the device that scanned the code.
+package pairing
+
+import (
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3/payload"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Prefix starts the payload of every pairing code, followed by the
+// protocol version
+const Prefix = "QRPAIR:1:"
+
+// secretSize is the size of the one-time secret in the pairing code
+const secretSize = 16
+
+// KeySize is the size of the session key
+const KeySize = 32
+
+// codeDigits is the number of digits of a confirmation code
+const codeDigits = 8
+
+// MaxAttempts is the number of wrong confirmation codes after which an
+// Initiator fails for good
+const MaxAttempts = 3
+
+// encoding keeps payloads to the alphanumeric mode of QR codes
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var (
+	// ErrPayload is returned by Respond for data that is not a pairing code
+	ErrPayload = errors.New("pairing: not a pairing code")
+	// ErrState is returned when a step is taken out of order
+	ErrState = errors.New("pairing: step out of order")
+	// ErrCode is returned by Confirm for a wrong confirmation code
+	ErrCode = errors.New("pairing: wrong confirmation code")
+	// ErrFailed is returned once MaxAttempts wrong codes were entered
+	ErrFailed = errors.New("pairing: too many wrong confirmation codes")
+)
+
+// State is the step an Initiator is at
+type State int
+
+// States of an Initiator, in order
+const (
+	// StateShowing waits for the public key of the device
+	StateShowing State = iota
+	// StateConfirming waits for the confirmation code
+	StateConfirming
+	// StatePaired has confirmed the device, the session key is known
+	StatePaired
+	// StateFailed was given too many wrong codes and can not be used
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateShowing:
+		return "showing"
+	case StateConfirming:
+		return "confirming"
+	case StatePaired:
+		return "paired"
+	case StateFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Initiator is the terminal side of a pairing
+type Initiator struct {
+	priv     *ecdh.PrivateKey
+	secret   []byte
+	state    State
+	attempts int
+	code     string
+	key      payload.Secret
+}
+
+// NewInitiator starts a pairing with a key and secret read from the
+// randomness of opts
+func NewInitiator(opts *payload.Options) (*Initiator, error) {
+	priv, err := generateKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, secretSize)
+	if _, err := io.ReadFull(opts.Random(), secret); err != nil {
+		return nil, err
+	}
+	return &Initiator{priv: priv, secret: secret}, nil
+}
+
+// generateKey returns an X25519 key read from the randomness of opts.
+// ecdh's GenerateKey may ignore the reader it is given, depending on the
+// Go version and GODEBUG settings.
+func generateKey(opts *payload.Options) (*ecdh.PrivateKey, error) {
+	seed := make([]byte, 32)
+	if _, err := io.ReadFull(opts.Random(), seed); err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPrivateKey(seed)
+}
+
+// Payload returns the data of the code to show the device
+func (i *Initiator) Payload() string {
+	return Prefix + encoding.EncodeToString(append(i.priv.PublicKey().Bytes(), i.secret...))
+}
+
+// State returns the step i is at
+func (i *Initiator) State() State {
+	return i.state
+}
+
+// Receive takes the public key the device sent back
+func (i *Initiator) Receive(peer []byte) error {
+	if i.state != StateShowing {
+		return ErrState
+	}
+	pub, err := ecdh.X25519().NewPublicKey(peer)
+	if err != nil {
+		return fmt.Errorf("pairing: bad public key: %w", err)
+	}
+	code, key, err := derive(i.priv, pub, i.priv.PublicKey(), pub, i.secret)
+	if err != nil {
+		return err
+	}
+	i.code, i.key = code, key
+	i.state = StateConfirming
+	return nil
+}
+
+// Confirm checks the confirmation code the operator typed, ignoring
+// spaces and dashes, and returns the session key if it is right. After
+// MaxAttempts wrong codes i fails with ErrFailed and forgets the key.
+func (i *Initiator) Confirm(code string) (payload.Secret, error) {
+	if i.state == StateFailed {
+		return nil, ErrFailed
+	}
+	if i.state != StateConfirming {
+		return nil, ErrState
+	}
+	if !hmac.Equal([]byte(normalizeCode(code)), []byte(i.code)) {
+		i.attempts++
+		if i.attempts >= MaxAttempts {
+			i.state = StateFailed
+			i.key.Zero()
+			i.key = nil
+			return nil, ErrFailed
+		}
+		return nil, ErrCode
+	}
+	i.state = StatePaired
+	return i.key, nil
+}
+
+// Response is the device side of a pairing
+type Response struct {
+	// PublicKey goes back to the Initiator over the untrusted channel
+	PublicKey []byte
+	// Code is shown to the operator, who types it into the terminal
+	Code string
+	// Key is the session key
+	Key payload.Secret
+}
+
+// Respond answers the pairing code that a device scanned, with a key read
+// from the randomness of opts
+func Respond(scanned string, opts *payload.Options) (*Response, error) {
+	if !strings.HasPrefix(scanned, Prefix) {
+		return nil, ErrPayload
+	}
+	raw, err := encoding.DecodeString(scanned[len(Prefix):])
+	if err != nil || len(raw) != 32+secretSize {
+		return nil, ErrPayload
+	}
+	peer, err := ecdh.X25519().NewPublicKey(raw[:32])
+	if err != nil {
+		return nil, ErrPayload
+	}
+	priv, err := generateKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	code, key, err := derive(priv, peer, peer, priv.PublicKey(), raw[32:])
+	if err != nil {
+		return nil, err
+	}
+	return &Response{PublicKey: priv.PublicKey().Bytes(), Code: FormatCode(code), Key: key}, nil
+}
+
+// derive returns the confirmation code and the session key of the
+// exchange between the Initiator key initiator and the device key device
+func derive(priv *ecdh.PrivateKey, peer, initiator, device *ecdh.PublicKey, secret []byte) (string, payload.Secret, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return "", nil, err
+	}
+	info := append([]byte("qrterminal pairing v1"), initiator.Bytes()...)
+	info = append(info, device.Bytes()...)
+	out := make([]byte, KeySize+8)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, secret, info), out); err != nil {
+		return "", nil, err
+	}
+	n := binary.BigEndian.Uint64(out[KeySize:]) % 100000000
+	return fmt.Sprintf("%0*d", codeDigits, n), out[:KeySize], nil
+}
+
+// FormatCode writes a confirmation code in two groups of four digits
+func FormatCode(code string) string {
+	code = normalizeCode(code)
+	if len(code) != codeDigits {
+		return code
+	}
+	return code[:4] + "-" + code[4:]
+}
+
+// normalizeCode drops the spaces and dashes of a typed code
+func normalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, code)
+}
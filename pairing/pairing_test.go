@@ -0,0 +1,165 @@
+package pairing
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+func TestPairing(t *testing.T) {
+	i, err := NewInitiator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Respond(i.Payload(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Code) != 9 || r.Code[4] != '-' {
+		t.Errorf("Unexpected confirmation code %q", r.Code)
+	}
+	if _, err := i.Confirm(r.Code); err != ErrState {
+		t.Errorf("Expected ErrState before the key is received, got %v", err)
+	}
+	if err := i.Receive(r.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	key, err := i.Confirm(strings.ReplaceAll(r.Code, "-", " "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, r.Key) || len(key) != KeySize {
+		t.Errorf("Keys differ: %x and %x", key, r.Key)
+	}
+	if i.State() != StatePaired {
+		t.Errorf("Expected %s, got %s", StatePaired, i.State())
+	}
+}
+
+func TestPairingSubstitutedKey(t *testing.T) {
+	i, _ := NewInitiator(nil)
+	r, _ := Respond(i.Payload(), nil)
+
+	// Someone on the channel answers with a key of their own, without
+	// having seen the code
+	other, _ := NewInitiator(nil)
+	mallory, _ := Respond(other.Payload(), nil)
+	i.Receive(mallory.PublicKey)
+
+	for n := 1; n <= MaxAttempts; n++ {
+		key, err := i.Confirm(r.Code)
+		if key != nil {
+			t.Fatal("Confirmed a substituted key")
+		}
+		want := ErrCode
+		if n == MaxAttempts {
+			want = ErrFailed
+		}
+		if err != want {
+			t.Errorf("Attempt %d: expected %v, got %v", n, want, err)
+		}
+	}
+	if i.State() != StateFailed {
+		t.Errorf("Expected %s, got %s", StateFailed, i.State())
+	}
+	if _, err := i.Confirm(r.Code); err != ErrFailed {
+		t.Errorf("Expected ErrFailed for good, got %v", err)
+	}
+}
+
+func TestRespondErrors(t *testing.T) {
+	for _, payload := range []string{"", "hello", Prefix, Prefix + "AAAA", Prefix + strings.Repeat("A", 77) + "!"} {
+		if _, err := Respond(payload, nil); err != ErrPayload {
+			t.Errorf("%q: expected ErrPayload, got %v", payload, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	i, _ := NewInitiator(nil)
+	var r *Response
+	var out bytes.Buffer
+	receive := func() ([]byte, error) {
+		var err error
+		r, err = Respond(i.Payload(), nil)
+		return r.PublicKey, err
+	}
+	in := &lazyReader{fn: func() string { return "0000-0000\n" + r.Code + "\n" }}
+	key, err := Run(i, qrterminal.Config{Writer: &out, Level: qrterminal.L}, in, receive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, r.Key) {
+		t.Error("Keys differ")
+	}
+	for _, want := range []string{"Scan the code", "Wrong code, 2 attempts left", "Paired"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Missing %q in the output", want)
+		}
+	}
+
+	// The operator gives up
+	i, _ = NewInitiator(nil)
+	_, err = Run(i, qrterminal.Config{Level: qrterminal.L}, strings.NewReader(""), receive)
+	if err == nil {
+		t.Error("Expected an error at the end of the input")
+	}
+
+	// The channel fails
+	i, _ = NewInitiator(nil)
+	broken := errors.New("broken")
+	if _, err := Run(i, qrterminal.Config{Level: qrterminal.L}, strings.NewReader(""), func() ([]byte, error) { return nil, broken }); err != broken {
+		t.Errorf("Expected the channel error, got %v", err)
+	}
+}
+
+// lazyReader reads the text fn returns at its first read, once the
+// response it depends on is known
+type lazyReader struct {
+	fn func() string
+	r  *strings.Reader
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.r == nil {
+		l.r = strings.NewReader(l.fn())
+	}
+	return l.r.Read(p)
+}
+
+// countingReader is a deterministic source of randomness
+type countingReader byte
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(*c)
+		*c++
+	}
+	return len(p), nil
+}
+
+func TestPairingRandomness(t *testing.T) {
+	var r1, r2 countingReader
+	i1, _ := NewInitiator(&payload.Options{Rand: &r1})
+	i2, _ := NewInitiator(&payload.Options{Rand: &r2})
+	if i1.Payload() != i2.Payload() {
+		t.Error("Expected the same randomness to give the same code")
+	}
+	a, _ := Respond(i1.Payload(), &payload.Options{Rand: &r1})
+	b, _ := Respond(i2.Payload(), &payload.Options{Rand: &r2})
+	if a.Code != b.Code || !bytes.Equal(a.Key, b.Key) {
+		t.Error("Expected the same randomness to give the same response")
+	}
+
+	empty := &payload.Options{Rand: strings.NewReader("")}
+	if _, err := NewInitiator(empty); err == nil {
+		t.Error("Expected NewInitiator to fail without randomness")
+	}
+	if _, err := Respond(i1.Payload(), empty); err == nil {
+		t.Error("Expected Respond to fail without randomness")
+	}
+}
@@ -0,0 +1,58 @@
+package pairing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// Run takes the operator through the terminal side of a pairing: it draws
+// the code of i with config and waits for receive to return the public key
+// of the device, from whatever channel the application pairs over. It then
+// asks for the confirmation code the device shows on in, until it is right
+// or MaxAttempts were wrong, and returns the session key. Prompts go to
+// the Writer of config.
+func Run(i *Initiator, config qrterminal.Config, in io.Reader, receive func() ([]byte, error)) (payload.Secret, error) {
+	if config.Writer == nil {
+		config.Writer = io.Discard
+	}
+	w := config.Writer
+	if err := qrterminal.GenerateFromReader(strings.NewReader(i.Payload()), 0, config); err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(w, "Scan the code with the device to pair it")
+
+	peer, err := receive()
+	if err != nil {
+		return nil, err
+	}
+	if err := i.Receive(peer); err != nil {
+		return nil, err
+	}
+
+	lines := bufio.NewReader(in)
+	for {
+		fmt.Fprint(w, "Type the code the device shows: ")
+		line, err := lines.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			fmt.Fprintln(w)
+			return nil, err
+		}
+		key, err := i.Confirm(strings.TrimSpace(line))
+		if errors.Is(err, ErrCode) {
+			fmt.Fprintf(w, "Wrong code, %d attempts left\n", MaxAttempts-i.attempts)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(w, "Pairing failed, the device could not be confirmed")
+			return nil, err
+		}
+		fmt.Fprintln(w, "Paired")
+		return key, nil
+	}
+}
@@ -0,0 +1,188 @@
+package payload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// bech32Charset maps the 5 bit values of bech32 data to characters
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of bech32 (BIP 173) and bech32m (BIP 350)
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if top>>i&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// decodeBech32 returns the human readable part of s in lower case, its 5
+// bit data without the checksum and the checksum constant, bech32Const or
+// bech32mConst. s may be in upper or lower case, not both.
+func decodeBech32(s string) (string, []byte, uint32, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("mixes upper and lower case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, 0, errors.New("is not bech32")
+	}
+	hrp := s[:sep]
+	values := make([]byte, 0, 2*len(hrp)+1+len(s)-sep-1)
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errors.New("is not bech32")
+		}
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	var data []byte
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, 0, fmt.Errorf("has the character %q, which bech32 does not use", s[i])
+		}
+		data = append(data, byte(v))
+	}
+	constant := bech32Polymod(append(values, data...))
+	if constant != bech32Const && constant != bech32mConst {
+		return "", nil, 0, errors.New("checksum does not match, it has a typo")
+	}
+	return hrp, data[:len(data)-6], constant, nil
+}
+
+// convertBits regroups data of from bit values into to bit values, without
+// padding, as segwit addresses need
+func convertBits(data []byte, from, to uint) ([]byte, bool) {
+	var acc uint32
+	var bits uint
+	var out []byte
+	for _, v := range data {
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&(1<<to-1)))
+		}
+	}
+	return out, bits < from && acc&(1<<bits-1) == 0
+}
+
+// checkSegwit checks a bech32 Bitcoin address, BIP 173 and 350
+func checkSegwit(addr string) error {
+	if len(addr) > 90 {
+		return errors.New("payload: Bitcoin address is longer than 90 characters")
+	}
+	hrp, data, constant, err := decodeBech32(addr)
+	if err != nil {
+		return fmt.Errorf("payload: Bitcoin address %v", err)
+	}
+	if hrp != "bc" && hrp != "tb" && hrp != "bcrt" {
+		return fmt.Errorf("payload: %q is not the prefix of a Bitcoin network", hrp)
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return errors.New("payload: Bitcoin address has no valid witness version")
+	}
+	program, ok := convertBits(data[1:], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 || data[0] == 0 && len(program) != 20 && len(program) != 32 {
+		return errors.New("payload: Bitcoin address has a witness program of the wrong size")
+	}
+	if data[0] == 0 && constant != bech32Const || data[0] != 0 && constant != bech32mConst {
+		return errors.New("payload: Bitcoin address uses the wrong checksum for its witness version")
+	}
+	return nil
+}
+
+// base58Alphabet are the digits of base58 as Bitcoin uses it
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// checkBase58 checks a legacy Bitcoin address, a version byte, a hash and
+// a checksum in base58
+func checkBase58(addr string) error {
+	n := new(big.Int)
+	for i := 0; i < len(addr); i++ {
+		v := strings.IndexByte(base58Alphabet, addr[i])
+		if v < 0 {
+			return fmt.Errorf("payload: Bitcoin address has the character %q, which base58 does not use", addr[i])
+		}
+		n.Mul(n, big.NewInt(58)).Add(n, big.NewInt(int64(v)))
+	}
+	b := n.Bytes()
+	for i := 0; i < len(addr) && addr[i] == '1'; i++ {
+		b = append([]byte{0}, b...)
+	}
+	if len(b) != 25 {
+		return errors.New("payload: Bitcoin address has the wrong length")
+	}
+	first := sha256.Sum256(b[:21])
+	sum := sha256.Sum256(first[:])
+	if !bytes.Equal(sum[:4], b[21:]) {
+		return errors.New("payload: Bitcoin address checksum does not match, it has a typo")
+	}
+	switch b[0] {
+	case 0x00, 0x05, 0x6f, 0xc4:
+		return nil
+	}
+	return errors.New("payload: not a Bitcoin address, its version is unknown")
+}
+
+// checkBitcoinAddress checks the checksum and format of a Bitcoin address
+// of the main, test or regression test network
+func checkBitcoinAddress(addr string) error {
+	if addr == "" {
+		return errors.New("payload: no Bitcoin address")
+	}
+	for _, prefix := range []string{"bc1", "tb1", "bcrt1"} {
+		if hasPrefixFold(addr, prefix) {
+			return checkSegwit(addr)
+		}
+	}
+	return checkBase58(addr)
+}
+
+// checkEthereumAddress checks that addr is 0x and 40 hex digits, with the
+// EIP-55 checksum if it mixes upper and lower case
+func checkEthereumAddress(what, addr string) error {
+	digits := strings.TrimPrefix(addr, "0x")
+	if len(addr) != 42 || len(digits) != 40 || !isHexDigits(digits) {
+		return fmt.Errorf("payload: Ethereum %s is not 0x and 40 hex digits", what)
+	}
+	lower := strings.ToLower(digits)
+	if digits == lower || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+	for i := 0; i < len(digits); i++ {
+		upper := hash[i] >= '8'
+		if c := digits[i]; c >= 'a' && upper || c >= 'A' && c <= 'F' && !upper {
+			return fmt.Errorf("payload: Ethereum %s checksum does not match, it has a typo", what)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package payload
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// encodeBech32 is the inverse of decodeBech32, for tests
+func encodeBech32(hrp string, data []byte, constant uint32) string {
+	values := []byte{}
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(append(values, data...), 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ constant
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, v := range data {
+		b.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[mod>>(5*(5-i))&31])
+	}
+	return b.String()
+}
+
+// encodeBase58Check writes a version byte and a hash as a legacy address
+func encodeBase58Check(version byte, hash []byte) string {
+	b := append([]byte{version}, hash...)
+	first := sha256.Sum256(b)
+	sum := sha256.Sum256(first[:])
+	b = append(b, sum[:4]...)
+	n := new(big.Int).SetBytes(b)
+	var out []byte
+	for n.Sign() > 0 {
+		m := new(big.Int)
+		n.DivMod(n, big.NewInt(58), m)
+		out = append([]byte{base58Alphabet[m.Int64()]}, out...)
+	}
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		out = append([]byte{'1'}, out...)
+	}
+	return string(out)
+}
+
+func TestCheckBitcoinAddress(t *testing.T) {
+	valid := []string{
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+		"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		"BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+		encodeBase58Check(0x6f, make([]byte, 20)),
+	}
+	for _, addr := range valid {
+		if err := checkBitcoinAddress(addr); err != nil {
+			t.Errorf("%s: %v", addr, err)
+		}
+	}
+	program := toBase32(make([]byte, 20))
+	invalid := map[string]string{
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb":                                "typo",
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfN0":                                "base58",
+		"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdr":                        "typo",
+		"bc1qar0srrr7xfkvy5l643lydnw9RE59gtzzwf5mdq":                        "case",
+		"ltc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq":                       "base58",
+		encodeBech32("bc", append([]byte{0}, program...), bech32mConst):     "wrong checksum",
+		encodeBech32("bc", append([]byte{1}, program...), bech32Const):      "wrong checksum",
+		encodeBech32("bc", append([]byte{0}, program[:20]...), bech32Const): "size",
+		encodeBase58Check(0x30, make([]byte, 20)):                           "version",
+	}
+	for addr, want := range invalid {
+		if err := checkBitcoinAddress(addr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error about %s, got %v", addr, want, err)
+		}
+	}
+}
+
+func TestCheckEthereumAddress(t *testing.T) {
+	for _, addr := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+	} {
+		if err := checkEthereumAddress("address", addr); err != nil {
+			t.Errorf("%s: %v", addr, err)
+		}
+	}
+	for addr, want := range map[string]string{
+		"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed": "typo",
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed":   "40 hex digits",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe":  "40 hex digits",
+		"vitalik.eth": "40 hex digits",
+	} {
+		if err := checkEthereumAddress("address", addr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error about %s, got %v", addr, want, err)
+		}
+	}
+}
+
+// toBase32 regroups bytes into 5 bit values, padding the last one with
+// zero bits as encoders do
+func toBase32(data []byte) []byte {
+	var out []byte
+	var acc uint32
+	var bits uint
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		for bits += 8; bits >= 5; {
+			bits -= 5
+			out = append(out, byte(acc>>bits&31))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(5-bits)&31))
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package payload
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+)
+
+// ErrNotAllowed is returned by Allowlist.Check for a payload it does not
+// allow
+var ErrNotAllowed = errors.New("payload: not in the allowlist")
+
+// Allowlist restricts the payloads an exposed endpoint renders, so it can
+// not be used to put arbitrary content on an operator's screen or a
+// printed label. A payload is allowed if it starts with one of Prefixes or
+// matches Pattern. An Allowlist without either allows nothing, a nil
+// *Allowlist allows everything.
+type Allowlist struct {
+	// Prefixes are allowed payload prefixes, usually URLs. End a URL
+	// prefix with a slash, or "https://example.com" also allows
+	// "https://example.com.evil.test/".
+	Prefixes []string
+	// Pattern is matched as with MatchString, anchor it with ^ and $ to
+	// match the whole payload
+	Pattern *regexp.Regexp
+}
+
+// Allowed reports whether a allows data
+func (a *Allowlist) Allowed(data []byte) bool {
+	if a == nil {
+		return true
+	}
+	for _, p := range a.Prefixes {
+		if bytes.HasPrefix(data, []byte(p)) {
+			return true
+		}
+	}
+	return a.Pattern != nil && a.Pattern.Match(data)
+}
+
+// Check returns ErrNotAllowed if a does not allow data
+func (a *Allowlist) Check(data []byte) error {
+	if !a.Allowed(data) {
+		return ErrNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package payload
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAllowlist(t *testing.T) {
+	a := &Allowlist{
+		Prefixes: []string{"https://example.com/pair/", "WIFI:"},
+		Pattern:  regexp.MustCompile(`^[0-9]{6}$`),
+	}
+	testCases := []struct {
+		data string
+		want bool
+	}{
+		{"https://example.com/pair/abc", true},
+		{"WIFI:S:home;T:WPA;P:secret;;", true},
+		{"123456", true},
+		{"https://example.com/other", false},
+		{"https://example.com.evil.test/pair/", false},
+		{"1234567", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		if got := a.Allowed([]byte(tc.data)); got != tc.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tc.data, got, tc.want)
+		}
+		if err := a.Check([]byte(tc.data)); (err == nil) != tc.want {
+			t.Errorf("Check(%q) = %v", tc.data, err)
+		}
+	}
+
+	var none *Allowlist
+	if !none.Allowed([]byte("anything")) {
+		t.Errorf("A nil allowlist should allow everything")
+	}
+	if (&Allowlist{}).Allowed([]byte("anything")) {
+		t.Errorf("An empty allowlist should allow nothing")
+	}
+}
@@ -0,0 +1,135 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBTC is the number of bitcoin there will ever be
+const maxBTC = 21000000
+
+// Bitcoin is a payment request in the bitcoin: URI format of BIP 21
+type Bitcoin struct {
+	Address string `json:"address"`
+	// Amount is in BTC as a decimal number, e.g. 0.001, empty leaves it to
+	// the payer
+	Amount string `json:"amount,omitempty"`
+	// Label names the recipient
+	Label string `json:"label,omitempty"`
+	// Message describes the payment
+	Message string `json:"message,omitempty"`
+}
+
+// Type returns TypeBitcoin
+func (b *Bitcoin) Type() Type { return TypeBitcoin }
+
+// Payload checks b and returns its bitcoin: URI
+func (b *Bitcoin) Payload() (string, error) {
+	if err := b.check(); err != nil {
+		return "", err
+	}
+	var params []string
+	if b.Amount != "" {
+		params = append(params, "amount="+b.Amount)
+	}
+	if b.Label != "" {
+		params = append(params, "label="+escapeParam(b.Label))
+	}
+	if b.Message != "" {
+		params = append(params, "message="+escapeParam(b.Message))
+	}
+	s := "bitcoin:" + b.Address
+	if len(params) > 0 {
+		s += "?" + strings.Join(params, "&")
+	}
+	return s, nil
+}
+
+func (b *Bitcoin) check() error {
+	if err := checkBitcoinAddress(b.Address); err != nil {
+		return err
+	}
+	if b.Amount != "" {
+		if err := checkBTC(b.Amount); err != nil {
+			return err
+		}
+	}
+	if !utf8.ValidString(b.Label) || !utf8.ValidString(b.Message) {
+		return errors.New("payload: Bitcoin label and message must be UTF-8")
+	}
+	return nil
+}
+
+// checkBTC checks that amount is a decimal number of BTC with up to 8
+// decimals, the satoshis, and no more than there will ever be
+func checkBTC(amount string) error {
+	whole, frac, hasFrac := strings.Cut(amount, ".")
+	if whole == "" || hasFrac && frac == "" || len(frac) > 8 || !isDigits(whole) || !isDigits(frac) {
+		return fmt.Errorf("payload: Bitcoin amount %q is not a decimal number of BTC with up to 8 decimals", amount)
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > 8 || len(whole) == 8 && (whole > fmt.Sprint(maxBTC) || whole == fmt.Sprint(maxBTC) && strings.Trim(frac, "0") != "") {
+		return fmt.Errorf("payload: Bitcoin amount %s is more than the %d BTC there will ever be", amount, maxBTC)
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeParam percent-encodes a query parameter value, with spaces as %20
+// rather than +, which some wallets show as is
+func escapeParam(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// Fields returns the address, amount, label and message of b
+func (b *Bitcoin) Fields() []Field {
+	fields := []Field{{"Address", b.Address}}
+	if b.Amount != "" {
+		fields = append(fields, Field{"Amount", b.Amount + " BTC"})
+	} else {
+		fields = append(fields, Field{"Amount", "left to the payer"})
+	}
+	if b.Label != "" {
+		fields = append(fields, Field{"Label", b.Label})
+	}
+	if b.Message != "" {
+		fields = append(fields, Field{"Message", b.Message})
+	}
+	return fields
+}
+
+// ParseBitcoin parses and checks a bitcoin: URI. Parameters it does not
+// know are ignored, unless their name starts with req-, which BIP 21
+// reserves for parameters a wallet must understand.
+func ParseBitcoin(s string) (*Bitcoin, error) {
+	s = strings.TrimSpace(s)
+	if !hasPrefixFold(s, "bitcoin:") {
+		return nil, errors.New("payload: not a Bitcoin payment, expected bitcoin:")
+	}
+	address, query, _ := strings.Cut(s[len("bitcoin:"):], "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("payload: invalid Bitcoin parameters: %v", parseError(err))
+	}
+	for name := range params {
+		if strings.HasPrefix(name, "req-") {
+			return nil, fmt.Errorf("payload: Bitcoin payment requires the unknown parameter %q", name)
+		}
+	}
+	b := &Bitcoin{Address: address, Amount: params.Get("amount"), Label: params.Get("label"), Message: params.Get("message")}
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
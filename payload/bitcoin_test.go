@@ -0,0 +1,63 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBitcoin(t *testing.T) {
+	testCases := []struct {
+		name string
+		btc  Bitcoin
+		want string
+	}{
+		{"Address", Bitcoin{Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"}, "bitcoin:bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		{"Everything", Bitcoin{Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", Amount: "0.0015", Label: "Luke Jr", Message: "Donation & thanks"},
+			"bitcoin:1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa?amount=0.0015&label=Luke%20Jr&message=Donation%20%26%20thanks"},
+		{"AllCoins", Bitcoin{Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", Amount: "21000000.00000000"},
+			"bitcoin:1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa?amount=21000000.00000000"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.btc.Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseBitcoin(got)
+			if err != nil {
+				t.Fatalf("ParseBitcoin failed: %v", err)
+			}
+			if *parsed != tc.btc {
+				t.Errorf("Parsed %#v, expected %#v", *parsed, tc.btc)
+			}
+		})
+	}
+}
+
+func TestBitcoinInvalid(t *testing.T) {
+	address := "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	for amount, want := range map[string]string{
+		"1.5e3":       "decimal",
+		"-1":          "decimal",
+		".5":          "decimal",
+		"1.":          "decimal",
+		"0.123456789": "8 decimals",
+		"21000000.1":  "ever be",
+		"99999999":    "ever be",
+	} {
+		b := Bitcoin{Address: address, Amount: amount}
+		if _, err := b.Payload(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Amount %q: expected an error about %s, got %v", amount, want, err)
+		}
+	}
+	if _, err := ParseBitcoin("bitcoin:" + address + "?req-somethingyoudontunderstand=50"); err == nil {
+		t.Error("Expected an error for an unknown required parameter")
+	}
+	b, err := ParseBitcoin("BITCOIN:" + address + "?somethingyoudontunderstand=50&amount=1")
+	if err != nil || b.Amount != "1" {
+		t.Errorf("Parsed %#v, %v", b, err)
+	}
+}
@@ -0,0 +1,63 @@
+package payload
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DeepLinkParam is the query parameter of the fallback URL that carries the
+// app deep link
+const DeepLinkParam = "link"
+
+// DeepLink returns an HTTPS URL suitable for a QR code that carries the app
+// deep link appLink in the DeepLinkParam query parameter of fallback.
+// Scanning it on a device without the app opens the fallback page, which
+// can offer an install link or redirect to appLink when the app is present.
+//
+// appLink must use a custom scheme (not http or https, which are universal
+// links already) and fallback must be an absolute https URL.
+func DeepLink(appLink, fallback string) (string, error) {
+	app, err := url.Parse(appLink)
+	if err != nil {
+		return "", fmt.Errorf("payload: invalid deep link: %v", parseError(err))
+	}
+	if err := checkScheme(app.Scheme); err != nil {
+		return "", err
+	}
+	switch strings.ToLower(app.Scheme) {
+	case "http", "https":
+		return "", fmt.Errorf("payload: deep link scheme %q is a web scheme, encode the link directly", app.Scheme)
+	case "javascript", "data", "file":
+		return "", fmt.Errorf("payload: deep link scheme %q is not allowed", app.Scheme)
+	}
+
+	fb, err := url.Parse(fallback)
+	if err != nil {
+		return "", fmt.Errorf("payload: invalid fallback URL: %v", parseError(err))
+	}
+	if fb.Scheme != "https" || fb.Host == "" {
+		return "", fmt.Errorf("payload: fallback URL (%s) must be an absolute https URL", Redact([]byte(fallback)))
+	}
+	q := fb.Query()
+	q.Set(DeepLinkParam, app.String())
+	fb.RawQuery = q.Encode()
+	return fb.String(), nil
+}
+
+// checkScheme validates scheme against RFC 3986 section 3.1:
+// ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )
+func checkScheme(scheme string) error {
+	if scheme == "" {
+		return fmt.Errorf("payload: deep link has no scheme")
+	}
+	for i, r := range scheme {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return fmt.Errorf("payload: invalid deep link scheme %q", scheme)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package payload
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDeepLink(t *testing.T) {
+	app := "katzen://pair?key=abc+def&name=My Phone"
+	got, err := DeepLink(app, "https://katzenpost.network/pair?lang=en")
+	if err != nil {
+		t.Fatalf("DeepLink failed: %v", err)
+	}
+
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("Result is not a URL: %v", err)
+	}
+	if u.Scheme != "https" || u.Host != "katzenpost.network" || u.Path != "/pair" {
+		t.Errorf("Unexpected fallback URL %q", got)
+	}
+	if u.Query().Get("lang") != "en" {
+		t.Errorf("Existing query parameters should be kept, got %q", got)
+	}
+	link, err := url.Parse(u.Query().Get(DeepLinkParam))
+	if err != nil || link.Scheme != "katzen" || link.Query().Get("key") != "abc def" {
+		t.Errorf("Deep link did not survive the round trip, got %q", u.Query().Get(DeepLinkParam))
+	}
+}
+
+func TestDeepLinkInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		app      string
+		fallback string
+	}{
+		{"NoScheme", "pair?key=abc", "https://example.com/"},
+		{"BadScheme", "1app://pair", "https://example.com/"},
+		{"WebScheme", "https://example.com/app", "https://example.com/"},
+		{"Javascript", "javascript:alert(1)", "https://example.com/"},
+		{"InsecureFallback", "app://pair", "http://example.com/"},
+		{"RelativeFallback", "app://pair", "/pair"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := DeepLink(tc.app, tc.fallback); err == nil {
+				t.Errorf("Expected an error, got %q", got)
+			}
+		})
+	}
+}
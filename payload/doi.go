@@ -0,0 +1,111 @@
+package payload
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// doiResolver is the URL of the DOI resolver, a DOI appended to it opens
+// the page of the work
+const doiResolver = "https://doi.org/"
+
+// doiPrefixes are the forms of DOI links and URIs ParseDOI reads
+var doiPrefixes = []string{doiResolver, "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"}
+
+// DOI is a Digital Object Identifier, such as 10.1000/182, as a link to the
+// DOI resolver
+type DOI struct {
+	// DOI is the identifier without a doi: or URL prefix
+	DOI string `json:"doi"`
+}
+
+// Type returns TypeDOI
+func (d *DOI) Type() Type { return TypeDOI }
+
+// Payload checks d and returns its https://doi.org/ link, with the
+// characters a URL path can not hold percent-encoded
+func (d *DOI) Payload() (string, error) {
+	if err := checkDOI(d.DOI); err != nil {
+		return "", err
+	}
+	return doiResolver + (&url.URL{Path: d.DOI}).EscapedPath(), nil
+}
+
+// checkDOI checks that doi is 10., a registrant code of dot separated
+// numbers, a slash and a suffix
+func checkDOI(doi string) error {
+	prefix, suffix, ok := strings.Cut(doi, "/")
+	if !ok || !strings.HasPrefix(prefix, "10.") {
+		return errors.New("payload: DOI must start with 10., a registrant code and a slash, e.g. 10.1000/182")
+	}
+	for _, part := range strings.Split(prefix[len("10."):], ".") {
+		if part == "" || !isDigits(part) {
+			return errors.New("payload: DOI registrant code must be numbers separated by dots, e.g. 10.1000")
+		}
+	}
+	if suffix == "" {
+		return errors.New("payload: DOI has no suffix after the slash")
+	}
+	if !utf8.ValidString(suffix) {
+		return errors.New("payload: DOI must be UTF-8")
+	}
+	for _, r := range suffix {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return errors.New("payload: DOI has a space or control character")
+		}
+	}
+	return nil
+}
+
+// Fields returns the DOI, its registrant and link
+func (d *DOI) Fields() []Field {
+	fields := []Field{{"DOI", d.DOI}}
+	if prefix, _, ok := strings.Cut(d.DOI, "/"); ok {
+		fields = append(fields, Field{"Registrant", prefix})
+	}
+	if link, err := d.Payload(); err == nil {
+		fields = append(fields, Field{"URL", link})
+	}
+	return fields
+}
+
+// ParseDOI parses and checks a DOI, bare, as a doi: URI or as a link to
+// doi.org or dx.doi.org
+func ParseDOI(s string) (*DOI, error) {
+	s = strings.TrimSpace(s)
+	for _, prefix := range doiPrefixes {
+		if !hasPrefixFold(s, prefix) {
+			continue
+		}
+		s = s[len(prefix):]
+		if prefix != "doi:" {
+			if i := strings.IndexAny(s, "?#"); i >= 0 {
+				s = s[:i]
+			}
+			unescaped, err := url.PathUnescape(s)
+			if err != nil {
+				return nil, errors.New("payload: DOI link has an invalid percent-encoding")
+			}
+			s = unescaped
+		}
+		break
+	}
+	if err := checkDOI(s); err != nil {
+		return nil, err
+	}
+	return &DOI{DOI: s}, nil
+}
+
+// isDOI reports whether s starts like a DOI link or URI, rather than a
+// link to another page of doi.org
+func isDOI(s string) bool {
+	for _, prefix := range doiPrefixes {
+		if hasPrefixFold(s, prefix) && strings.HasPrefix(s[len(prefix):], "10.") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDOI(t *testing.T) {
+	testCases := []struct {
+		name string
+		doi  string
+		want string
+	}{
+		{"Simple", "10.1000/182", "https://doi.org/10.1000/182"},
+		{"Subdivided", "10.1000.10/123456", "https://doi.org/10.1000.10/123456"},
+		{"Slashes", "10.1038/nphys1170/abc", "https://doi.org/10.1038/nphys1170/abc"},
+		{"SICI", "10.1002/(SICI)1097-4571(199806)49:8<693::AID-ASI4>3.0.CO;2-0",
+			"https://doi.org/10.1002/%28SICI%291097-4571%28199806%2949:8%3C693::AID-ASI4%3E3.0.CO;2-0"},
+		{"Reserved", "10.5555/a?b#c%d", "https://doi.org/10.5555/a%3Fb%23c%25d"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&DOI{DOI: tc.doi}).Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseDOI(got)
+			if err != nil {
+				t.Fatalf("ParseDOI failed: %v", err)
+			}
+			if parsed.DOI != tc.doi {
+				t.Errorf("Parsed %q, expected %q", parsed.DOI, tc.doi)
+			}
+		})
+	}
+}
+
+func TestDOIInvalid(t *testing.T) {
+	for doi, want := range map[string]string{
+		"":              "must start with 10.",
+		"10.1000":       "must start with 10.",
+		"11.1000/182":   "must start with 10.",
+		"10./182":       "registrant code",
+		"10.10a0/182":   "registrant code",
+		"10.1000..1/x":  "registrant code",
+		"10.1000/":      "no suffix",
+		"10.1000/a b":   "space",
+		"10.1000/a\x00": "control",
+		"10.1000/\xff":  "UTF-8",
+	} {
+		if _, err := (&DOI{DOI: doi}).Payload(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DOI %q: expected an error about %s, got %v", doi, want, err)
+		}
+	}
+}
+
+func TestParseDOI(t *testing.T) {
+	for _, s := range []string{
+		"10.1000/182",
+		"doi:10.1000/182",
+		"DOI:10.1000/182",
+		"https://doi.org/10.1000/182",
+		"http://dx.doi.org/10.1000/182",
+		" https://doi.org/10.1000/182?utm_source=x\n",
+	} {
+		d, err := ParseDOI(s)
+		if err != nil || d.DOI != "10.1000/182" {
+			t.Errorf("%q parsed as %#v, %v", s, d, err)
+		}
+	}
+	if _, err := ParseDOI("https://doi.org/10.1000/%zz"); err == nil {
+		t.Error("Expected an error for an invalid percent-encoding")
+	}
+	if p, err := Parse([]byte("https://doi.org/the-identifier/")); err != nil || p.Type() != TypeURL {
+		t.Errorf("Expected other doi.org pages to be links, got %#v, %v", p, err)
+	}
+}
@@ -0,0 +1,83 @@
+package payload
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Argon2id parameters for version 1 envelopes, see RFC 9106 section 4
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	saltSize     = 16
+)
+
+// ErrDecrypt is returned when an encrypted envelope can not be opened,
+// either because the passphrase is wrong or the envelope was modified
+var ErrDecrypt = errors.New("payload: wrong passphrase or corrupted envelope")
+
+// Encrypt seals data with a key derived from passphrase using Argon2id and
+// XChaCha20-Poly1305. The envelope layout is:
+//
+//	version | 'E' | salt (16 bytes) | nonce (24 bytes) | ciphertext | tag (16 bytes)
+//
+// The header and salt are authenticated as additional data.
+func Encrypt(data, passphrase []byte) ([]byte, error) {
+	return EncryptWithOptions(data, passphrase, nil)
+}
+
+// EncryptWithOptions is Encrypt with the salt and nonce read from the
+// randomness of opts
+func EncryptWithOptions(data, passphrase []byte, opts *Options) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(opts.Random(), salt); err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(deriveKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(opts.Random(), nonce); err != nil {
+		return nil, err
+	}
+
+	env := make([]byte, 0, headerSize+saltSize+len(nonce)+len(data)+aead.Overhead())
+	env = append(env, Version, byte(KindEncrypted))
+	env = append(env, salt...)
+	ad := append([]byte(nil), env...)
+	env = append(env, nonce...)
+	return aead.Seal(env, nonce, data, ad), nil
+}
+
+// Decrypt opens an envelope created by Encrypt
+func Decrypt(env, passphrase []byte) ([]byte, error) {
+	body, err := open(env, KindEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < saltSize+chacha20poly1305.NonceSizeX+chacha20poly1305.Overhead {
+		return nil, ErrMalformed
+	}
+	salt := body[:saltSize]
+	nonce := body[saltSize : saltSize+chacha20poly1305.NonceSizeX]
+	ciphertext := body[saltSize+chacha20poly1305.NonceSizeX:]
+
+	aead, err := chacha20poly1305.NewX(deriveKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, nonce, ciphertext, env[:headerSize+saltSize])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return data, nil
+}
+
+func deriveKey(passphrase, salt []byte) []byte {
+	return argon2.IDKey(passphrase, salt, argonTime, argonMemory, argonThreads, chacha20poly1305.KeySize)
+}
@@ -0,0 +1,67 @@
+package payload
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	data := []byte("wireguard private key")
+	passphrase := []byte("correct horse battery staple")
+
+	env, err := Encrypt(data, passphrase)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if bytes.Contains(env, data) {
+		t.Errorf("Envelope should not contain the plaintext")
+	}
+	if kind, err := KindOf(env); err != nil || kind != KindEncrypted {
+		t.Errorf("Expected kind %q, got %q (%v)", KindEncrypted, kind, err)
+	}
+
+	got, err := Decrypt(env, passphrase)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+}
+
+func TestEncryptRandomized(t *testing.T) {
+	data := []byte("same input")
+	env1, _ := Encrypt(data, []byte("pw"))
+	env2, _ := Encrypt(data, []byte("pw"))
+	if bytes.Equal(env1, env2) {
+		t.Errorf("Encrypting twice should use a fresh salt and nonce")
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	env, err := Encrypt([]byte("secret"), []byte("pw"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	if _, err := Decrypt(env, []byte("wrong")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong passphrase, got %v", err)
+	}
+
+	tampered := append([]byte(nil), env...)
+	tampered[headerSize] ^= 0x01 // flip a salt bit
+	if _, err := Decrypt(tampered, []byte("pw")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for tampered salt, got %v", err)
+	}
+
+	if _, err := Decrypt(env[:10], []byte("pw")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected ErrMalformed for truncated envelope, got %v", err)
+	}
+
+	wrongVersion := append([]byte(nil), env...)
+	wrongVersion[0] = Version + 1
+	if _, err := Decrypt(wrongVersion, []byte("pw")); err == nil {
+		t.Errorf("Expected an error for an unsupported version")
+	}
+}
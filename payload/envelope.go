@@ -0,0 +1,53 @@
+// Package payload prepares the data that gets encoded into a QR code.
+//
+// Envelopes wrap a payload in a small, versioned binary header so the
+// scanning application can tell how to unwrap it. Every envelope starts
+// with two bytes: the wire format Version and the envelope Kind.
+package payload
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Version is the envelope wire format version written by this package
+const Version = 1
+
+// headerSize is the number of bytes preceding every envelope body
+const headerSize = 2
+
+// Kind identifies the type of an envelope
+type Kind byte
+
+// Envelope kinds
+const (
+	KindEncrypted Kind = 'E'
+	KindSigned    Kind = 'S'
+	KindExpiring  Kind = 'T'
+)
+
+// ErrMalformed is returned when an envelope is too short or otherwise corrupt
+var ErrMalformed = errors.New("payload: malformed envelope")
+
+// KindOf returns the kind of the envelope env
+func KindOf(env []byte) (Kind, error) {
+	if len(env) < headerSize {
+		return 0, ErrMalformed
+	}
+	if env[0] != Version {
+		return 0, fmt.Errorf("payload: unsupported envelope version %d", env[0])
+	}
+	return Kind(env[1]), nil
+}
+
+// open checks that env is an envelope of kind k and returns its body
+func open(env []byte, k Kind) ([]byte, error) {
+	kind, err := KindOf(env)
+	if err != nil {
+		return nil, err
+	}
+	if kind != k {
+		return nil, fmt.Errorf("payload: expected envelope kind %q, got %q", k, kind)
+	}
+	return env[headerSize:], nil
+}
@@ -0,0 +1,198 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// epcMaxSize is the largest EPC payload banking apps accept, in bytes
+const epcMaxSize = 331
+
+// epcMaxCents is the largest amount of an EPC payment, 999999999.99 euro
+const epcMaxCents = 99999999999
+
+// EPC is a SEPA credit transfer in the format of the European Payments
+// Council (EPC069-12) that banking apps read, also known as GiroCode
+type EPC struct {
+	// BIC of the bank of the beneficiary, optional within the EEA
+	BIC string `json:"bic,omitempty"`
+	// Name of the beneficiary
+	Name string `json:"name"`
+	IBAN string `json:"iban"`
+	// Cents is the amount in euro cents, zero leaves it to the payer
+	Cents int64 `json:"cents,omitempty"`
+	// Purpose is a four letter purpose code
+	Purpose string `json:"purpose,omitempty"`
+	// Reference is a structured creditor reference and Text a free
+	// remittance text, a payment has at most one of them
+	Reference string `json:"reference,omitempty"`
+	Text      string `json:"text,omitempty"`
+	// Info is a note to the payer
+	Info string `json:"info,omitempty"`
+}
+
+// Type returns TypeEPC
+func (e *EPC) Type() Type { return TypeEPC }
+
+// Payload checks e and returns its EPC payload, version 002 in UTF-8
+func (e *EPC) Payload() (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	amount := ""
+	if e.Cents > 0 {
+		amount = fmt.Sprintf("EUR%d.%02d", e.Cents/100, e.Cents%100)
+	}
+	lines := []string{"BCD", "002", "1", "SCT", e.BIC, e.Name, e.IBAN, amount, e.Purpose, e.Reference, e.Text, e.Info}
+	// Trailing empty lines may be left out
+	for lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	s := strings.Join(lines, "\n")
+	if len(s) > epcMaxSize {
+		return "", fmt.Errorf("payload: EPC payment is %d bytes, banking apps accept up to %d", len(s), epcMaxSize)
+	}
+	return s, nil
+}
+
+// check reports the first field of e that banking apps would reject
+func (e *EPC) check() error {
+	if n := len([]rune(e.Name)); n == 0 || n > 70 {
+		return fmt.Errorf("payload: EPC beneficiary name has %d characters, expected 1 to 70", n)
+	}
+	if err := checkIBAN(e.IBAN); err != nil {
+		return err
+	}
+	if e.BIC != "" && len(e.BIC) != 8 && len(e.BIC) != 11 {
+		return fmt.Errorf("payload: EPC BIC has %d characters, expected 8 or 11", len(e.BIC))
+	}
+	if e.Cents < 0 || e.Cents > epcMaxCents {
+		return errors.New("payload: EPC amount out of range, expected up to 999999999.99 euro")
+	}
+	if e.Purpose != "" && len(e.Purpose) != 4 {
+		return fmt.Errorf("payload: EPC purpose code %q is not four characters", e.Purpose)
+	}
+	if e.Reference != "" && e.Text != "" {
+		return errors.New("payload: EPC payment has both a creditor reference and a remittance text")
+	}
+	if n := len([]rune(e.Reference)); n > 35 {
+		return fmt.Errorf("payload: EPC creditor reference has %d characters, expected up to 35", n)
+	}
+	if n := len([]rune(e.Text)); n > 140 {
+		return fmt.Errorf("payload: EPC remittance text has %d characters, expected up to 140", n)
+	}
+	if n := len([]rune(e.Info)); n > 70 {
+		return fmt.Errorf("payload: EPC note has %d characters, expected up to 70", n)
+	}
+	for _, v := range []string{e.BIC, e.Name, e.IBAN, e.Purpose, e.Reference, e.Text, e.Info} {
+		if strings.ContainsAny(v, "\r\n") {
+			return errors.New("payload: EPC fields can not contain line breaks")
+		}
+	}
+	return nil
+}
+
+// checkIBAN checks the country code, length and check digits of an IBAN
+// without spaces, as ISO 13616 defines them
+func checkIBAN(iban string) error {
+	if len(iban) < 15 || len(iban) > 34 {
+		return fmt.Errorf("payload: IBAN has %d characters, expected 15 to 34 without spaces", len(iban))
+	}
+	var digits strings.Builder
+	for i, c := range iban[4:] + iban[:4] {
+		switch {
+		case '0' <= c && c <= '9' && (i < len(iban)-4 || i >= len(iban)-2):
+			digits.WriteRune(c)
+		case 'A' <= c && c <= 'Z' && i < len(iban)-2:
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		default:
+			return errors.New("payload: IBAN is not a country code, two check digits and upper case letters or digits")
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(n, big.NewInt(97)).Int64() != 1 {
+		return errors.New("payload: IBAN check digits do not match, it has a typo")
+	}
+	return nil
+}
+
+// Fields returns the beneficiary, account, amount and remittance
+// information of e
+func (e *EPC) Fields() []Field {
+	fields := []Field{{"Beneficiary", e.Name}, {"IBAN", e.IBAN}}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, Field{name, value})
+		}
+	}
+	add("BIC", e.BIC)
+	if e.Cents > 0 {
+		add("Amount", fmt.Sprintf("%d.%02d EUR", e.Cents/100, e.Cents%100))
+	} else {
+		add("Amount", "left to the payer")
+	}
+	add("Purpose", e.Purpose)
+	add("Reference", e.Reference)
+	add("Text", e.Text)
+	add("Note", e.Info)
+	return fields
+}
+
+// ParseEPC parses and checks an EPC payload of version 001 or 002 in
+// UTF-8
+func ParseEPC(s string) (*EPC, error) {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if lines[0] != "BCD" {
+		return nil, errors.New("payload: not an EPC payment, expected BCD")
+	}
+	for len(lines) < 12 {
+		lines = append(lines, "")
+	}
+	if lines[1] != "001" && lines[1] != "002" {
+		return nil, fmt.Errorf("payload: unknown EPC version %q", lines[1])
+	}
+	if lines[2] != "1" {
+		return nil, fmt.Errorf("payload: EPC character set %q is not UTF-8 (1)", lines[2])
+	}
+	if lines[3] != "SCT" {
+		return nil, fmt.Errorf("payload: EPC identification %q is not SCT", lines[3])
+	}
+	if strings.TrimSpace(strings.Join(lines[12:], "")) != "" {
+		return nil, errors.New("payload: EPC payment has more than 12 lines")
+	}
+	e := &EPC{BIC: lines[4], Name: lines[5], IBAN: lines[6], Purpose: lines[8], Reference: lines[9], Text: lines[10], Info: lines[11]}
+	if amount := lines[7]; amount != "" {
+		cents, err := parseEuro(amount)
+		if err != nil {
+			return nil, err
+		}
+		e.Cents = cents
+	}
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseEuro returns the cents of an EPC amount such as EUR12.5
+func parseEuro(amount string) (int64, error) {
+	bad := errors.New("payload: EPC amount is not EUR followed by up to two decimals")
+	if !strings.HasPrefix(amount, "EUR") {
+		return 0, bad
+	}
+	whole, frac, _ := strings.Cut(amount[3:], ".")
+	if whole == "" || len(frac) > 2 || strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, bad
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	cents, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || cents > epcMaxCents {
+		return 0, errors.New("payload: EPC amount out of range, expected up to 999999999.99 euro")
+	}
+	return cents, nil
+}
@@ -0,0 +1,66 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEPC(t *testing.T) {
+	e := EPC{BIC: "BFSWDE33BER", Name: "Wikimedia Foerdergesellschaft", IBAN: "DE33100205000001194700",
+		Cents: 12345, Text: "Spende fuer Wikipedia"}
+	got, err := e.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "BCD\n002\n1\nSCT\nBFSWDE33BER\nWikimedia Foerdergesellschaft\nDE33100205000001194700\nEUR123.45\n\n\nSpende fuer Wikipedia"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	parsed, err := ParseEPC(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != e {
+		t.Errorf("Parsed %#v, expected %#v", *parsed, e)
+	}
+}
+
+func TestEPCInvalid(t *testing.T) {
+	valid := EPC{Name: "Red Cross", IBAN: "DE89370400440532013000"}
+	testCases := []struct {
+		name   string
+		change func(e *EPC)
+		want   string
+	}{
+		{"NoName", func(e *EPC) { e.Name = "" }, "name"},
+		{"CheckDigits", func(e *EPC) { e.IBAN = "DE89370400440532013001" }, "typo"},
+		{"Spaces", func(e *EPC) { e.IBAN = "DE89 3704 0044 0532 0130 00" }, "upper case"},
+		{"BIC", func(e *EPC) { e.BIC = "COBADE" }, "BIC"},
+		{"Amount", func(e *EPC) { e.Cents = epcMaxCents + 1 }, "amount"},
+		{"Both", func(e *EPC) { e.Reference, e.Text = "RF18539007547034", "thanks" }, "both"},
+		{"LineBreak", func(e *EPC) { e.Info = "a\nb" }, "line breaks"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := valid
+			tc.change(&e)
+			_, err := e.Payload()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error about %s, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestParseEuro(t *testing.T) {
+	for amount, want := range map[string]int64{"EUR1": 100, "EUR0.5": 50, "EUR12.34": 1234, "EUR999999999.99": epcMaxCents} {
+		if got, err := parseEuro(amount); err != nil || got != want {
+			t.Errorf("parseEuro(%q) = %d, %v, expected %d", amount, got, err, want)
+		}
+	}
+	for _, amount := range []string{"12.34", "EUR", "EUR1.234", "EUR-1", "EUR1,50", "EUR1000000000"} {
+		if _, err := parseEuro(amount); err == nil {
+			t.Errorf("Expected an error for %q", amount)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Ethereum is a payment request in the ethereum: URI format of EIP-681,
+// of ether or, with Token, of an ERC-20 token
+type Ethereum struct {
+	// Address receives the payment
+	Address string `json:"address"`
+	// ChainID is the network, wallets take the one they are on if zero
+	ChainID uint64 `json:"chain_id,omitempty"`
+	// Value is the amount in wei, or in the smallest unit of Token, as a
+	// decimal integer, empty leaves it to the payer
+	Value string `json:"value,omitempty"`
+	// Token is the contract address of an ERC-20 token to transfer
+	// instead of ether
+	Token string `json:"token,omitempty"`
+}
+
+// Type returns TypeEthereum
+func (e *Ethereum) Type() Type { return TypeEthereum }
+
+// Payload checks e and returns its ethereum: URI
+func (e *Ethereum) Payload() (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	target := e.Address
+	if e.Token != "" {
+		target = e.Token
+	}
+	s := "ethereum:" + target
+	if e.ChainID != 0 {
+		s += "@" + strconv.FormatUint(e.ChainID, 10)
+	}
+	switch {
+	case e.Token != "":
+		s += "/transfer?address=" + e.Address
+		if e.Value != "" {
+			s += "&uint256=" + e.Value
+		}
+	case e.Value != "":
+		s += "?value=" + e.Value
+	}
+	return s, nil
+}
+
+func (e *Ethereum) check() error {
+	if err := checkEthereumAddress("address", e.Address); err != nil {
+		return err
+	}
+	if e.Token != "" {
+		if err := checkEthereumAddress("token address", e.Token); err != nil {
+			return err
+		}
+	}
+	if e.Value != "" && (!isDigits(e.Value) || len(e.Value) > 1 && e.Value[0] == '0') {
+		return errors.New("payload: Ethereum value is not a decimal integer")
+	}
+	return nil
+}
+
+// Fields returns the address, network, token and value of e
+func (e *Ethereum) Fields() []Field {
+	fields := []Field{{"Address", e.Address}}
+	if e.ChainID != 0 {
+		fields = append(fields, Field{"Chain ID", strconv.FormatUint(e.ChainID, 10)})
+	}
+	unit := "wei"
+	if e.Token != "" {
+		fields = append(fields, Field{"Token", e.Token})
+		unit = "token units"
+	}
+	if e.Value != "" {
+		fields = append(fields, Field{"Value", e.Value + " " + unit})
+	} else {
+		fields = append(fields, Field{"Value", "left to the payer"})
+	}
+	return fields
+}
+
+// ParseEthereum parses and checks an ethereum: URI that pays ether or
+// calls transfer on an ERC-20 token. Values may use the scientific
+// notation of EIP-681, e.g. 1.5e18. Addresses must be hex, ENS names are
+// not resolved.
+func ParseEthereum(s string) (*Ethereum, error) {
+	s = strings.TrimSpace(s)
+	if !hasPrefixFold(s, "ethereum:") {
+		return nil, errors.New("payload: not an Ethereum payment, expected ethereum:")
+	}
+	rest, query, _ := strings.Cut(strings.TrimPrefix(s[len("ethereum:"):], "pay-"), "?")
+	rest, function, hasFunction := strings.Cut(rest, "/")
+	target, chain, hasChain := strings.Cut(rest, "@")
+	e := &Ethereum{}
+	if hasChain {
+		id, err := strconv.ParseUint(chain, 10, 64)
+		if err != nil {
+			return nil, errors.New("payload: Ethereum chain ID is not a number")
+		}
+		e.ChainID = id
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("payload: invalid Ethereum parameters: %v", parseError(err))
+	}
+	value := "value"
+	switch {
+	case !hasFunction:
+		e.Address = target
+	case function == "transfer":
+		e.Token, e.Address = target, params.Get("address")
+		value = "uint256"
+	default:
+		return nil, fmt.Errorf("payload: Ethereum function %q is not a payment, only ERC-20 transfer is", function)
+	}
+	if v := params.Get(value); v != "" {
+		if e.Value, err = parseWei(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseWei returns the decimal integer of an EIP-681 number, such as
+// 2014000000000000000 or 2.014e18
+func parseWei(v string) (string, error) {
+	if strings.ContainsAny(v, "/+-") {
+		return "", errors.New("payload: Ethereum value is not a positive number")
+	}
+	// 2^256 has 78 digits, larger exponents would only take memory
+	if _, exp, ok := strings.Cut(strings.ToLower(v), "e"); ok {
+		if n, err := strconv.Atoi(exp); err != nil || n > 80 {
+			return "", errors.New("payload: Ethereum value does not fit 256 bits")
+		}
+	}
+	r, ok := new(big.Rat).SetString(v)
+	if !ok || !r.IsInt() {
+		return "", errors.New("payload: Ethereum value is not an integer")
+	}
+	if r.Num().BitLen() > 256 {
+		return "", errors.New("payload: Ethereum value does not fit 256 bits")
+	}
+	return r.Num().String(), nil
+}
@@ -0,0 +1,61 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEthereum(t *testing.T) {
+	const (
+		to    = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+		token = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	)
+	testCases := []struct {
+		name string
+		eth  Ethereum
+		want string
+	}{
+		{"Address", Ethereum{Address: to}, "ethereum:" + to},
+		{"Ether", Ethereum{Address: to, ChainID: 1, Value: "2014000000000000000"}, "ethereum:" + to + "@1?value=2014000000000000000"},
+		{"Token", Ethereum{Address: to, Token: token, Value: "1000000"}, "ethereum:" + token + "/transfer?address=" + to + "&uint256=1000000"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.eth.Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseEthereum(got)
+			if err != nil {
+				t.Fatalf("ParseEthereum failed: %v", err)
+			}
+			if *parsed != tc.eth {
+				t.Errorf("Parsed %#v, expected %#v", *parsed, tc.eth)
+			}
+		})
+	}
+
+	// The examples of EIP-681
+	e, err := ParseEthereum("ethereum:pay-" + to + "@1?value=2.014e18&gasPrice=21000")
+	if err != nil || e.Value != "2014000000000000000" || e.ChainID != 1 {
+		t.Errorf("Parsed %#v, %v", e, err)
+	}
+	for s, want := range map[string]string{
+		"ethereum:" + to + "/approve?address=" + token:      "not a payment",
+		"ethereum:" + to + "?value=1.5":                     "integer",
+		"ethereum:" + to + "?value=-1":                      "positive",
+		"ethereum:" + to + "?value=1e999999999":             "256 bits",
+		"ethereum:" + to + "@mainnet":                       "chain ID",
+		"ethereum:" + strings.ToLower(to[:41]) + "?value=1": "40 hex digits",
+	} {
+		if e, err := ParseEthereum(s); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error about %s, got %#v, %v", s, want, e, err)
+		}
+	}
+	if _, err := (&Ethereum{Address: to, Value: "007"}).Payload(); err == nil {
+		t.Error("Expected an error for a value with leading zeros")
+	}
+}
@@ -0,0 +1,57 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// MaxClockSkew is how far in the future an issued-at time may be before
+// CheckExpiry rejects the envelope
+const MaxClockSkew = time.Minute
+
+const timestampSize = 8
+
+var (
+	// ErrExpired is returned for an envelope checked after its expiry time
+	ErrExpired = errors.New("payload: envelope has expired")
+	// ErrNotYetValid is returned for an envelope issued in the future
+	ErrNotYetValid = errors.New("payload: envelope is not valid yet")
+)
+
+// Expire wraps data in an envelope that is only valid between issuedAt and
+// expiresAt. Times are stored with one second precision. The envelope
+// layout is:
+//
+//	version | 'T' | issued-at (8 bytes) | expires-at (8 bytes) | data
+//
+// Both times are big endian Unix seconds. The envelope is not authenticated
+// on its own, wrap it with Sign to keep the window from being altered.
+func Expire(data []byte, issuedAt, expiresAt time.Time) []byte {
+	env := make([]byte, headerSize+2*timestampSize, headerSize+2*timestampSize+len(data))
+	env[0], env[1] = Version, byte(KindExpiring)
+	binary.BigEndian.PutUint64(env[headerSize:], uint64(issuedAt.Unix()))
+	binary.BigEndian.PutUint64(env[headerSize+timestampSize:], uint64(expiresAt.Unix()))
+	return append(env, data...)
+}
+
+// CheckExpiry validates the window of an envelope created by Expire against
+// now and returns the wrapped data
+func CheckExpiry(env []byte, now time.Time) ([]byte, error) {
+	body, err := open(env, KindExpiring)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < 2*timestampSize {
+		return nil, ErrMalformed
+	}
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint64(body)), 0)
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(body[timestampSize:])), 0)
+	if now.Add(MaxClockSkew).Before(issuedAt) {
+		return nil, ErrNotYetValid
+	}
+	if !now.Before(expiresAt) {
+		return nil, ErrExpired
+	}
+	return body[2*timestampSize:], nil
+}
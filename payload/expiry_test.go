@@ -0,0 +1,64 @@
+package payload
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	issued := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data := []byte("session-token")
+	env := Expire(data, issued, issued.Add(5*time.Minute))
+
+	testCases := []struct {
+		name string
+		now  time.Time
+		err  error
+	}{
+		{"AtIssue", issued, nil},
+		{"WithinWindow", issued.Add(4 * time.Minute), nil},
+		{"SmallClockSkew", issued.Add(-30 * time.Second), nil},
+		{"BeforeIssue", issued.Add(-2 * time.Minute), ErrNotYetValid},
+		{"AtExpiry", issued.Add(5 * time.Minute), ErrExpired},
+		{"AfterExpiry", issued.Add(time.Hour), ErrExpired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckExpiry(env, tc.now)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if err == nil && !bytes.Equal(got, data) {
+				t.Errorf("Expected %q, got %q", data, got)
+			}
+		})
+	}
+}
+
+func TestExpirySigned(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	now := time.Now()
+	env := Sign(Expire([]byte("pairing"), now, now.Add(time.Minute)), priv)
+
+	inner, err := Verify(env, pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if kind, _ := KindOf(inner); kind != KindExpiring {
+		t.Fatalf("Expected an expiring envelope inside the signature, got %q", kind)
+	}
+	if _, err := CheckExpiry(inner, now.Add(2*time.Minute)); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
+
+func TestExpiryMalformed(t *testing.T) {
+	env := Expire(nil, time.Now(), time.Now())
+	if _, err := CheckExpiry(env[:headerSize+4], time.Now()); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected ErrMalformed, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package payload
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Geo is a location, as a geo: URI (RFC 5870) that map apps open
+type Geo struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Type returns TypeGeo
+func (g *Geo) Type() Type { return TypeGeo }
+
+// Payload checks g and returns its geo: URI, with the shortest decimals
+// that read back as the same coordinates
+func (g *Geo) Payload() (string, error) {
+	if math.IsNaN(g.Lat) || g.Lat < -90 || g.Lat > 90 {
+		return "", errors.New("payload: latitude out of range, expected -90 to 90")
+	}
+	if math.IsNaN(g.Lon) || g.Lon < -180 || g.Lon > 180 {
+		return "", errors.New("payload: longitude out of range, expected -180 to 180")
+	}
+	return "geo:" + strconv.FormatFloat(g.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(g.Lon, 'f', -1, 64), nil
+}
+
+// Fields returns the coordinates of g
+func (g *Geo) Fields() []Field {
+	return []Field{
+		{"Latitude", strconv.FormatFloat(g.Lat, 'f', -1, 64)},
+		{"Longitude", strconv.FormatFloat(g.Lon, 'f', -1, 64)},
+	}
+}
+
+// ParseGeo parses a geo: URI. The altitude, the parameters and the query
+// that some apps add, such as a label, are ignored.
+func ParseGeo(s string) (*Geo, error) {
+	s = strings.TrimSpace(s)
+	if !hasPrefixFold(s, "geo:") {
+		return nil, errors.New("payload: not a location, expected geo:")
+	}
+	coords := s[len("geo:"):]
+	if i := strings.IndexAny(coords, ";?"); i >= 0 {
+		coords = coords[:i]
+	}
+	parts := strings.Split(coords, ",")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, errors.New("payload: location is not latitude,longitude")
+	}
+	var g Geo
+	var err error
+	if g.Lat, err = strconv.ParseFloat(parts[0], 64); err != nil {
+		return nil, errors.New("payload: latitude is not a number")
+	}
+	if g.Lon, err = strconv.ParseFloat(parts[1], 64); err != nil {
+		return nil, errors.New("payload: longitude is not a number")
+	}
+	if _, err := g.Payload(); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
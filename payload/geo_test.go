@@ -0,0 +1,26 @@
+package payload
+
+import "testing"
+
+func TestGeo(t *testing.T) {
+	g := Geo{Lat: -33.8568, Lon: 151.2153}
+	got, err := g.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "geo:-33.8568,151.2153"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	parsed, err := ParseGeo("geo:-33.8568,151.2153,58;u=35?q=Opera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != g {
+		t.Errorf("Parsed %#v, expected %#v", *parsed, g)
+	}
+	for _, s := range []string{"geo:1", "geo:a,b", "geo:0,181", "geo:NaN,0"} {
+		if _, err := ParseGeo(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// isbnResolver is the Open Library URL that redirects an ISBN to the page
+// of its edition
+const isbnResolver = "https://openlibrary.org/isbn/"
+
+// gs1Resolver is the GS1 Digital Link URL that redirects a GTIN to the
+// information its brand owner registered
+const gs1Resolver = "https://id.gs1.org/01/"
+
+// ISBN is the International Standard Book Number of a book, as a link to
+// its Open Library page
+type ISBN struct {
+	// ISBN is an ISBN-13, or an ISBN-10 that Payload converts, with or
+	// without hyphens and spaces
+	ISBN string `json:"isbn"`
+}
+
+// Type returns TypeISBN
+func (i *ISBN) Type() Type { return TypeISBN }
+
+// Payload checks the check digit of i and returns its Open Library link,
+// with the ISBN-13 without hyphens
+func (i *ISBN) Payload() (string, error) {
+	isbn, err := normalizeISBN(i.ISBN)
+	if err != nil {
+		return "", err
+	}
+	return isbnResolver + isbn, nil
+}
+
+// normalizeISBN checks isbn and returns it as an ISBN-13 of 13 digits
+func normalizeISBN(isbn string) (string, error) {
+	digits := stripSeparators(isbn)
+	switch len(digits) {
+	case 13:
+		if !isDigits(digits) {
+			return "", errors.New("payload: ISBN-13 must be 13 digits")
+		}
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", errors.New("payload: ISBN-13 must start with 978 or 979, other EANs are not books")
+		}
+		if err := checkGTIN("ISBN", digits); err != nil {
+			return "", err
+		}
+		return digits, nil
+	case 10:
+		check := digits[9]
+		if check == 'x' {
+			check = 'X'
+		}
+		if !isDigits(digits[:9]) || check != 'X' && !isDigits(digits[9:]) {
+			return "", errors.New("payload: ISBN-10 must be 9 digits and a check digit or X")
+		}
+		if want := isbn10CheckDigit(digits[:9]); check != want {
+			return "", fmt.Errorf("payload: ISBN-10 check digit should be %c, not %c, the number has a typo", want, check)
+		}
+		body := "978" + digits[:9]
+		return body + string(gtinCheckDigit(body)), nil
+	}
+	return "", fmt.Errorf("payload: ISBN has %d characters, expected 13 digits, or 10 for an ISBN-10", utf8.RuneCountInString(digits))
+}
+
+// isbn10CheckDigit returns the check digit of the first 9 digits of an
+// ISBN-10, a digit or X for 10
+func isbn10CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(body[i]-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// gtinCheckDigit returns the check digit of the digits of an EAN, UPC or
+// ISBN-13 before it, weighted 3 and 1 from the right
+func gtinCheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		d := int(body[len(body)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// checkGTIN checks the last of digits against the check digit of the others
+func checkGTIN(what, digits string) error {
+	last := len(digits) - 1
+	if want := gtinCheckDigit(digits[:last]); digits[last] != want {
+		return fmt.Errorf("payload: %s check digit should be %c, not %c, the number has a typo", what, want, digits[last])
+	}
+	return nil
+}
+
+// stripSeparators removes the hyphens and spaces printed numbers are
+// grouped with
+func stripSeparators(s string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s))
+}
+
+// Fields returns the ISBN-13, the ISBN-10 of books that have one, and the
+// link
+func (i *ISBN) Fields() []Field {
+	isbn, err := normalizeISBN(i.ISBN)
+	if err != nil {
+		return []Field{{"ISBN", i.ISBN}}
+	}
+	fields := []Field{{"ISBN", isbn}}
+	if strings.HasPrefix(isbn, "978") {
+		fields = append(fields, Field{"ISBN-10", isbn[3:12] + string(isbn10CheckDigit(isbn[3:12]))})
+	}
+	return append(fields, Field{"URL", isbnResolver + isbn})
+}
+
+// ParseISBN parses and checks an ISBN-13 or ISBN-10, as an Open Library
+// link, a urn:isbn: URI or printed with an ISBN label, hyphens and spaces.
+// It returns the ISBN-13 without hyphens.
+func ParseISBN(s string) (*ISBN, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case hasPrefixFold(s, isbnResolver):
+		s = s[len(isbnResolver):]
+		if i := strings.IndexAny(s, "/?#"); i >= 0 {
+			s = s[:i]
+		}
+	case hasPrefixFold(s, "urn:isbn:"):
+		s = s[len("urn:isbn:"):]
+	default:
+		for _, label := range []string{"ISBN-13", "ISBN-10", "ISBN"} {
+			if hasPrefixFold(s, label) {
+				s = strings.TrimLeft(s[len(label):], ": ")
+				break
+			}
+		}
+	}
+	isbn, err := normalizeISBN(s)
+	if err != nil {
+		return nil, err
+	}
+	return &ISBN{ISBN: isbn}, nil
+}
+
+// EAN is the barcode number of a product, a GTIN, as a GS1 Digital Link
+// that phones open without a barcode app
+type EAN struct {
+	// Code is an EAN-13, EAN-8, UPC-A of 12 digits or GTIN-14, with or
+	// without hyphens and spaces
+	Code string `json:"code"`
+}
+
+// Type returns TypeEAN
+func (e *EAN) Type() Type { return TypeEAN }
+
+// Payload checks the check digit of e and returns its GS1 Digital Link,
+// with the code padded to the 14 digits of a GTIN
+func (e *EAN) Payload() (string, error) {
+	gtin, err := normalizeGTIN(e.Code)
+	if err != nil {
+		return "", err
+	}
+	return gs1Resolver + gtin, nil
+}
+
+// normalizeGTIN checks code and returns it padded to 14 digits
+func normalizeGTIN(code string) (string, error) {
+	digits := stripSeparators(code)
+	switch len(digits) {
+	case 8, 12, 13, 14:
+	default:
+		return "", fmt.Errorf("payload: EAN has %d characters, expected 13 digits, or 8, 12 or 14", utf8.RuneCountInString(digits))
+	}
+	if !isDigits(digits) {
+		return "", errors.New("payload: EAN must be digits")
+	}
+	if err := checkGTIN("EAN", digits); err != nil {
+		return "", err
+	}
+	return strings.Repeat("0", 14-len(digits)) + digits, nil
+}
+
+// Fields returns the code, the ISBN of books and the link
+func (e *EAN) Fields() []Field {
+	fields := []Field{{"EAN", e.Code}}
+	gtin, err := normalizeGTIN(e.Code)
+	if err != nil {
+		return fields
+	}
+	if isbn := gtin[1:]; gtin[0] == '0' && (strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) {
+		fields = append(fields, Field{"ISBN", isbn})
+	}
+	return append(fields, Field{"URL", gs1Resolver + gtin})
+}
+
+// ParseEAN parses and checks an EAN, bare or as a GS1 Digital Link, whose
+// further qualifiers such as a batch or serial number are ignored. The
+// code of a link is returned as 13 digits, so EAN-8 and UPC-A codes come
+// back with leading zeros, and GTIN-14 codes as 14.
+func ParseEAN(s string) (*EAN, error) {
+	s = strings.TrimSpace(s)
+	fromLink := hasPrefixFold(s, gs1Resolver)
+	if fromLink {
+		s = s[len(gs1Resolver):]
+		if i := strings.IndexAny(s, "/?#"); i >= 0 {
+			s = s[:i]
+		}
+	}
+	gtin, err := normalizeGTIN(s)
+	if err != nil {
+		return nil, err
+	}
+	code := stripSeparators(s)
+	if fromLink {
+		code = strings.TrimPrefix(gtin, "0")
+	}
+	return &EAN{Code: code}, nil
+}
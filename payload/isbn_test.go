@@ -0,0 +1,130 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestISBN(t *testing.T) {
+	testCases := []struct {
+		name string
+		isbn string
+		want string
+	}{
+		{"ISBN13", "9780306406157", "https://openlibrary.org/isbn/9780306406157"},
+		{"Hyphens", "978-0-306-40615-7", "https://openlibrary.org/isbn/9780306406157"},
+		{"ISBN10", "0-306-40615-2", "https://openlibrary.org/isbn/9780306406157"},
+		{"ISBN10X", "3-16-148410-X", "https://openlibrary.org/isbn/9783161484100"},
+		{"ISBN10LowerX", "316148410x", "https://openlibrary.org/isbn/9783161484100"},
+		{"979", "979-12-345678-9-6", "https://openlibrary.org/isbn/9791234567896"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&ISBN{ISBN: tc.isbn}).Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseISBN(got)
+			if err != nil {
+				t.Fatalf("ParseISBN failed: %v", err)
+			}
+			if want := strings.TrimPrefix(tc.want, isbnResolver); parsed.ISBN != want {
+				t.Errorf("Parsed %q, expected %q", parsed.ISBN, want)
+			}
+		})
+	}
+}
+
+func TestISBNInvalid(t *testing.T) {
+	for isbn, want := range map[string]string{
+		"9780306406158": "should be 7, not 8",
+		"0306406153":    "should be 2, not 3",
+		"4006381333931": "978 or 979",
+		"978030640615":  "12 characters",
+		"978030640615a": "13 digits",
+		"03064061X2":    "9 digits",
+		"":              "0 characters",
+	} {
+		if _, err := (&ISBN{ISBN: isbn}).Payload(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ISBN %q: expected an error about %s, got %v", isbn, want, err)
+		}
+	}
+}
+
+func TestParseISBN(t *testing.T) {
+	for _, s := range []string{
+		"ISBN 978-0-306-40615-7",
+		"ISBN-13: 978-0-306-40615-7",
+		"isbn-10: 0-306-40615-2",
+		"urn:isbn:9780306406157",
+		"https://openlibrary.org/isbn/0306406152",
+		"https://openlibrary.org/isbn/9780306406157?edition=",
+	} {
+		i, err := ParseISBN(s)
+		if err != nil || i.ISBN != "9780306406157" {
+			t.Errorf("%q parsed as %#v, %v", s, i, err)
+		}
+	}
+}
+
+func TestISBNFields(t *testing.T) {
+	fields := (&ISBN{ISBN: "978-0-306-40615-7"}).Fields()
+	want := []Field{{"ISBN", "9780306406157"}, {"ISBN-10", "0306406152"}, {"URL", "https://openlibrary.org/isbn/9780306406157"}}
+	if len(fields) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], fields[i])
+		}
+	}
+}
+
+func TestEAN(t *testing.T) {
+	testCases := []struct {
+		name   string
+		code   string
+		want   string
+		parsed string
+	}{
+		{"EAN13", "4006381333931", "https://id.gs1.org/01/04006381333931", "4006381333931"},
+		{"EAN8", "9638 5074", "https://id.gs1.org/01/00000096385074", "0000096385074"},
+		{"UPCA", "036000291452", "https://id.gs1.org/01/00036000291452", "0036000291452"},
+		{"GTIN14", "10614141000415", "https://id.gs1.org/01/10614141000415", "10614141000415"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&EAN{Code: tc.code}).Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseEAN(got + "/10/ABC123?linkType=gs1:pip")
+			if err != nil {
+				t.Fatalf("ParseEAN failed: %v", err)
+			}
+			if parsed.Code != tc.parsed {
+				t.Errorf("Parsed %q, expected %q", parsed.Code, tc.parsed)
+			}
+		})
+	}
+}
+
+func TestEANInvalid(t *testing.T) {
+	for code, want := range map[string]string{
+		"4006381333932": "should be 1, not 2",
+		"96385075":      "should be 4, not 5",
+		"400638133393":  "should be 0, not 3",
+		"40063813339":   "11 characters",
+		"400638133393a": "digits",
+	} {
+		if _, err := (&EAN{Code: code}).Payload(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("EAN %q: expected an error about %s, got %v", code, want, err)
+		}
+	}
+}
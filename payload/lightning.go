@@ -0,0 +1,145 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lightningScheme is the URI scheme of Lightning invoices
+const lightningScheme = "lightning:"
+
+// lightningNetworks names the networks of the currency prefixes of BOLT 11
+var lightningNetworks = map[string]string{
+	"bc":   "bitcoin",
+	"tb":   "testnet",
+	"tbs":  "signet",
+	"bcrt": "regtest",
+}
+
+// Lightning is a BOLT 11 Lightning invoice. It is checked and passed
+// through as is, only its amount and network are read.
+type Lightning struct {
+	// Invoice is the invoice in lower case
+	Invoice string `json:"invoice"`
+	// URI prefixes the invoice with the lightning: scheme
+	URI bool `json:"uri,omitempty"`
+}
+
+// Type returns TypeLightning
+func (l *Lightning) Type() Type { return TypeLightning }
+
+// Payload checks l and returns the invoice in upper case, which QR codes
+// store in the denser alphanumeric mode
+func (l *Lightning) Payload() (string, error) {
+	if _, _, err := l.parse(); err != nil {
+		return "", err
+	}
+	s := strings.ToUpper(l.Invoice)
+	if l.URI {
+		s = strings.ToUpper(lightningScheme) + s
+	}
+	return s, nil
+}
+
+// parse checks the invoice and returns the network and the amount of its
+// human readable part, in millisatoshi, -1 if it has none
+func (l *Lightning) parse() (string, int64, error) {
+	if l.Invoice != strings.ToLower(l.Invoice) {
+		return "", 0, errors.New("payload: Lightning invoice must be in lower case")
+	}
+	hrp, _, constant, err := decodeBech32(l.Invoice)
+	if err != nil {
+		return "", 0, fmt.Errorf("payload: Lightning invoice %v", err)
+	}
+	if constant != bech32Const || !strings.HasPrefix(hrp, "ln") {
+		return "", 0, errors.New("payload: not a Lightning invoice")
+	}
+	currency := strings.TrimRight(hrp[2:], "0123456789munp")
+	network, ok := lightningNetworks[currency]
+	if !ok {
+		return "", 0, fmt.Errorf("payload: Lightning invoice is for the unknown currency %q", currency)
+	}
+	amount := hrp[2+len(currency):]
+	if amount == "" {
+		return network, -1, nil
+	}
+	msat, err := parseInvoiceAmount(amount)
+	if err != nil {
+		return "", 0, err
+	}
+	return network, msat, nil
+}
+
+// parseInvoiceAmount returns the millisatoshi of the amount of an invoice,
+// a number of BTC with an optional multiplier
+func parseInvoiceAmount(amount string) (int64, error) {
+	// millisatoshi per unit, p is a tenth
+	multipliers := map[byte]int64{'m': 100000000, 'u': 100000, 'n': 100, 'p': 1}
+	mult, ok := multipliers[amount[len(amount)-1]]
+	digits := amount
+	if ok {
+		digits = amount[:len(amount)-1]
+	} else {
+		mult = 100000000000
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	bad := errors.New("payload: Lightning invoice amount is not a number and a multiplier")
+	if err != nil || !isDigits(digits) || digits[0] == '0' {
+		return 0, bad
+	}
+	if amount[len(amount)-1] == 'p' {
+		if n%10 != 0 {
+			return 0, errors.New("payload: Lightning invoice amount is a fraction of a millisatoshi")
+		}
+		n /= 10
+	}
+	if n > maxBTC*100000000000/mult {
+		return 0, errors.New("payload: Lightning invoice amount is more than there will ever be")
+	}
+	return n * mult, nil
+}
+
+// Fields returns the invoice, its network and amount
+func (l *Lightning) Fields() []Field {
+	fields := []Field{{"Invoice", l.Invoice}}
+	network, msat, err := l.parse()
+	if err != nil {
+		return fields
+	}
+	fields = append(fields, Field{"Network", network})
+	switch {
+	case msat < 0:
+		fields = append(fields, Field{"Amount", "left to the payer"})
+	case msat%1000 == 0:
+		fields = append(fields, Field{"Amount", strconv.FormatInt(msat/1000, 10) + " sat"})
+	default:
+		fields = append(fields, Field{"Amount", strconv.FormatInt(msat, 10) + " msat"})
+	}
+	return fields
+}
+
+// ParseLightning parses and checks a BOLT 11 invoice, with or without the
+// lightning: scheme, in either case
+func ParseLightning(s string) (*Lightning, error) {
+	s = strings.TrimSpace(s)
+	l := &Lightning{}
+	if hasPrefixFold(s, lightningScheme) {
+		s, l.URI = s[len(lightningScheme):], true
+	}
+	if strings.ToUpper(s) == s {
+		s = strings.ToLower(s)
+	}
+	l.Invoice = s
+	if _, _, err := l.parse(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// isLightningInvoice reports whether s starts like an invoice without the
+// lightning: scheme
+func isLightningInvoice(s string) bool {
+	return hasPrefixFold(s, "lnbc") || hasPrefixFold(s, "lntb")
+}
@@ -0,0 +1,68 @@
+package payload
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The examples of BOLT 11
+const (
+	invoiceDonation = "lnbc1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdpl2pkx2ctnv5sxxmmwwd5kgetjypeh2ursdae8g6twvus8g6rfwvs8qun0dfjkxaq8rkx3yf5tcsyz3d73gafnh3cax9rn449d9p5uxz9ezhhypd0elx87sjle52x86fux2ypatgddc6k63n7erqz25le42c4u4ecky03ylcqca784w"
+	invoiceCoffee   = "lnbc2500u1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdq5xysxxatsyp3k7enxv4jsxqzpuaztrnwngzn3kdzw5hydlzf03qdgm2hdq27cqv3agm2awhz5se903vruatfhq77w3ls4evs3ch9zw97j25emudupq63nyw24cg27h2rspfj9srp"
+)
+
+func TestLightning(t *testing.T) {
+	l := Lightning{Invoice: invoiceCoffee, URI: true}
+	got, err := l.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "LIGHTNING:" + strings.ToUpper(invoiceCoffee); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	parsed, err := ParseLightning(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != l {
+		t.Errorf("Parsed %#v, expected %#v", *parsed, l)
+	}
+
+	fields := []Field{{"Invoice", invoiceCoffee}, {"Network", "bitcoin"}, {"Amount", "250000 sat"}}
+	if got := parsed.Fields(); !reflect.DeepEqual(got, fields) {
+		t.Errorf("Fields %v, expected %v", got, fields)
+	}
+	p, err := Parse([]byte(invoiceDonation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Fields()[2].Value; got != "left to the payer" {
+		t.Errorf("Amount of an invoice without one is %q", got)
+	}
+}
+
+func TestLightningInvalid(t *testing.T) {
+	for s, want := range map[string]string{
+		invoiceCoffee[:len(invoiceCoffee)-1] + "q":                              "typo",
+		"lightning:" + invoiceCoffee[:20] + strings.ToUpper(invoiceCoffee[20:]): "case",
+		"lnbcxyz": "not bech32",
+	} {
+		if _, err := ParseLightning(s); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error about %s, got %v", s, want, err)
+		}
+	}
+}
+
+func TestParseInvoiceAmount(t *testing.T) {
+	for amount, want := range map[string]int64{"1": 100000000000, "2500u": 250000000, "10n": 1000, "10p": 1, "20m": 2000000000} {
+		if got, err := parseInvoiceAmount(amount); err != nil || got != want {
+			t.Errorf("parseInvoiceAmount(%q) = %d, %v, expected %d", amount, got, err, want)
+		}
+	}
+	for _, amount := range []string{"15p", "0u", "010m", "u", "21000001"} {
+		if _, err := parseInvoiceAmount(amount); err == nil {
+			t.Errorf("Expected an error for %q", amount)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package payload
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// Options are the settings shared by the helpers that need randomness:
+// the envelopes of this package and the pairing package. A nil *Options
+// is the default.
+//
+// Randomness is read from Options.Rand only, never straight from
+// crypto/rand, so tests can make the output deterministic and deployments
+// can draw it from a hardware security module.
+type Options struct {
+	// Rand is the source of randomness, crypto/rand.Reader if nil
+	Rand io.Reader
+}
+
+// Random returns the source of randomness of o
+func (o *Options) Random() io.Reader {
+	if o == nil || o.Rand == nil {
+		return rand.Reader
+	}
+	return o.Rand
+}
@@ -0,0 +1,71 @@
+package payload
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// countingReader is a deterministic source of randomness
+type countingReader byte
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(*c)
+		*c++
+	}
+	return len(p), nil
+}
+
+type failingReader struct{}
+
+var errNoEntropy = errors.New("no entropy")
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errNoEntropy
+}
+
+func TestEncryptWithOptions(t *testing.T) {
+	var r1, r2 countingReader
+	env1, _ := EncryptWithOptions([]byte("data"), []byte("pw"), &Options{Rand: &r1})
+	env2, _ := EncryptWithOptions([]byte("data"), []byte("pw"), &Options{Rand: &r2})
+	if !bytes.Equal(env1, env2) {
+		t.Error("Expected the same randomness to give the same envelope")
+	}
+	if _, err := EncryptWithOptions([]byte("data"), []byte("pw"), &Options{Rand: failingReader{}}); err != errNoEntropy {
+		t.Errorf("Expected the error of the source, got %v", err)
+	}
+}
+
+// TestRandomnessConvention keeps every helper of the module on
+// Options.Rand: only options.go may read crypto/rand
+func TestRandomnessConvention(t *testing.T) {
+	root := ".."
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		if rel, _ := filepath.Rel(root, path); rel == filepath.Join("payload", "options.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, imp := range f.Imports {
+			if p, _ := strconv.Unquote(imp.Path.Value); p == "crypto/rand" || p == "math/rand" {
+				t.Errorf("%s imports %s, read payload.Options.Random instead", path, p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,191 @@
+package payload
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OTP is a one-time password generator for an authenticator app, in the
+// otpauth: URI format of Google Authenticator
+type OTP struct {
+	// Kind is totp or hotp
+	Kind    string `json:"kind"`
+	Issuer  string `json:"issuer,omitempty"`
+	Account string `json:"account"`
+	// Secret is the shared key in base32
+	Secret Secret `json:"secret"`
+	// Algorithm is SHA1, SHA256 or SHA512, apps take SHA1 if empty
+	Algorithm string `json:"algorithm,omitempty"`
+	// Digits is the length of a password, apps take 6 if zero
+	Digits int `json:"digits,omitempty"`
+	// Period is how many seconds a totp password is valid, apps take 30
+	// if zero
+	Period int `json:"period,omitempty"`
+	// Counter is the first counter value of hotp
+	Counter uint64 `json:"counter,omitempty"`
+}
+
+// otpSecret is the encoding of OTP secrets, padding is optional
+var otpSecret = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Type returns TypeOTP
+func (o *OTP) Type() Type { return TypeOTP }
+
+// Payload checks o and returns its otpauth: URI
+func (o *OTP) Payload() (string, error) {
+	if o.Kind != "totp" && o.Kind != "hotp" {
+		return "", fmt.Errorf("payload: unknown OTP type %q, expected totp or hotp", o.Kind)
+	}
+	if o.Account == "" {
+		return "", errors.New("payload: OTP has no account")
+	}
+	if strings.Contains(o.Issuer, ":") {
+		return "", errors.New("payload: OTP issuer contains a colon")
+	}
+	if o.Issuer == "" && strings.Contains(o.Account, ":") {
+		return "", errors.New("payload: OTP account contains a colon, which would be read as the end of an issuer")
+	}
+	if _, err := otpSecret.DecodeString(strings.TrimRight(string(o.Secret), "=")); err != nil || len(o.Secret) == 0 {
+		return "", errors.New("payload: OTP secret is not base32")
+	}
+	switch o.Algorithm {
+	case "", "SHA1", "SHA256", "SHA512":
+	default:
+		return "", fmt.Errorf("payload: unknown OTP algorithm %q, expected SHA1, SHA256 or SHA512", o.Algorithm)
+	}
+	if o.Digits < 0 || o.Period < 0 {
+		return "", errors.New("payload: OTP digits and period can not be negative")
+	}
+
+	label := url.PathEscape(o.Account)
+	q := url.Values{"secret": {string(o.Secret)}}
+	if o.Issuer != "" {
+		label = url.PathEscape(o.Issuer) + ":" + label
+		q.Set("issuer", o.Issuer)
+	}
+	if o.Algorithm != "" {
+		q.Set("algorithm", o.Algorithm)
+	}
+	if o.Digits != 0 {
+		q.Set("digits", strconv.Itoa(o.Digits))
+	}
+	if o.Period != 0 {
+		q.Set("period", strconv.Itoa(o.Period))
+	}
+	if o.Kind == "hotp" {
+		q.Set("counter", strconv.FormatUint(o.Counter, 10))
+	}
+	return "otpauth://" + o.Kind + "/" + label + "?" + q.Encode(), nil
+}
+
+// Fields returns the issuer, account and secret of o, and the parameters
+// apps use, with their defaults
+func (o *OTP) Fields() []Field {
+	fields := []Field{{"Kind", strings.ToUpper(o.Kind)}}
+	if o.Issuer != "" {
+		fields = append(fields, Field{"Issuer", o.Issuer})
+	}
+	fields = append(fields, Field{"Account", o.Account}, Field{"Secret", string(o.Secret)})
+	algorithm, digits, period := o.Algorithm, o.Digits, o.Period
+	if algorithm == "" {
+		algorithm = "SHA1"
+	}
+	if digits == 0 {
+		digits = 6
+	}
+	if period == 0 {
+		period = 30
+	}
+	fields = append(fields, Field{"Algorithm", algorithm}, Field{"Digits", strconv.Itoa(digits)})
+	if o.Kind == "hotp" {
+		return append(fields, Field{"Counter", strconv.FormatUint(o.Counter, 10)})
+	}
+	return append(fields, Field{"Period", strconv.Itoa(period) + "s"})
+}
+
+// MarshalJSON writes Secret as text, where a Secret alone would be base64
+func (o *OTP) MarshalJSON() ([]byte, error) {
+	type plain OTP
+	return json.Marshal(struct {
+		*plain
+		Secret string `json:"secret"`
+	}{(*plain)(o), string(o.Secret)})
+}
+
+// UnmarshalJSON reads the text written by MarshalJSON
+func (o *OTP) UnmarshalJSON(b []byte) error {
+	type plain OTP
+	v := struct {
+		*plain
+		Secret string `json:"secret"`
+	}{plain: (*plain)(o)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	o.Secret = Secret(v.Secret)
+	return nil
+}
+
+// ParseOTP parses an otpauth: URI. The issuer is taken from the label, or
+// from the issuer parameter if the label has none.
+func ParseOTP(s string) (*OTP, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("payload: invalid otpauth URI: %v", parseError(err))
+	}
+	if !strings.EqualFold(u.Scheme, "otpauth") {
+		return nil, errors.New("payload: not an OTP, expected otpauth:")
+	}
+	o := &OTP{Kind: strings.ToLower(u.Host)}
+	if o.Kind != "totp" && o.Kind != "hotp" {
+		return nil, fmt.Errorf("payload: unknown OTP type %q, expected totp or hotp", u.Host)
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		o.Issuer, o.Account = issuer, account
+	} else {
+		o.Account = label
+	}
+
+	q := u.Query()
+	if o.Issuer == "" {
+		o.Issuer = q.Get("issuer")
+	}
+	o.Secret = Secret(q.Get("secret"))
+	if len(o.Secret) == 0 {
+		return nil, errors.New("payload: OTP has no secret")
+	}
+	o.Algorithm = strings.ToUpper(q.Get("algorithm"))
+	number := func(name string) (uint64, error) {
+		v := q.Get(name)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(v, 10, 63)
+		if err != nil {
+			return 0, fmt.Errorf("payload: OTP %s is not a number", name)
+		}
+		return n, nil
+	}
+	digits, err := number("digits")
+	if err != nil {
+		return nil, err
+	}
+	period, err := number("period")
+	if err != nil {
+		return nil, err
+	}
+	if o.Counter, err = number("counter"); err != nil {
+		return nil, err
+	}
+	if digits > 100 || period > 1<<31-1 {
+		return nil, errors.New("payload: OTP digits or period out of range")
+	}
+	o.Digits, o.Period = int(digits), int(period)
+	return o, nil
+}
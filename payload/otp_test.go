@@ -0,0 +1,86 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOTP(t *testing.T) {
+	testCases := []struct {
+		name string
+		otp  OTP
+		want string
+	}{
+		{"TOTP", OTP{Kind: "totp", Issuer: "ACME Co", Account: "alice@example.com", Secret: Secret("JBSWY3DPEHPK3PXP")},
+			"otpauth://totp/ACME%20Co:alice@example.com?issuer=ACME+Co&secret=JBSWY3DPEHPK3PXP"},
+		{"HOTP", OTP{Kind: "hotp", Account: "bob", Secret: Secret("JBSWY3DPEHPK3PXP"), Algorithm: "SHA256", Digits: 8},
+			"otpauth://hotp/bob?algorithm=SHA256&counter=0&digits=8&secret=JBSWY3DPEHPK3PXP"},
+		{"Period", OTP{Kind: "totp", Account: "carol", Secret: Secret("JBSWY3DPEHPK3PXP"), Period: 60},
+			"otpauth://totp/carol?period=60&secret=JBSWY3DPEHPK3PXP"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.otp.Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseOTP(got)
+			if err != nil {
+				t.Fatalf("ParseOTP failed: %v", err)
+			}
+			if !reflect.DeepEqual(*parsed, tc.otp) {
+				t.Errorf("Parsed %#v, expected %#v", *parsed, tc.otp)
+			}
+		})
+	}
+}
+
+func TestOTPInvalid(t *testing.T) {
+	for _, o := range []OTP{
+		{Kind: "motp", Account: "a", Secret: Secret("JBSWY3DP")},
+		{Kind: "totp", Secret: Secret("JBSWY3DP")},
+		{Kind: "totp", Account: "a", Secret: Secret("not base32!")},
+		{Kind: "totp", Account: "a:b", Secret: Secret("JBSWY3DP")},
+		{Kind: "totp", Issuer: "a:b", Account: "c", Secret: Secret("JBSWY3DP")},
+		{Kind: "totp", Account: "a", Secret: Secret("JBSWY3DP"), Algorithm: "MD5"},
+	} {
+		if _, err := o.Payload(); err == nil {
+			t.Errorf("Expected an error for %#v", o)
+		}
+	}
+}
+
+func TestParseOTPIssuerParameter(t *testing.T) {
+	o, err := ParseOTP("otpauth://totp/alice?secret=JBSWY3DP&issuer=Example&digits=x")
+	if err == nil {
+		t.Errorf("Expected an error for digits=x, got %#v", o)
+	}
+	o, err = ParseOTP("otpauth://TOTP/alice?secret=JBSWY3DP&issuer=Example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Kind != "totp" || o.Issuer != "Example" || o.Account != "alice" {
+		t.Errorf("Parsed %#v", o)
+	}
+}
+
+func TestOTPSecret(t *testing.T) {
+	o := &OTP{Kind: "totp", Account: "alice", Secret: Secret("JBSWY3DP")}
+	if s := fmt.Sprintf("%v %+v", o.Secret, o); strings.Contains(s, "JBSWY3DP") {
+		t.Errorf("Formatting leaked the secret: %s", s)
+	}
+	b, err := json.Marshal(o)
+	if err != nil || !strings.Contains(string(b), `"secret":"JBSWY3DP"`) {
+		t.Fatalf("Marshal gave %s, %v", b, err)
+	}
+	var got OTP
+	if err := json.Unmarshal(b, &got); err != nil || !reflect.DeepEqual(&got, o) {
+		t.Errorf("Unmarshal gave %#v, %v", got, err)
+	}
+}
@@ -0,0 +1,150 @@
+package payload
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Type is the type of payload Parse recognized
+type Type string
+
+// Types of payload Parse recognizes
+const (
+	TypeText  Type = "text"
+	TypeURL   Type = "url"
+	TypeWiFi  Type = "wifi"
+	TypeOTP   Type = "otpauth"
+	TypeVCard Type = "vcard"
+	TypeEPC   Type = "epc"
+	TypeGeo   Type = "geo"
+
+	TypeBitcoin   Type = "bitcoin"
+	TypeEthereum  Type = "ethereum"
+	TypeLightning Type = "lightning"
+
+	TypeDOI  Type = "doi"
+	TypeISBN Type = "isbn"
+	TypeEAN  Type = "ean"
+)
+
+// Field is a named value of a parsed payload, for people to read
+type Field struct {
+	Name, Value string
+}
+
+// Parsed is a payload that Parse recognized. Payload builds the data of a
+// code from it again, so every type is both the builder and the result of
+// its parser.
+type Parsed interface {
+	Type() Type
+	Payload() (string, error)
+	Fields() []Field
+}
+
+// Parse recognizes the type of data, e.g. the text a scanner read, and
+// parses it. Data that starts like a known type but does not parse is an
+// error. Absolute URLs of other schemes are a *Link, anything else is
+// *Text.
+func Parse(data []byte) (Parsed, error) {
+	s := string(data)
+	var p Parsed
+	var err error
+	switch {
+	case hasPrefixFold(s, wifiPrefix):
+		p, err = parseAs(ParseWiFi(s))
+	case hasPrefixFold(s, "otpauth:"):
+		p, err = parseAs(ParseOTP(s))
+	case hasPrefixFold(s, "BEGIN:VCARD"):
+		p, err = parseAs(ParseVCard(s))
+	case strings.HasPrefix(s, "BCD\n") || strings.HasPrefix(s, "BCD\r\n"):
+		p, err = parseAs(ParseEPC(s))
+	case hasPrefixFold(s, "geo:"):
+		p, err = parseAs(ParseGeo(s))
+	case hasPrefixFold(s, "bitcoin:"):
+		p, err = parseAs(ParseBitcoin(s))
+	case hasPrefixFold(s, "ethereum:"):
+		p, err = parseAs(ParseEthereum(s))
+	case hasPrefixFold(s, lightningScheme) || isLightningInvoice(s):
+		p, err = parseAs(ParseLightning(s))
+	case isDOI(s):
+		p, err = parseAs(ParseDOI(s))
+	case hasPrefixFold(s, isbnResolver) || hasPrefixFold(s, "urn:isbn:"):
+		p, err = parseAs(ParseISBN(s))
+	case hasPrefixFold(s, gs1Resolver):
+		p, err = parseAs(ParseEAN(s))
+	default:
+		link := strings.TrimSpace(s)
+		if u, err := url.Parse(link); err == nil && u.Scheme != "" && u.Host != "" {
+			return &Link{URL: link}, nil
+		}
+		return &Text{Text: s}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseAs returns the result of a parser as a Parsed, nil on error rather
+// than a nil pointer in an interface
+func parseAs[T Parsed](p T, err error) (Parsed, error) {
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// Text is a payload of no known type
+type Text struct {
+	Text string `json:"text"`
+}
+
+// Type returns TypeText
+func (t *Text) Type() Type { return TypeText }
+
+// Payload returns the text
+func (t *Text) Payload() (string, error) { return t.Text, nil }
+
+// Fields returns the text
+func (t *Text) Fields() []Field { return []Field{{"Text", t.Text}} }
+
+// Link is an absolute URL, of any scheme. Use URL to check and normalize
+// the URLs of new codes.
+type Link struct {
+	URL string `json:"url"`
+}
+
+// Type returns TypeURL
+func (l *Link) Type() Type { return TypeURL }
+
+// Payload returns the URL
+func (l *Link) Payload() (string, error) {
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return "", fmt.Errorf("payload: invalid URL: %v", parseError(err))
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("payload: URL (%s) is not absolute", Redact([]byte(l.URL)))
+	}
+	return l.URL, nil
+}
+
+// Fields returns the URL, its scheme and host, and warnings about hosts a
+// scanning device will most likely not reach
+func (l *Link) Fields() []Field {
+	fields := []Field{{"URL", l.URL}}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return fields
+	}
+	fields = append(fields, Field{"Scheme", u.Scheme}, Field{"Host", u.Host})
+	for _, w := range hostWarnings(u.Hostname()) {
+		fields = append(fields, Field{"Warning", w})
+	}
+	return fields
+}
@@ -0,0 +1,59 @@
+package payload
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		data string
+		want Parsed
+	}{
+		{"hello world", &Text{Text: "hello world"}},
+		{"example.com", &Text{Text: "example.com"}},
+		{"https://example.com/a?b=c", &Link{URL: "https://example.com/a?b=c"}},
+		{"ftp://example.com/file", &Link{URL: "ftp://example.com/file"}},
+		{"WIFI:T:WPA;S:home;P:secret;;", &WiFi{SSID: "home", Security: "WPA", Password: Secret("secret")}},
+		{"wifi:S:cafe;;", &WiFi{SSID: "cafe"}},
+		{"otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			&OTP{Kind: "totp", Issuer: "Example", Account: "alice", Secret: Secret("JBSWY3DPEHPK3PXP")}},
+		{"BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nEND:VCARD", &VCard{Name: "Alice"}},
+		{"BCD\n002\n1\nSCT\n\nRed Cross\nDE89370400440532013000\nEUR10", &EPC{Name: "Red Cross", IBAN: "DE89370400440532013000", Cents: 1000}},
+		{"geo:52.52,13.405", &Geo{Lat: 52.52, Lon: 13.405}},
+	}
+	for _, tc := range testCases {
+		got, err := Parse([]byte(tc.data))
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tc.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Parse(%q) = %#v, expected %#v", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	// Payloads that look like a known type but do not parse are errors,
+	// not text
+	for _, data := range []string{
+		"WIFI:T:WPA;P:secret;;",
+		"otpauth://totp/alice",
+		"BEGIN:VCARD\nFN:Alice\n",
+		"BCD\n002\n1\nSCT\n\nRed Cross\nDE00370400440532013000",
+		"geo:91,0",
+	} {
+		if p, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) = %#v, expected an error", data, p)
+		}
+	}
+}
+
+func TestLinkFields(t *testing.T) {
+	l := &Link{URL: "http://localhost:8080/"}
+	fields := l.Fields()
+	if last := fields[len(fields)-1]; last.Name != "Warning" {
+		t.Errorf("Expected a warning for localhost, got %v", fields)
+	}
+}
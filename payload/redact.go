@@ -0,0 +1,27 @@
+package payload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Redact describes data for logs and error messages without revealing it:
+// its length and the start of its SHA-256 hash, which is enough to tell
+// payloads apart
+func Redact(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%d bytes, sha256 %s", len(data), hex.EncodeToString(sum[:8]))
+}
+
+// parseError returns the reason url.Parse failed without the URL, which
+// may hold a secret such as a token
+func parseError(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return uerr.Err
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	if got, want := Redact([]byte("hello")), "5 bytes, sha256 2cf24dba5fb0a30e"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestErrorsRedactPayload(t *testing.T) {
+	const secret = "tok3n"
+	var errs []error
+	for _, raw := range []string{"example.com/?t=" + secret, "https:///?t=" + secret, "https://x/%zz?t=" + secret} {
+		_, _, err := URL(raw, nil)
+		errs = append(errs, err)
+	}
+	for _, link := range [][2]string{
+		{"https://example.com/?t=" + secret, "https://example.com"},
+		{"app://pair?t=" + secret, "http://example.com/?t=" + secret},
+		{"app://pair/%zz?t=" + secret, "https://example.com"},
+	} {
+		_, err := DeepLink(link[0], link[1])
+		errs = append(errs, err)
+	}
+	for _, err := range errs {
+		if err == nil {
+			t.Error("Expected an error")
+		} else if strings.Contains(err.Error(), secret) {
+			t.Errorf("Payload leaked into %q", err)
+		}
+	}
+}
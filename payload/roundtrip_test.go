@@ -0,0 +1,436 @@
+package payload
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode"
+)
+
+// roundTripConfig runs every property on enough values to hit the rare
+// characters of randomText
+var roundTripConfig = &quick.Config{MaxCount: 2000}
+
+// randomRunes are the characters of randomText, heavy on the ones that
+// have a meaning in some payload format
+var randomRunes = []rune("abcXYZ019 \t\n\r\\;,:\"'=&?/%#+@.-_~()[]{}<>äß漢😀 ")
+
+// randomText returns up to n characters of randomRunes, at least min
+func randomText(r *rand.Rand, min, n int) string {
+	var b strings.Builder
+	for i := min + r.Intn(n-min+1); i > 0; i-- {
+		b.WriteRune(randomRunes[r.Intn(len(randomRunes))])
+	}
+	return b.String()
+}
+
+// randomLine is randomText without line breaks
+func randomLine(r *rand.Rand, min, n int) string {
+	for {
+		if s := randomText(r, min, n); !strings.ContainsAny(s, "\r\n") {
+			return s
+		}
+	}
+}
+
+func pick(r *rand.Rand, choices ...string) string {
+	return choices[r.Intn(len(choices))]
+}
+
+// roundTrip checks that p parses back to itself, with its own parser and
+// with Parse
+func roundTrip(t *testing.T, p Parsed, parse func(string) (Parsed, error)) bool {
+	t.Helper()
+	data, err := p.Payload()
+	if err != nil {
+		t.Errorf("Payload of %#v failed: %v", p, err)
+		return false
+	}
+	for _, parse := range []func(string) (Parsed, error){parse, func(s string) (Parsed, error) { return Parse([]byte(s)) }} {
+		got, err := parse(data)
+		if err != nil {
+			t.Errorf("Parsing %q failed: %v", data, err)
+			return false
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("%q parsed as %#v, expected %#v", data, got, p)
+			return false
+		}
+	}
+	return true
+}
+
+type randomWiFi struct{ *WiFi }
+
+func (randomWiFi) Generate(r *rand.Rand, size int) reflect.Value {
+	w := &WiFi{SSID: randomText(r, 1, 32), Hidden: r.Intn(2) == 0}
+	if w.Security = pick(r, "", "WPA", "WEP", "SAE"); w.Security != "" {
+		w.Password = Secret(randomText(r, 1, 63))
+	}
+	return reflect.ValueOf(randomWiFi{w})
+}
+
+func TestWiFiRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseWiFi(s)) }
+	if err := quick.Check(func(w randomWiFi) bool { return roundTrip(t, w.WiFi, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWiFiCompatRoundTrip(t *testing.T) {
+	// The quirks of every scanner set read back as the same network, but
+	// for SAE, which iOS knows as WPA
+	prop := func(w randomWiFi, compat uint8) bool {
+		w.Compat = Compat(compat) & (CompatAndroid | CompatIOS)
+		data, err := w.Payload()
+		if err != nil {
+			both := w.Compat == CompatAndroid|CompatIOS && (isHexDigits(w.SSID) || isHexDigits(string(w.Password)))
+			if !both {
+				t.Errorf("Payload of %#v failed: %v", w.WiFi, err)
+			}
+			return both
+		}
+		want := *w.WiFi
+		want.Compat = 0
+		if want.Security == "SAE" && w.Compat&CompatIOS != 0 {
+			want.Security = "WPA"
+		}
+		got, err := ParseWiFi(data)
+		if err != nil || !reflect.DeepEqual(*got, want) {
+			t.Errorf("%q parsed as %#v, %v, expected %#v", data, got, err, want)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(prop, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+type randomOTP struct{ *OTP }
+
+func (randomOTP) Generate(r *rand.Rand, size int) reflect.Value {
+	secret := make([]byte, 10+r.Intn(22))
+	r.Read(secret)
+	o := &OTP{
+		Kind:      pick(r, "totp", "hotp"),
+		Issuer:    strings.ReplaceAll(randomText(r, 0, 20), ":", ""),
+		Account:   randomText(r, 1, 30),
+		Secret:    Secret(otpSecret.EncodeToString(secret)),
+		Algorithm: pick(r, "", "SHA1", "SHA256", "SHA512"),
+		Digits:    []int{0, 6, 8}[r.Intn(3)],
+	}
+	if o.Issuer == "" {
+		o.Account = strings.ReplaceAll(o.Account, ":", "")
+		if o.Account == "" {
+			o.Account = "a"
+		}
+	}
+	if o.Kind == "hotp" {
+		o.Counter = r.Uint64() >> 1
+	} else {
+		o.Period = []int{0, 30, 60}[r.Intn(3)]
+	}
+	return reflect.ValueOf(randomOTP{o})
+}
+
+func TestOTPRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseOTP(s)) }
+	if err := quick.Check(func(o randomOTP) bool { return roundTrip(t, o.OTP, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+type randomVCard struct{ *VCard }
+
+func (randomVCard) Generate(r *rand.Rand, size int) reflect.Value {
+	v := &VCard{
+		Name:       randomText(r, 1, 30),
+		FamilyName: randomText(r, 0, 15),
+		GivenName:  randomText(r, 0, 15),
+		Org:        randomText(r, 0, 20),
+		Title:      randomText(r, 0, 20),
+		URL:        randomText(r, 0, 30),
+		Note:       randomText(r, 0, 60),
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		v.Phones = append(v.Phones, randomText(r, 0, 15))
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		v.Emails = append(v.Emails, randomText(r, 0, 25))
+	}
+	return reflect.ValueOf(randomVCard{v})
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseVCard(s)) }
+	if err := quick.Check(func(v randomVCard) bool { return roundTrip(t, v.VCard, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+// randomIBAN returns a German IBAN with random account digits and the
+// check digits that make it valid
+func randomIBAN(r *rand.Rand) string {
+	bban := fmt.Sprintf("%018d", r.Int63n(1e18))
+	// DE is 1314 as digits, moved behind the account with check digits 00
+	n, _ := new(big.Int).SetString(bban+"131400", 10)
+	check := 98 - new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("DE%02d%s", check, bban)
+}
+
+type randomEPC struct{ *EPC }
+
+func (randomEPC) Generate(r *rand.Rand, size int) reflect.Value {
+	e := &EPC{
+		BIC:     pick(r, "", "COBADEFF", "BFSWDE33BER"),
+		Name:    randomLine(r, 1, 70),
+		IBAN:    randomIBAN(r),
+		Cents:   []int64{0, 1, 99, 100, r.Int63n(epcMaxCents + 1), epcMaxCents}[r.Intn(6)],
+		Purpose: pick(r, "", "CHAR", "GDDS"),
+		Info:    randomLine(r, 0, 20),
+	}
+	if r.Intn(2) == 0 {
+		e.Reference = randomLine(r, 0, 35)
+	} else {
+		e.Text = randomLine(r, 0, 40)
+	}
+	// Wide characters can take a payment past epcMaxSize bytes
+	for _, err := e.Payload(); err != nil && strings.Contains(err.Error(), "bytes"); _, err = e.Payload() {
+		name := []rune(e.Name)
+		e.Name = string(name[:(len(name)+1)/2])
+		e.Info = ""
+	}
+	return reflect.ValueOf(randomEPC{e})
+}
+
+func TestEPCRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseEPC(s)) }
+	if err := quick.Check(func(e randomEPC) bool { return roundTrip(t, e.EPC, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIBANCheckDigits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if iban := randomIBAN(r); checkIBAN(iban) != nil {
+			t.Fatalf("Generated an invalid IBAN %s", iban)
+		}
+	}
+}
+
+type randomGeo struct{ *Geo }
+
+func (randomGeo) Generate(r *rand.Rand, size int) reflect.Value {
+	g := &Geo{Lat: r.Float64()*180 - 90, Lon: r.Float64()*360 - 180}
+	switch r.Intn(4) {
+	case 0:
+		g.Lat, g.Lon = 90, -180
+	case 1:
+		// Coordinates as people type them
+		g.Lat, g.Lon = float64(r.Intn(18001)-9000)/100, float64(r.Intn(36001)-18000)/100
+	}
+	return reflect.ValueOf(randomGeo{g})
+}
+
+func TestGeoRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseGeo(s)) }
+	if err := quick.Check(func(g randomGeo) bool { return roundTrip(t, g.Geo, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	// Text that starts like another type is that type, anything else
+	// parses back as it was
+	prop := func(s string) bool {
+		p, err := Parse([]byte(s))
+		if err != nil {
+			return true
+		}
+		data, _ := p.Payload()
+		switch p := p.(type) {
+		case *Text:
+			return data == s && p.Text == s
+		case *Link:
+			return data == strings.TrimSpace(s)
+		}
+		return true
+	}
+	if err := quick.Check(prop, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+// randomBitcoinAddress returns a valid legacy or segwit address
+func randomBitcoinAddress(r *rand.Rand) string {
+	hash := make([]byte, 20+12*r.Intn(2))
+	r.Read(hash)
+	switch r.Intn(3) {
+	case 0:
+		return encodeBase58Check([]byte{0x00, 0x05, 0x6f, 0xc4}[r.Intn(4)], hash[:20])
+	case 1:
+		program := toBase32(hash)
+		return encodeBech32(pick(r, "bc", "tb", "bcrt"), append([]byte{0}, program...), bech32Const)
+	}
+	program := toBase32(hash[:20])
+	return strings.ToUpper(encodeBech32("bc", append([]byte{byte(1 + r.Intn(16))}, program...), bech32mConst))
+}
+
+type randomBitcoin struct{ *Bitcoin }
+
+func (randomBitcoin) Generate(r *rand.Rand, size int) reflect.Value {
+	b := &Bitcoin{Address: randomBitcoinAddress(r), Label: randomText(r, 0, 20), Message: randomText(r, 0, 40)}
+	switch r.Intn(3) {
+	case 0:
+		b.Amount = fmt.Sprint(r.Intn(maxBTC))
+	case 1:
+		b.Amount = fmt.Sprintf("%d.%0*d", r.Intn(1000), 1+r.Intn(8), 0)
+		b.Amount = b.Amount[:len(b.Amount)-1] + fmt.Sprint(r.Intn(10))
+	}
+	return reflect.ValueOf(randomBitcoin{b})
+}
+
+func TestBitcoinRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseBitcoin(s)) }
+	if err := quick.Check(func(b randomBitcoin) bool { return roundTrip(t, b.Bitcoin, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+// randomEthereumAddress returns an address in lower case
+func randomEthereumAddress(r *rand.Rand) string {
+	b := make([]byte, 20)
+	r.Read(b)
+	return fmt.Sprintf("0x%x", b)
+}
+
+type randomEthereum struct{ *Ethereum }
+
+func (randomEthereum) Generate(r *rand.Rand, size int) reflect.Value {
+	e := &Ethereum{Address: randomEthereumAddress(r), ChainID: []uint64{0, 1, 137, r.Uint64()}[r.Intn(4)]}
+	if r.Intn(2) == 0 {
+		e.Token = randomEthereumAddress(r)
+	}
+	if r.Intn(3) > 0 {
+		e.Value = new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), 256)).String()
+	}
+	return reflect.ValueOf(randomEthereum{e})
+}
+
+func TestEthereumRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseEthereum(s)) }
+	if err := quick.Check(func(e randomEthereum) bool { return roundTrip(t, e.Ethereum, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+type randomLightning struct{ *Lightning }
+
+func (randomLightning) Generate(r *rand.Rand, size int) reflect.Value {
+	hrp := "ln" + pick(r, "bc", "tb", "tbs", "bcrt")
+	if r.Intn(2) == 0 {
+		hrp += fmt.Sprint(1+r.Intn(999)) + pick(r, "", "m", "u", "n", "0p")
+	}
+	data := make([]byte, 100+r.Intn(300))
+	for i := range data {
+		data[i] = byte(r.Intn(32))
+	}
+	l := &Lightning{Invoice: encodeBech32(hrp, data, bech32Const), URI: r.Intn(2) == 0}
+	return reflect.ValueOf(randomLightning{l})
+}
+
+func TestLightningRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseLightning(s)) }
+	if err := quick.Check(func(l randomLightning) bool { return roundTrip(t, l.Lightning, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+type randomDOI struct{ *DOI }
+
+func (randomDOI) Generate(r *rand.Rand, size int) reflect.Value {
+	prefix := fmt.Sprintf("10.%d", 1000+r.Intn(99000))
+	if r.Intn(4) == 0 {
+		prefix += fmt.Sprintf(".%d", r.Intn(100))
+	}
+	suffix := strings.Map(func(c rune) rune {
+		if unicode.IsSpace(c) {
+			return '_'
+		}
+		return c
+	}, randomText(r, 1, 40))
+	return reflect.ValueOf(randomDOI{&DOI{DOI: prefix + "/" + suffix}})
+}
+
+func TestDOIRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseDOI(s)) }
+	if err := quick.Check(func(d randomDOI) bool { return roundTrip(t, d.DOI, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+// randomDigits returns prefix followed by random digits and a check digit,
+// n digits in all
+func randomDigits(r *rand.Rand, prefix string, n int) string {
+	b := []byte(prefix)
+	for len(b) < n-1 {
+		b = append(b, byte('0'+r.Intn(10)))
+	}
+	return string(b) + string(gtinCheckDigit(string(b)))
+}
+
+type randomISBN struct{ *ISBN }
+
+func (randomISBN) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomISBN{&ISBN{ISBN: randomDigits(r, pick(r, "978", "979"), 13)}})
+}
+
+func TestISBNRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseISBN(s)) }
+	if err := quick.Check(func(i randomISBN) bool { return roundTrip(t, i.ISBN, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+type randomEAN struct{ *EAN }
+
+func (randomEAN) Generate(r *rand.Rand, size int) reflect.Value {
+	// Links give the 13 digits of an EAN-13 back, a GTIN-14 only without
+	// a leading zero
+	code := randomDigits(r, "", 13)
+	if r.Intn(4) == 0 {
+		code = randomDigits(r, string(rune('1'+r.Intn(9))), 14)
+	}
+	return reflect.ValueOf(randomEAN{&EAN{Code: code}})
+}
+
+func TestEANRoundTrip(t *testing.T) {
+	parse := func(s string) (Parsed, error) { return parseAs(ParseEAN(s)) }
+	if err := quick.Check(func(e randomEAN) bool { return roundTrip(t, e.EAN, parse) }, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestISBNCheckDigits(t *testing.T) {
+	// Every ISBN-10 converts to an ISBN-13 whose ISBN-10 is the same
+	prop := func(n uint32) bool {
+		body := fmt.Sprintf("%09d", n%1000000000)
+		isbn10 := body + string(isbn10CheckDigit(body))
+		isbn13, err := normalizeISBN(isbn10)
+		if err != nil {
+			t.Errorf("%s: %v", isbn10, err)
+			return false
+		}
+		fields := (&ISBN{ISBN: isbn13}).Fields()
+		return fields[1] == Field{"ISBN-10", isbn10}
+	}
+	if err := quick.Check(prop, roundTripConfig); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,8 @@
+package payload
+
+import "github.com/katzenpost/qrterminal/v3/secret"
+
+// Secret holds secret payload bytes, such as an OTP seed, a WiFi password
+// or a private key. It is secret.Bytes, which formats as a placeholder
+// with every fmt verb and which Zero wipes.
+type Secret = secret.Bytes
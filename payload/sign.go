@@ -0,0 +1,68 @@
+package payload
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+// ErrSignature is returned when a signed envelope does not verify
+var ErrSignature = errors.New("payload: invalid signature")
+
+// Sign wraps data in an envelope carrying an Ed25519 signature made with
+// priv, attached so that one code holds both. The envelope layout is:
+//
+//	version | 'S' | data | signature (64 bytes)
+//
+// The signature covers the header and data. It is the one SignDetached
+// returns for data.
+func Sign(data []byte, priv ed25519.PrivateKey) []byte {
+	env := make([]byte, 0, headerSize+len(data)+ed25519.SignatureSize)
+	env = append(env, Version, byte(KindSigned))
+	env = append(env, data...)
+	return append(env, ed25519.Sign(priv, env)...)
+}
+
+// SignDetached returns the Ed25519 signature of data made with priv, for
+// data that is sent unchanged, e.g. in one code with the signature in
+// another. Like the signature of Sign it covers the envelope header, so
+// it can not be taken for a signature of data made for another purpose.
+func SignDetached(data []byte, priv ed25519.PrivateKey) []byte {
+	return ed25519.Sign(priv, signedMessage(data))
+}
+
+// VerifyDetached checks a signature of data made by SignDetached against
+// pub
+func VerifyDetached(data, sig []byte, pub ed25519.PublicKey) error {
+	if len(sig) != ed25519.SignatureSize {
+		return ErrMalformed
+	}
+	if !ed25519.Verify(pub, signedMessage(data), sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// signedMessage returns the envelope header and data, which the signature
+// covers
+func signedMessage(data []byte) []byte {
+	msg := make([]byte, 0, headerSize+len(data))
+	msg = append(msg, Version, byte(KindSigned))
+	return append(msg, data...)
+}
+
+// Verify checks the signature of an envelope created by Sign against pub
+// and returns the signed data
+func Verify(env []byte, pub ed25519.PublicKey) ([]byte, error) {
+	body, err := open(env, KindSigned)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, ErrMalformed
+	}
+	signed := env[:len(env)-ed25519.SignatureSize]
+	if !ed25519.Verify(pub, signed, env[len(signed):]) {
+		return nil, ErrSignature
+	}
+	return body[:len(body)-ed25519.SignatureSize], nil
+}
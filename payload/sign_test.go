@@ -0,0 +1,86 @@
+package payload
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	data := []byte("https://provisioning.example/enroll?token=abc")
+
+	env := Sign(data, priv)
+	if kind, err := KindOf(env); err != nil || kind != KindSigned {
+		t.Errorf("Expected kind %q, got %q (%v)", KindSigned, kind, err)
+	}
+	if len(env) != headerSize+len(data)+ed25519.SignatureSize {
+		t.Errorf("Unexpected envelope length %d", len(env))
+	}
+
+	got, err := Verify(env, pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	env := Sign([]byte("payload"), priv)
+
+	if _, err := Verify(env, otherPub); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected ErrSignature for the wrong key, got %v", err)
+	}
+
+	tampered := append([]byte(nil), env...)
+	tampered[headerSize] ^= 0x01
+	if _, err := Verify(tampered, pub); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected ErrSignature for tampered data, got %v", err)
+	}
+
+	if _, err := Verify(env[:headerSize+10], pub); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected ErrMalformed for a truncated envelope, got %v", err)
+	}
+
+	encrypted, _ := Encrypt([]byte("payload"), []byte("pw"))
+	if _, err := Verify(encrypted, pub); err == nil {
+		t.Errorf("Expected an error when verifying an encrypted envelope")
+	}
+}
+
+func TestSignDetached(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	data := []byte("https://provisioning.example/enroll?token=abc")
+
+	sig := SignDetached(data, priv)
+	if err := VerifyDetached(data, sig, pub); err != nil {
+		t.Fatalf("VerifyDetached failed: %v", err)
+	}
+	// The attached form carries the same signature
+	if env := Sign(data, priv); !bytes.Equal(env[len(env)-ed25519.SignatureSize:], sig) {
+		t.Error("Expected Sign to end in the detached signature")
+	}
+
+	if err := VerifyDetached(data, sig, otherPub); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected ErrSignature for the wrong key, got %v", err)
+	}
+	if err := VerifyDetached(data[1:], sig, pub); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected ErrSignature for tampered data, got %v", err)
+	}
+	if err := VerifyDetached(data, sig[:10], pub); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected ErrMalformed for a short signature, got %v", err)
+	}
+	// A plain Ed25519 signature of data is not accepted
+	if err := VerifyDetached(data, ed25519.Sign(priv, data), pub); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected ErrSignature for a signature without the header, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package payload
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URLOptions tune the checks and normalization of URL
+type URLOptions struct {
+	// Schemes lists the schemes accepted, http and https if empty
+	Schemes []string
+	// Uppercase writes the scheme and host in upper case, which they are
+	// not sensitive to. A URL made up only of upper case letters, digits
+	// and " $%*+-./:" is encoded in the alphanumeric mode of QR codes, which
+	// takes 5.5 bits per character instead of 8, so this only shortens the
+	// symbol if the path and query are upper case as well, e.g. a token.
+	Uppercase bool
+}
+
+// URL checks that raw is an absolute URL with an accepted scheme and a
+// host, and returns it normalized for a QR code: the scheme and host are
+// in lower case, or upper case with URLOptions.Uppercase, and characters
+// not allowed in a URL are percent-encoded. The warnings returned point out
+// hosts that a phone scanning the code will most likely not reach, such as
+// localhost and private network addresses. opts may be nil.
+func URL(raw string, opts *URLOptions) (string, []string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", nil, fmt.Errorf("payload: invalid URL: %v", parseError(err))
+	}
+	schemes := []string{"http", "https"}
+	if opts != nil && len(opts.Schemes) > 0 {
+		schemes = opts.Schemes
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !contains(schemes, scheme) {
+		if u.Scheme == "" {
+			return "", nil, fmt.Errorf("payload: URL (%s) has no scheme, expected one of %s", Redact([]byte(raw)), strings.Join(schemes, ", "))
+		}
+		return "", nil, fmt.Errorf("payload: URL scheme %q is not one of %s", u.Scheme, strings.Join(schemes, ", "))
+	}
+	if u.Host == "" || u.Hostname() == "" {
+		return "", nil, fmt.Errorf("payload: URL (%s) has no host", Redact([]byte(raw)))
+	}
+
+	u.Scheme, u.Host = scheme, strings.ToLower(u.Host)
+	u.RawQuery = escapeQuery(u.RawQuery)
+	normalized := u.String()
+	// The scheme and host are ASCII after parsing, only user info could
+	// come between them
+	if prefix := u.Scheme + "://" + u.Host; opts != nil && opts.Uppercase && strings.HasPrefix(normalized, prefix) {
+		normalized = strings.ToUpper(prefix) + normalized[len(prefix):]
+	}
+	return normalized, hostWarnings(u.Hostname()), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeQuery percent-encodes the characters of a raw query that RFC 3986
+// section 3.4 does not allow, keeping existing escapes and separators
+func escapeQuery(q string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			strings.IndexByte("-._~!$&'()*+,;=:@/?", c) >= 0:
+			b.WriteByte(c)
+		case c == '%' && i+2 < len(q) && isHex(q[i+1]) && isHex(q[i+2]):
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// hostWarnings reports hosts that are only reachable from this machine or
+// its local network
+func hostWarnings(host string) []string {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return []string{fmt.Sprintf("host %s is this machine, a scanning device will not reach it", host)}
+	}
+	if strings.HasSuffix(host, ".local") {
+		return []string{fmt.Sprintf("host %s is a local network name, only devices on the same network can resolve it", host)}
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return nil
+	case ip.IsLoopback(), ip.IsUnspecified():
+		return []string{fmt.Sprintf("address %s is this machine, a scanning device will not reach it", host)}
+	case ip.IsPrivate(), ip.IsLinkLocalUnicast():
+		return []string{fmt.Sprintf("address %s is private, only devices on the same network can reach it", host)}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+		opts *URLOptions
+		want string
+	}{
+		{"Unchanged", "https://example.com/a?b=c", nil, "https://example.com/a?b=c"},
+		{"LowerCase", "HTTPS://Example.COM/Path", nil, "https://example.com/Path"},
+		{"Spaces", " https://example.com/a b?q=x y ", nil, "https://example.com/a%20b?q=x%20y"},
+		{"Unicode", "https://example.com/ä?x=ü", nil, "https://example.com/%C3%A4?x=%C3%BC"},
+		{"KeepEscapes", "https://example.com/?q=a%2Fb&r=%zz", nil, "https://example.com/?q=a%2Fb&r=%25zz"},
+		{"Uppercase", "https://example.com/T0K3N", &URLOptions{Uppercase: true}, "HTTPS://EXAMPLE.COM/T0K3N"},
+		{"UppercasePort", "http://example.com:8080/x", &URLOptions{Uppercase: true}, "HTTP://EXAMPLE.COM:8080/x"},
+		{"Schemes", "ftp://example.com/file", &URLOptions{Schemes: []string{"ftp"}}, "ftp://example.com/file"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warnings, err := URL(tc.raw, tc.opts)
+			if err != nil {
+				t.Fatalf("URL failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("Expected no warnings, got %q", warnings)
+			}
+		})
+	}
+}
+
+func TestURLInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"example.com/path",
+		"ftp://example.com/file",
+		"javascript:alert(1)",
+		"https:///path",
+		"https://exa mple.com/",
+	} {
+		if got, _, err := URL(raw, nil); err == nil {
+			t.Errorf("Expected %q to be rejected, got %q", raw, got)
+		}
+	}
+}
+
+func TestURLWarnings(t *testing.T) {
+	testCases := []struct {
+		raw  string
+		warn string
+	}{
+		{"http://localhost:8080/", "this machine"},
+		{"http://app.localhost/", "this machine"},
+		{"http://127.0.0.1/", "this machine"},
+		{"http://[::1]/", "this machine"},
+		{"http://printer.local/", "same network"},
+		{"http://192.168.1.10/", "same network"},
+		{"http://[fd00::1]/", "same network"},
+		{"https://93.184.215.14/", ""},
+	}
+	for _, tc := range testCases {
+		_, warnings, err := URL(tc.raw, nil)
+		if err != nil {
+			t.Fatalf("URL(%q) failed: %v", tc.raw, err)
+		}
+		if tc.warn == "" {
+			if len(warnings) != 0 {
+				t.Errorf("Expected no warnings for %q, got %q", tc.raw, warnings)
+			}
+			continue
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], tc.warn) {
+			t.Errorf("Expected a warning about %q for %q, got %q", tc.warn, tc.raw, warnings)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+)
+
+// VCard is a contact, written as a vCard 3.0
+type VCard struct {
+	// Name is the full name as it is shown
+	Name       string   `json:"name"`
+	FamilyName string   `json:"family_name,omitempty"`
+	GivenName  string   `json:"given_name,omitempty"`
+	Org        string   `json:"org,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Phones     []string `json:"phones,omitempty"`
+	Emails     []string `json:"emails,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Note       string   `json:"note,omitempty"`
+}
+
+// Type returns TypeVCard
+func (v *VCard) Type() Type { return TypeVCard }
+
+// Payload checks v and returns it as a vCard, with the special characters
+// of its values escaped
+func (v *VCard) Payload() (string, error) {
+	if v.Name == "" {
+		return "", errors.New("payload: vCard has no name")
+	}
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(name + ":" + value + "\r\n")
+	}
+	line("BEGIN", "VCARD")
+	line("VERSION", "3.0")
+	line("N", escapeVCard(v.FamilyName)+";"+escapeVCard(v.GivenName)+";;;")
+	line("FN", escapeVCard(v.Name))
+	optional := func(name, value string) {
+		if value != "" {
+			line(name, escapeVCard(value))
+		}
+	}
+	optional("ORG", v.Org)
+	optional("TITLE", v.Title)
+	for _, p := range v.Phones {
+		line("TEL", escapeVCard(p))
+	}
+	for _, e := range v.Emails {
+		line("EMAIL", escapeVCard(e))
+	}
+	optional("URL", v.URL)
+	optional("NOTE", v.Note)
+	line("END", "VCARD")
+	return b.String(), nil
+}
+
+// Fields returns the name, organization and ways to reach the contact
+func (v *VCard) Fields() []Field {
+	fields := []Field{{"Name", v.Name}}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, Field{name, value})
+		}
+	}
+	add("Family name", v.FamilyName)
+	add("Given name", v.GivenName)
+	add("Organization", v.Org)
+	add("Title", v.Title)
+	for _, p := range v.Phones {
+		add("Phone", p)
+	}
+	for _, e := range v.Emails {
+		add("Email", e)
+	}
+	add("URL", v.URL)
+	add("Note", v.Note)
+	return fields
+}
+
+// ParseVCard parses a vCard of any version. Properties it does not know
+// and parameters, such as the TYPE of a phone number, are ignored.
+func ParseVCard(s string) (*VCard, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	// Lines starting with a space or tab continue the previous one
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\n ", ""), "\n\t", "")
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCARD") {
+		return nil, errors.New("payload: not a vCard, expected BEGIN:VCARD")
+	}
+	v := &VCard{}
+	ended := false
+	for _, l := range lines[1:] {
+		name, value, ok := strings.Cut(l, ":")
+		if !ok {
+			continue
+		}
+		// Drop the parameters and the group of the property
+		name, _, _ = strings.Cut(name, ";")
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+		switch strings.ToUpper(name) {
+		case "END":
+			ended = true
+		case "N":
+			parts := splitEscaped(value, ';')
+			v.FamilyName = unescapeVCard(parts[0])
+			if len(parts) > 1 {
+				v.GivenName = unescapeVCard(parts[1])
+			}
+		case "FN":
+			v.Name = unescapeVCard(value)
+		case "ORG":
+			v.Org = unescapeVCard(value)
+		case "TITLE":
+			v.Title = unescapeVCard(value)
+		case "TEL":
+			v.Phones = append(v.Phones, unescapeVCard(value))
+		case "EMAIL":
+			v.Emails = append(v.Emails, unescapeVCard(value))
+		case "URL":
+			v.URL = unescapeVCard(value)
+		case "NOTE":
+			v.Note = unescapeVCard(value)
+		}
+		if ended {
+			break
+		}
+	}
+	if !ended {
+		return nil, errors.New("payload: vCard has no END:VCARD")
+	}
+	if v.Name == "" {
+		v.Name = strings.TrimSpace(v.GivenName + " " + v.FamilyName)
+	}
+	return v, nil
+}
+
+// escapeVCard escapes the characters with a meaning in vCard values
+func escapeVCard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`).Replace(s)
+}
+
+// unescapeVCard reverses escapeVCard, an escaped n or N is a newline
+func unescapeVCard(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package payload
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVCard(t *testing.T) {
+	v := VCard{
+		Name: "Dr. Alice Liddell", FamilyName: "Liddell", GivenName: "Alice",
+		Org: "Wonder; Land, Inc.", Phones: []string{"+44 1234", "+44 5678"},
+		Emails: []string{"alice@example.com"}, URL: "https://example.com", Note: "line one\nline two\\",
+	}
+	got, err := v.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Liddell;Alice;;;\r\nFN:Dr. Alice Liddell\r\n" +
+		"ORG:Wonder\\; Land\\, Inc.\r\nTEL:+44 1234\r\nTEL:+44 5678\r\nEMAIL:alice@example.com\r\n" +
+		"URL:https://example.com\r\nNOTE:line one\\nline two\\\\\r\nEND:VCARD\r\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	parsed, err := ParseVCard(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*parsed, v) {
+		t.Errorf("Parsed %#v, expected %#v", *parsed, v)
+	}
+	if _, err := (&VCard{}).Payload(); err == nil {
+		t.Error("Expected an error for a vCard without a name")
+	}
+}
+
+func TestParseVCardQuirks(t *testing.T) {
+	// vCard 2.1 and 4.0, folded lines, groups and parameters
+	s := "BEGIN:VCARD\nVERSION:2.1\nN:Hatter;Mad\nitem1.TEL;TYPE=CELL:+1 555\nEMAIL;type=INTERNET;type=pref:hat\n ter@example.com\nEND:VCARD\n"
+	v, err := ParseVCard(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VCard{Name: "Mad Hatter", FamilyName: "Hatter", GivenName: "Mad", Phones: []string{"+1 555"}, Emails: []string{"hatter@example.com"}}
+	if !reflect.DeepEqual(*v, want) {
+		t.Errorf("Parsed %#v, expected %#v", *v, want)
+	}
+}
@@ -0,0 +1,248 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// wifiPrefix starts the payload of a WiFi network
+const wifiPrefix = "WIFI:"
+
+// Compat is a set of scanners whose quirks a WiFi payload is written for.
+// The WIFI: format is defined by ZXing, and the stock scanners of Android
+// and iOS read it differently in places.
+type Compat int
+
+// Scanners a WiFi payload can be written for. Without any of them it is
+// written as ZXing documents it.
+const (
+	// CompatAndroid quotes an SSID or password made only of hex digits,
+	// which Android reads as hex otherwise
+	CompatAndroid Compat = 1 << iota
+	// CompatIOS writes SAE as WPA, which iOS joins WPA2 and WPA3 networks
+	// with, and the hidden flag right after the SSID. It never quotes, iOS
+	// takes quotes as part of the name.
+	CompatIOS
+)
+
+// WiFi is a network to join, in the WIFI: format the camera apps of
+// Android and iOS read
+type WiFi struct {
+	SSID string `json:"ssid"`
+	// Security is WPA, WEP or SAE, empty for an open network
+	Security string `json:"security,omitempty"`
+	Password Secret `json:"password,omitempty"`
+	// Hidden is set for networks that do not broadcast their SSID
+	Hidden bool `json:"hidden,omitempty"`
+	// Compat selects the scanners Payload writes for, e.g.
+	// CompatAndroid|CompatIOS for a code that both read. It is not part
+	// of the payload, ParseWiFi leaves it zero.
+	Compat Compat `json:"-"`
+}
+
+// Type returns TypeWiFi
+func (w *WiFi) Type() Type { return TypeWiFi }
+
+// Payload checks w and returns its WIFI: payload, with the special
+// characters of the SSID and password escaped
+func (w *WiFi) Payload() (string, error) {
+	if w.SSID == "" {
+		return "", errors.New("payload: WiFi network has no SSID")
+	}
+	switch w.Security {
+	case "":
+		if len(w.Password) != 0 {
+			return "", errors.New("payload: open WiFi network has a password, set its Security")
+		}
+	case "WPA", "WEP", "SAE":
+		if len(w.Password) == 0 {
+			return "", fmt.Errorf("payload: %s WiFi network has no password", w.Security)
+		}
+	default:
+		return "", fmt.Errorf("payload: unknown WiFi security %q, expected WPA, WEP or SAE", w.Security)
+	}
+
+	ssid, err := w.quote("SSID", w.SSID)
+	if err != nil {
+		return "", err
+	}
+	password, err := w.quote("password", string(w.Password))
+	if err != nil {
+		return "", err
+	}
+	security := w.Security
+	if security == "SAE" && w.Compat&CompatIOS != 0 {
+		security = "WPA"
+	}
+
+	var b strings.Builder
+	b.WriteString(wifiPrefix)
+	if w.Compat&CompatIOS != 0 {
+		b.WriteString("S:" + ssid + ";")
+		if w.Hidden {
+			b.WriteString("H:true;")
+		}
+		if security != "" {
+			b.WriteString("T:" + security + ";")
+		}
+	} else {
+		if security != "" {
+			b.WriteString("T:" + security + ";")
+		}
+		b.WriteString("S:" + ssid + ";")
+	}
+	if password != "" {
+		b.WriteString("P:" + password + ";")
+	}
+	if w.Hidden && w.Compat&CompatIOS == 0 {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+// quote escapes the value of the field name for the scanners of w.Compat
+func (w *WiFi) quote(name, value string) (string, error) {
+	if w.Compat&CompatAndroid == 0 || !isHexDigits(value) {
+		return escapeWiFi(value), nil
+	}
+	if w.Compat&CompatIOS != 0 {
+		return "", fmt.Errorf("payload: a WiFi %s of hex digits can not be written for both Android, which reads it as hex unless quoted, and iOS, which takes the quotes as part of it", name)
+	}
+	return `"` + escapeWiFi(value) + `"`, nil
+}
+
+func isHexDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Fields returns the SSID, security and password of w
+func (w *WiFi) Fields() []Field {
+	security := w.Security
+	if security == "" {
+		security = "open"
+	}
+	fields := []Field{{"SSID", w.SSID}, {"Security", security}}
+	if len(w.Password) != 0 {
+		fields = append(fields, Field{"Password", string(w.Password)})
+	}
+	if w.Hidden {
+		fields = append(fields, Field{"Hidden", "yes"})
+	}
+	return fields
+}
+
+// MarshalJSON writes Password as text, where a Secret alone would be
+// base64
+func (w *WiFi) MarshalJSON() ([]byte, error) {
+	type plain WiFi
+	return json.Marshal(struct {
+		*plain
+		Password string `json:"password,omitempty"`
+	}{(*plain)(w), string(w.Password)})
+}
+
+// UnmarshalJSON reads the text written by MarshalJSON
+func (w *WiFi) UnmarshalJSON(b []byte) error {
+	type plain WiFi
+	v := struct {
+		*plain
+		Password string `json:"password,omitempty"`
+	}{plain: (*plain)(w)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	w.Password = Secret(v.Password)
+	return nil
+}
+
+// ParseWiFi parses a WIFI: payload. Fields it does not know are ignored,
+// values in double quotes are taken without them.
+func ParseWiFi(s string) (*WiFi, error) {
+	if !hasPrefixFold(s, wifiPrefix) {
+		return nil, errors.New("payload: not a WiFi network, expected WIFI:")
+	}
+	w := &WiFi{}
+	hasSSID := false
+	for _, field := range splitEscaped(strings.TrimRight(s[len(wifiPrefix):], "\r\n"), ';') {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, errors.New("payload: WiFi field without a colon")
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		value = unescape(value)
+		switch strings.ToUpper(key) {
+		case "S":
+			w.SSID, hasSSID = value, true
+		case "T":
+			w.Security = strings.ToUpper(value)
+			if w.Security == "NOPASS" {
+				w.Security = ""
+			}
+		case "P":
+			w.Password = Secret(value)
+		case "H":
+			w.Hidden = strings.EqualFold(value, "true")
+		}
+	}
+	if !hasSSID || w.SSID == "" {
+		return nil, errors.New("payload: WiFi network has no SSID")
+	}
+	return w, nil
+}
+
+// escapeWiFi escapes the characters with a meaning in WIFI: payloads
+func escapeWiFi(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\;,:"`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitEscaped splits s at every sep that is not escaped with a backslash,
+// keeping the escapes
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape drops the backslash of every escaped character of s
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
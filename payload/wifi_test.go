@@ -0,0 +1,108 @@
+package payload
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWiFi(t *testing.T) {
+	testCases := []struct {
+		name string
+		wifi WiFi
+		want string
+	}{
+		{"Open", WiFi{SSID: "cafe"}, "WIFI:S:cafe;;"},
+		{"WPA", WiFi{SSID: "home", Security: "WPA", Password: Secret("secret")}, "WIFI:T:WPA;S:home;P:secret;;"},
+		{"Hidden", WiFi{SSID: "lab", Security: "SAE", Password: Secret("pw"), Hidden: true}, "WIFI:T:SAE;S:lab;P:pw;H:true;;"},
+		{"Escaped", WiFi{SSID: `a;b,c:d`, Security: "WPA", Password: Secret(`"x\y"`)}, `WIFI:T:WPA;S:a\;b\,c\:d;P:\"x\\y\";;`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.wifi.Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			parsed, err := ParseWiFi(got)
+			if err != nil {
+				t.Fatalf("ParseWiFi failed: %v", err)
+			}
+			if !reflect.DeepEqual(*parsed, tc.wifi) {
+				t.Errorf("Parsed %#v, expected %#v", *parsed, tc.wifi)
+			}
+		})
+	}
+}
+
+func TestWiFiInvalid(t *testing.T) {
+	for _, w := range []WiFi{
+		{},
+		{SSID: "home", Password: Secret("secret")},
+		{SSID: "home", Security: "WPA"},
+		{SSID: "home", Security: "WPA2", Password: Secret("secret")},
+	} {
+		if _, err := w.Payload(); err == nil {
+			t.Errorf("Expected an error for %#v", w)
+		}
+	}
+}
+
+func TestParseWiFiQuirks(t *testing.T) {
+	// Field order, case, quotes and a missing final semicolon vary
+	// between generators
+	w, err := ParseWiFi(`WIFI:s:"home";t:nopass;X:ignored;h:TRUE`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (WiFi{SSID: "home", Hidden: true}); !reflect.DeepEqual(*w, want) {
+		t.Errorf("Parsed %#v, expected %#v", *w, want)
+	}
+}
+
+func TestWiFiCompat(t *testing.T) {
+	testCases := []struct {
+		name string
+		wifi WiFi
+		want string
+	}{
+		{"StandardHex", WiFi{SSID: "CAFE", Security: "WPA", Password: Secret("12345678")}, "WIFI:T:WPA;S:CAFE;P:12345678;;"},
+		{"AndroidHex", WiFi{SSID: "CAFE", Security: "WPA", Password: Secret("12345678"), Compat: CompatAndroid}, `WIFI:T:WPA;S:"CAFE";P:"12345678";;`},
+		{"AndroidText", WiFi{SSID: "home", Security: "SAE", Password: Secret("pw"), Hidden: true, Compat: CompatAndroid}, "WIFI:T:SAE;S:home;P:pw;H:true;;"},
+		{"IOS", WiFi{SSID: "CAFE", Security: "SAE", Password: Secret("pw"), Hidden: true, Compat: CompatIOS}, "WIFI:S:CAFE;H:true;T:WPA;P:pw;;"},
+		{"IOSOpen", WiFi{SSID: "cafe", Compat: CompatIOS}, "WIFI:S:cafe;;"},
+		{"Both", WiFi{SSID: "home", Security: "SAE", Password: Secret("pw"), Compat: CompatAndroid | CompatIOS}, "WIFI:S:home;T:WPA;P:pw;;"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.wifi.Payload()
+			if err != nil {
+				t.Fatalf("Payload failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// Android needs the quotes that iOS would keep
+	w := WiFi{SSID: "home", Security: "WPA", Password: Secret("deadbeef"), Compat: CompatAndroid | CompatIOS}
+	if _, err := w.Payload(); err == nil || strings.Contains(err.Error(), "deadbeef") {
+		t.Errorf("Expected an error without the password, got %v", err)
+	}
+}
+
+func TestWiFiJSON(t *testing.T) {
+	w := &WiFi{SSID: "home", Security: "WPA", Password: Secret("secret")}
+	b, err := json.Marshal(w)
+	if err != nil || !strings.Contains(string(b), `"password":"secret"`) {
+		t.Fatalf("Marshal gave %s, %v", b, err)
+	}
+	var got WiFi
+	if err := json.Unmarshal(b, &got); err != nil || !reflect.DeepEqual(&got, w) {
+		t.Errorf("Unmarshal gave %#v, %v", got, err)
+	}
+}
@@ -0,0 +1,242 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PageSize is the size of a PDF page in points, 1/72 of an inch
+type PageSize struct {
+	Width, Height float64
+}
+
+// Common page sizes
+var (
+	PageA4     = PageSize{595.28, 841.89}
+	PageLetter = PageSize{612, 792}
+)
+
+// defaultMargin is half an inch
+const defaultMargin = 36
+
+// PDFOptions lays out PDF output
+type PDFOptions struct {
+	// Page is the page size, PageA4 if zero
+	Page PageSize
+	// Margin is the blank border around the page content in points, half
+	// an inch if zero
+	Margin float64
+	// Caption is printed centered under the code
+	Caption string
+}
+
+// area returns the page size and the content rectangle within the margins
+func (o *PDFOptions) area() (page PageSize, x, y, w, h float64) {
+	page, margin := PageA4, float64(defaultMargin)
+	if o != nil {
+		if o.Page.Width > 0 && o.Page.Height > 0 {
+			page = o.Page
+		}
+		if o.Margin > 0 {
+			margin = o.Margin
+		}
+	}
+	return page, margin, margin, page.Width - 2*margin, page.Height - 2*margin
+}
+
+// Label is one code on a label sheet
+type Label struct {
+	Data    []byte
+	Caption string
+}
+
+// Sheet arranges labels in a grid of Columns by Rows on every page
+type Sheet struct {
+	Columns, Rows int
+	PDFOptions
+}
+
+// ErrSheetLayout is returned by WritePDFSheet for a grid without cells
+var ErrSheetLayout = errors.New("qrterminal: a sheet needs at least one column and one row")
+
+// WritePDFSheet writes a PDF with one code per label, filling the cells of
+// the sheet left to right and top to bottom and starting new pages as
+// needed. The Level, Symbology, QuietZone and OutputVersion of config apply
+// to every code, the PDFOptions.Caption of the sheet is not used.
+func WritePDFSheet(w io.Writer, labels []Label, sheet Sheet, config Config) error {
+	if sheet.Columns <= 0 || sheet.Rows <= 0 {
+		return ErrSheetLayout
+	}
+	spec, err := outputSpecFor(config.OutputVersion)
+	if err != nil {
+		return err
+	}
+	if config.QuietZone < spec.minQuietZone {
+		config.QuietZone = spec.minQuietZone
+	}
+
+	codes := make([]*Bitmap, len(labels))
+	for i, l := range labels {
+		bm, err := config.encode(l.Data)
+		if err != nil {
+			return fmt.Errorf("label %d: %w", i+1, err)
+		}
+		codes[i] = bm
+	}
+
+	page, x0, y0, width, height := sheet.area()
+	cellW, cellH := width/float64(sheet.Columns), height/float64(sheet.Rows)
+	perPage := sheet.Columns * sheet.Rows
+	var pages []string
+	for first := 0; first == 0 || first < len(labels); first += perPage {
+		var content strings.Builder
+		for i := first; i < len(labels) && i < first+perPage; i++ {
+			col, row := (i-first)%sheet.Columns, (i-first)/sheet.Columns
+			// Rows are counted from the top, PDF coordinates from the bottom
+			x := x0 + float64(col)*cellW
+			y := y0 + float64(sheet.Rows-1-row)*cellH
+			drawLabel(&content, codes[i], config.QuietZone, labels[i].Caption, 8, x, y, cellW, cellH)
+		}
+		pages = append(pages, content.String())
+	}
+	_, err = w.Write(pdfDocument(page, pages))
+	return err
+}
+
+func (c *Config) writePDF(w io.Writer, bm *Bitmap) {
+	caption := ""
+	if c.PDF != nil {
+		caption = c.PDF.Caption
+	}
+	page, x, y, width, height := c.PDF.area()
+	var content strings.Builder
+	drawLabel(&content, bm, c.QuietZone, caption, 12, x, y, width, height)
+	w.Write(pdfDocument(page, []string{content.String()}))
+}
+
+// drawLabel centers the code, with its caption underneath in a font of
+// fontSize points, in the rectangle at x, y
+func drawLabel(b *strings.Builder, bm *Bitmap, quietZone int, caption string, fontSize, x, y, width, height float64) {
+	captionHeight := 0.0
+	if caption != "" {
+		captionHeight = 2 * fontSize
+	}
+	size := height - captionHeight
+	if width < size {
+		size = width
+	}
+	if size <= 0 {
+		return
+	}
+	codeX := x + (width-size)/2
+	codeY := y + captionHeight + (height-captionHeight-size)/2
+	drawCode(b, bm, quietZone, codeX, codeY, size)
+
+	if caption != "" {
+		// Courier is monospaced, every glyph is 0.6 em wide
+		advance := 0.6 * fontSize
+		text := []rune(caption)
+		if fit := int(width / advance); len(text) > fit {
+			if fit < 3 {
+				return
+			}
+			text = append(text[:fit-3], []rune("...")...)
+		}
+		textX := x + (width-float64(len(text))*advance)/2
+		textY := codeY - 1.5*fontSize
+		fmt.Fprintf(b, "BT /F1 %s Tf %s %s Td %s Tj ET\n", pdfNum(fontSize), pdfNum(textX), pdfNum(textY), pdfString(string(text)))
+	}
+}
+
+// drawCode fills the black modules of bm, with a quiet zone of quietZone
+// modules around them, in the square of side size at x, y
+func drawCode(b *strings.Builder, bm *Bitmap, quietZone int, x, y, size float64) {
+	module := size / float64(bm.Size+2*quietZone)
+	b.WriteString("0 g\n")
+	for row := 0; row < bm.Size; row++ {
+		top := y + size - float64(quietZone+row+1)*module
+		for col := 0; col < bm.Size; {
+			if !bm.Black(col, row) {
+				col++
+				continue
+			}
+			// Draw a run of black modules as a single rectangle
+			run := col
+			for run < bm.Size && bm.Black(run, row) {
+				run++
+			}
+			left := x + float64(quietZone+col)*module
+			fmt.Fprintf(b, "%s %s %s %s re\n", pdfNum(left), pdfNum(top), pdfNum(float64(run-col)*module), pdfNum(module))
+			col = run
+		}
+	}
+	b.WriteString("f\n")
+}
+
+// pdfDocument assembles a PDF with one page per content stream. Objects 1 to
+// 3 are the catalog, the page tree and the font, followed by a content
+// stream and a page object for every page.
+func pdfDocument(page PageSize, contents []string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(contents))
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	for i, content := range contents {
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfNum(page.Width), pdfNum(page.Height), 4+2*i))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfNum formats v with at most two decimals
+func pdfNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfString quotes s as a PDF literal string. The standard fonts only cover
+// ASCII reliably, anything else is replaced with '?'.
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
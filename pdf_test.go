@@ -0,0 +1,163 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+// checkPDF verifies the header, that every xref entry points at its object
+// and returns the number of pages
+func checkPDF(t *testing.T, doc []byte) int {
+	t.Helper()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("Missing PDF header or trailer")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatalf("Missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(doc[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[off:], []byte(want)) {
+			t.Fatalf("xref entry %d does not point at its object", i+1)
+		}
+	}
+	count := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(doc)
+	pages, _ := strconv.Atoi(string(count[1]))
+	return pages
+}
+
+func TestPDFFormat(t *testing.T) {
+	text := "https://github.com/mdp/qrterminal"
+	var buf bytes.Buffer
+	GenerateWithConfig(text, Config{
+		Level:     M,
+		Writer:    &buf,
+		QuietZone: QUIET_ZONE,
+		Format:    FormatPDF,
+		PDF:       &PDFOptions{Page: PageLetter, Caption: text},
+	})
+	doc := buf.Bytes()
+	if pages := checkPDF(t, doc); pages != 1 {
+		t.Errorf("Expected 1 page, got %d", pages)
+	}
+	if !bytes.Contains(doc, []byte("/MediaBox [0 0 612 792]")) {
+		t.Errorf("Expected a letter sized page")
+	}
+	if !bytes.Contains(doc, []byte("("+text+") Tj")) {
+		t.Errorf("Expected the caption in the page content")
+	}
+
+	// Read the rectangles back into a grid of modules
+	code, _ := qr.Encode(text, M.QR())
+	bm := bitmapFromCode(code)
+	var rects [][4]float64
+	for _, m := range regexp.MustCompile(`([\d.]+) ([\d.]+) ([\d.]+) ([\d.]+) re\n`).FindAllSubmatch(doc, -1) {
+		var r [4]float64
+		for i := range r {
+			r[i], _ = strconv.ParseFloat(string(m[i+1]), 64)
+		}
+		rects = append(rects, r)
+	}
+	if len(rects) == 0 {
+		t.Fatalf("No modules drawn")
+	}
+	module := rects[0][3]
+	left, top := math.Inf(1), math.Inf(-1)
+	for _, r := range rects {
+		left = math.Min(left, r[0])
+		top = math.Max(top, r[1])
+	}
+	got := NewBitmap(bm.Size)
+	for _, r := range rects {
+		row := int(math.Round((top - r[1]) / module))
+		for i := 0; i < int(math.Round(r[2]/module)); i++ {
+			got.Set(int(math.Round((r[0]-left)/module))+i, row, true)
+		}
+	}
+	for y := 0; y < bm.Size; y++ {
+		for x := 0; x < bm.Size; x++ {
+			if got.Black(x, y) != bm.Black(x, y) {
+				t.Fatalf("Module (%d, %d) differs from the encoded code", x, y)
+			}
+		}
+	}
+}
+
+func TestPDFFormatNoHyperlink(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("https://example.com", Config{Level: L, Writer: &buf, Format: FormatPDF, Hyperlink: true})
+	if !bytes.HasSuffix(buf.Bytes(), []byte("%%EOF\n")) {
+		t.Errorf("Nothing may follow the PDF document")
+	}
+}
+
+func TestWritePDFSheet(t *testing.T) {
+	var labels []Label
+	for i := 0; i < 7; i++ {
+		labels = append(labels, Label{Data: []byte(fmt.Sprintf("asset-%d", i)), Caption: fmt.Sprintf("Asset %d", i)})
+	}
+	var buf bytes.Buffer
+	if err := WritePDFSheet(&buf, labels, Sheet{Columns: 2, Rows: 3}, Config{Level: L}); err != nil {
+		t.Fatalf("WritePDFSheet failed: %v", err)
+	}
+	if pages := checkPDF(t, buf.Bytes()); pages != 2 {
+		t.Errorf("Expected 7 labels on 2 pages of 6, got %d pages", pages)
+	}
+	for _, l := range labels {
+		if !bytes.Contains(buf.Bytes(), []byte("("+l.Caption+") Tj")) {
+			t.Errorf("Missing caption %q", l.Caption)
+		}
+	}
+
+	buf.Reset()
+	if err := WritePDFSheet(&buf, nil, Sheet{Columns: 2, Rows: 3}, Config{Level: L}); err != nil {
+		t.Fatalf("WritePDFSheet failed: %v", err)
+	}
+	if pages := checkPDF(t, buf.Bytes()); pages != 1 {
+		t.Errorf("Expected a single blank page, got %d pages", pages)
+	}
+}
+
+func TestWritePDFSheetErrors(t *testing.T) {
+	labels := []Label{{Data: []byte("ok")}}
+	if err := WritePDFSheet(&bytes.Buffer{}, labels, Sheet{Columns: 0, Rows: 3}, Config{}); !errors.Is(err, ErrSheetLayout) {
+		t.Errorf("Expected ErrSheetLayout, got %v", err)
+	}
+
+	labels = append(labels, Label{Data: bytes.Repeat([]byte("x"), 4000)})
+	var buf bytes.Buffer
+	err := WritePDFSheet(&buf, labels, Sheet{Columns: 1, Rows: 1}, Config{Level: L})
+	if err == nil || !strings.HasPrefix(err.Error(), "label 2:") {
+		t.Errorf("Expected an error naming label 2, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Nothing should be written when a label can not be encoded")
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	testCases := map[string]string{
+		"plain":       "(plain)",
+		`a (b) \c`:    `(a \(b\) \\c)`,
+		"café\x1b[0m": "(caf??[0m)",
+	}
+	for in, want := range testCases {
+		if got := pdfString(in); got != want {
+			t.Errorf("pdfString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package qrterminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultDotsPerModule suits 203 dpi printers, a module is half a millimeter
+const defaultDotsPerModule = 4
+
+// raster packs the code with its quiet zone into rows of bits, the most
+// significant bit first and 1 for a black dot, with every module scaled to
+// a square of DotsPerModule dots. Rows are padded to whole bytes with
+// white dots.
+func (c *Config) raster(bm *Bitmap) (rows [][]byte, bytesPerRow int) {
+	dots := c.DotsPerModule
+	if dots <= 0 {
+		dots = defaultDotsPerModule
+	}
+	width := (bm.Size + 2*c.QuietZone) * dots
+	bytesPerRow = (width + 7) / 8
+	for y := 0; y < width; y++ {
+		row := make([]byte, bytesPerRow)
+		for x := 0; x < width; x++ {
+			if bm.Black(x/dots-c.QuietZone, y/dots-c.QuietZone) {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, bytesPerRow
+}
+
+// writeZPL writes a ZPL II label with the code as a ^GF graphic field, so
+// the printer reproduces the exact modules instead of encoding the data
+// again
+func (c *Config) writeZPL(w io.Writer, bm *Bitmap) {
+	rows, bytesPerRow := c.raster(bm)
+	total := len(rows) * bytesPerRow
+	var data strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&data, "%X", row)
+	}
+	fmt.Fprintf(w, "^XA\n^FO0,0^GFA,%d,%d,%d,%s^FS\n^XZ\n", total, total, bytesPerRow, data.String())
+}
+
+// writeEPL writes an EPL2 label with the code as a GW graphic. EPL prints
+// a dot for every 0 bit, so the raster is inverted.
+func (c *Config) writeEPL(w io.Writer, bm *Bitmap) {
+	rows, bytesPerRow := c.raster(bm)
+	fmt.Fprintf(w, "\nN\nGW0,0,%d,%d,", bytesPerRow, len(rows))
+	for _, row := range rows {
+		for i := range row {
+			row[i] = ^row[i]
+		}
+		w.Write(row)
+	}
+	io.WriteString(w, "\nP1\n")
+}
@@ -0,0 +1,83 @@
+package qrterminal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+// checkRaster compares rows of packed dots, 1 being black, with the code
+func checkRaster(t *testing.T, rows [][]byte, bm *Bitmap, quietZone, dots int) {
+	t.Helper()
+	width := (bm.Size + 2*quietZone) * dots
+	if len(rows) != width {
+		t.Fatalf("Expected %d rows of dots, got %d", width, len(rows))
+	}
+	for y, row := range rows {
+		for x := 0; x < len(row)*8; x++ {
+			black := row[x/8]&(0x80>>(x%8)) != 0
+			want := x < width && bm.Black(x/dots-quietZone, y/dots-quietZone)
+			if black != want {
+				t.Fatalf("Dot (%d, %d) is %v, want %v", x, y, black, want)
+			}
+		}
+	}
+}
+
+func TestZPLFormat(t *testing.T) {
+	text := "https://github.com/mdp/qrterminal"
+	code, _ := qr.Encode(text, M.QR())
+	bm := bitmapFromCode(code)
+
+	var buf bytes.Buffer
+	GenerateWithConfig(text, Config{Level: M, Writer: &buf, QuietZone: 2, Format: FormatZPL, DotsPerModule: 3})
+	m := regexp.MustCompile(`^\^XA\n\^FO0,0\^GFA,(\d+),(\d+),(\d+),([0-9A-F]+)\^FS\n\^XZ\n$`).FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("Unexpected ZPL output: %.80q", buf.String())
+	}
+	total, _ := strconv.Atoi(m[1])
+	bytesPerRow, _ := strconv.Atoi(m[3])
+	data, err := hex.DecodeString(m[4])
+	if err != nil || len(data) != total || m[2] != m[1] {
+		t.Fatalf("Graphic field sizes do not match its data")
+	}
+	var rows [][]byte
+	for len(data) > 0 {
+		rows = append(rows, data[:bytesPerRow])
+		data = data[bytesPerRow:]
+	}
+	checkRaster(t, rows, bm, 2, 3)
+}
+
+func TestEPLFormat(t *testing.T) {
+	text := "https://github.com/mdp/qrterminal"
+	code, _ := qr.Encode(text, L.QR())
+	bm := bitmapFromCode(code)
+
+	var buf bytes.Buffer
+	GenerateWithConfig(text, Config{Level: L, Writer: &buf, QuietZone: 1, Format: FormatEPL})
+	out := buf.Bytes()
+	header := regexp.MustCompile(`^\nN\nGW0,0,(\d+),(\d+),`).FindSubmatch(out)
+	if header == nil || !bytes.HasSuffix(out, []byte("\nP1\n")) {
+		t.Fatalf("Unexpected EPL output: %.40q", out)
+	}
+	bytesPerRow, _ := strconv.Atoi(string(header[1]))
+	height, _ := strconv.Atoi(string(header[2]))
+	data := out[len(header[0]) : len(out)-len("\nP1\n")]
+	if len(data) != bytesPerRow*height {
+		t.Fatalf("Expected %d bytes of graphic data, got %d", bytesPerRow*height, len(data))
+	}
+	var rows [][]byte
+	for y := 0; y < height; y++ {
+		row := make([]byte, bytesPerRow)
+		for i, b := range data[y*bytesPerRow : (y+1)*bytesPerRow] {
+			row[i] = ^b // EPL prints the 0 bits
+		}
+		rows = append(rows, row)
+	}
+	checkRaster(t, rows, bm, 1, defaultDotsPerModule)
+}
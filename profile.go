@@ -0,0 +1,339 @@
+package qrterminal
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+)
+
+// Profile tunes the output for a family of terminals. Set it on
+// Config.Profile, or let DetectProfile pick one for the current terminal.
+type Profile struct {
+	// Name identifies the profile
+	Name string
+	// NoSixel disables sixel output even if it was requested
+	NoSixel bool
+	// NoHyperlink drops the OSC 8 hyperlink caption even if it was requested
+	NoHyperlink bool
+	// ResetLines ends every line with an explicit SGR reset, so colors never
+	// bleed into the rest of the line or the next one
+	ResetLines bool
+	// SingleWrite renders the whole code into memory and hands it to the
+	// writer in one write, so it is never drawn piecemeal
+	SingleWrite bool
+	// Coalesce merges runs of modules with the same color into a single
+	// escape sequence. It implies SingleWrite.
+	Coalesce bool
+	// Format becomes the Config.Format of a Config that does not choose a
+	// format, theme or fallbacks of its own
+	Format Format
+	// Fallbacks become the Config.Fallbacks of such a Config
+	Fallbacks []RenderMode
+	// MinQuietZone raises a narrower quiet zone to this many modules
+	MinQuietZone int
+	// ASCII marks output that is read where only ASCII is shown reliably,
+	// such as build logs. Config.Strict then rejects other characters.
+	ASCII bool
+}
+
+// ProfileXtermJS suits xterm.js based web consoles, such as the VS Code
+// terminal, which do not render sixel by default and may carry attributes
+// past the end of a line
+var ProfileXtermJS = &Profile{
+	Name:       "xtermjs",
+	NoSixel:    true,
+	ResetLines: true,
+}
+
+// ProfileRemote suits sessions over SSH, mosh and other high latency links.
+// The code is sent as one contiguous block without cursor movement and
+// with as few escape sequences as possible, which also keeps mosh's
+// predictive local echo from drawing over it.
+var ProfileRemote = &Profile{
+	Name:        "remote",
+	ResetLines:  true,
+	SingleWrite: true,
+	Coalesce:    true,
+}
+
+// ProfileAsciinema keeps to the text and SGR colors that asciinema records
+// and plays back faithfully: no sixel images, which are not recorded, and
+// no hyperlinks, which the player does not make clickable
+var ProfileAsciinema = &Profile{
+	Name:        "asciinema",
+	NoSixel:     true,
+	NoHyperlink: true,
+	ResetLines:  true,
+}
+
+// ProfileWindowsTerminal suits Windows Terminal, which draws sixel since
+// version 1.22 and block elements in earlier ones. The richest mode the
+// terminal reports is chosen.
+var ProfileWindowsTerminal = &Profile{
+	Name:      "windows-terminal",
+	Fallbacks: DefaultFallbacks,
+}
+
+// ProfileWindowsConsole suits the classic Windows console, which may
+// neither draw block elements nor, before Windows 10, ANSI colors, so it
+// falls back as far as ASCII
+var ProfileWindowsConsole = &Profile{
+	Name:      "windows-console",
+	Fallbacks: DefaultFallbacks,
+}
+
+// ProfileCI suits the logs of continuous integration jobs. They are not
+// interactive terminals and are shown by web viewers, often on a dark
+// background: the code is written in one piece with the full quiet zone,
+// without sixel or hyperlinks.
+var ProfileCI = &Profile{
+	Name:         "ci",
+	NoSixel:      true,
+	NoHyperlink:  true,
+	ResetLines:   true,
+	SingleWrite:  true,
+	MinQuietZone: QUIET_ZONE,
+	ASCII:        true,
+}
+
+// ProfileLinePrinter suits fixed-pitch impact printers that print
+// provisioning sheets: FormatLinePrinter, plain ASCII without escape
+// sequences, with the full quiet zone. Set Config.LinePrinter to strike
+// the modules darker.
+var ProfileLinePrinter = &Profile{
+	Name:         "lineprinter",
+	Format:       FormatLinePrinter,
+	NoSixel:      true,
+	NoHyperlink:  true,
+	MinQuietZone: QUIET_ZONE,
+	ASCII:        true,
+}
+
+// ciVariables are set by continuous integration services in their jobs
+var ciVariables = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "JENKINS_URL", "TF_BUILD"}
+
+// goos is runtime.GOOS, a variable so tests can detect other platforms
+var goos = runtime.GOOS
+
+// xtermJSPrograms are the TERM_PROGRAM values set by xterm.js based terminals
+var xtermJSPrograms = map[string]bool{
+	"vscode": true,
+	"Hyper":  true,
+	"Tabby":  true,
+}
+
+// DetectProfile returns the profile for the terminal behind w, or nil if
+// the terminal needs no special treatment. It is nil as well if w is not a
+// terminal, so output written to a file or a buffer does not depend on the
+// environment.
+//
+// Linux desktop terminals and the macOS Terminal get no profile on
+// purpose. What they differ in, sixel, hyperlinks and block elements, is
+// found by DetectCapabilities, and none of them needs the line resets,
+// single writes or fallbacks a profile makes. A profile that picked a
+// format for them would override what probing finds. Over SSH they get
+// ProfileRemote.
+func DetectProfile(w io.Writer) *Profile {
+	t := newTerminal(w)
+	if !t.IsTerminal() {
+		return nil
+	}
+	return detectProfile(t)
+}
+
+// DetectProfileFromEnv returns the profile the environment asks for,
+// whether or not the output is a terminal: ProfileCI in the job of a CI
+// service, whose log is not one. It suits programs writing to their own
+// standard output, library output should use DetectProfile.
+func DetectProfileFromEnv() *Profile {
+	return detectProfile(newTerminal(nil))
+}
+
+func detectProfile(t Terminal) *Profile {
+	// asciinema rec sets ASCIINEMA_REC in the recorded shell, whatever the
+	// terminal around it is the recording is what gets shown
+	if t.Getenv("ASCIINEMA_REC") != "" {
+		return ProfileAsciinema
+	}
+	if xtermJSPrograms[t.Getenv("TERM_PROGRAM")] {
+		return ProfileXtermJS
+	}
+	for _, key := range ciVariables {
+		if t.Getenv(key) != "" {
+			return ProfileCI
+		}
+	}
+	if t.Getenv("SSH_CONNECTION") != "" || t.Getenv("MOSH") != "" {
+		return ProfileRemote
+	}
+	if goos == "windows" {
+		if t.Getenv("WT_SESSION") != "" {
+			return ProfileWindowsTerminal
+		}
+		return ProfileWindowsConsole
+	}
+	return nil
+}
+
+// adjust applies the profile's settings to c
+func (p *Profile) adjust(c *Config) {
+	if p == nil {
+		return
+	}
+	if c.Format == "" && !c.HalfBlocks && c.Theme == nil && len(c.Fallbacks) == 0 {
+		if p.Format != "" {
+			c.Format = p.Format
+		}
+		c.Fallbacks = p.Fallbacks
+	}
+	if p.NoSixel {
+		c.WithSixel = false
+		if c.Format == FormatSixel {
+			c.Format = ""
+		}
+		var fallbacks []RenderMode
+		for _, m := range c.Fallbacks {
+			if m != ModeSixel {
+				fallbacks = append(fallbacks, m)
+			}
+		}
+		c.Fallbacks = fallbacks
+	}
+	if c.QuietZone < p.MinQuietZone {
+		c.QuietZone = p.MinQuietZone
+	}
+	if p.NoHyperlink {
+		c.Hyperlink = false
+	}
+}
+
+// render runs draw against w, post-processing its output as the profile
+// requires
+func (p *Profile) render(w io.Writer, draw func(w io.Writer)) {
+	if p == nil {
+		draw(w)
+		return
+	}
+	if !p.SingleWrite && !p.Coalesce {
+		if p.ResetLines {
+			w = &resetWriter{w: w}
+		}
+		draw(w)
+		return
+	}
+
+	var buf bytes.Buffer
+	draw(&buf)
+	out := buf.Bytes()
+	if p.Coalesce {
+		out = coalesceSGR(out)
+	}
+	if p.ResetLines {
+		out = addResets(out)
+	}
+	w.Write(out)
+}
+
+// unbuffered returns p without the settings that render the whole code
+// into memory before writing it
+func (p *Profile) unbuffered() *Profile {
+	if p == nil || (!p.SingleWrite && !p.Coalesce) {
+		return p
+	}
+	q := *p
+	q.SingleWrite, q.Coalesce = false, false
+	return &q
+}
+
+// resetWriter inserts an SGR reset before every newline that does not
+// already follow one
+type resetWriter struct {
+	w    io.Writer
+	tail []byte // the last bytes written, to spot a reset split over writes
+}
+
+func (r *resetWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if b == '\n' && !r.afterReset(out) {
+			out = append(out, sgrReset...)
+		}
+		out = append(out, b)
+	}
+	r.tail = append(r.tail, out...)
+	if len(r.tail) > len(sgrReset) {
+		r.tail = r.tail[len(r.tail)-len(sgrReset):]
+	}
+	if _, err := r.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// afterReset reports whether out, following the previous writes, ends with
+// an SGR reset
+func (r *resetWriter) afterReset(out []byte) bool {
+	if len(out) >= len(sgrReset) {
+		return bytes.HasSuffix(out, []byte(sgrReset))
+	}
+	return bytes.HasSuffix(append(append([]byte(nil), r.tail...), out...), []byte(sgrReset))
+}
+
+const sgrReset = "\033[0m"
+
+func addResets(p []byte) []byte {
+	var buf bytes.Buffer
+	(&resetWriter{w: &buf}).Write(p)
+	return buf.Bytes()
+}
+
+// coalesceSGR drops every reset that is immediately followed by the one
+// SGR sequence that was in effect before it, e.g. two adjacent WHITE
+// modules become a single colored run
+func coalesceSGR(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	active := "" // the only SGR set since the last reset, if any
+	for len(p) > 0 {
+		if bytes.HasPrefix(p, []byte(sgrReset)) {
+			rest := p[len(sgrReset):]
+			if active != "" && bytes.HasPrefix(rest, []byte(active)) {
+				p = rest[len(active):]
+				continue
+			}
+			out = append(out, sgrReset...)
+			active = ""
+			p = rest
+			continue
+		}
+		if n := sgrLen(p); n > 0 {
+			if active == "" {
+				active = string(p[:n])
+			} else {
+				// More than one attribute is set, a reset can not be elided
+				active = "\x00"
+			}
+			out = append(out, p[:n]...)
+			p = p[n:]
+			continue
+		}
+		out = append(out, p[0])
+		p = p[1:]
+	}
+	return out
+}
+
+// sgrLen returns the length of the SGR sequence at the start of p, or 0
+func sgrLen(p []byte) int {
+	if !bytes.HasPrefix(p, []byte("\033[")) {
+		return 0
+	}
+	for i := 2; i < len(p); i++ {
+		switch c := p[i]; {
+		case c == 'm':
+			return i + 1
+		case c != ';' && (c < '0' || c > '9'):
+			return 0
+		}
+	}
+	return 0
+}
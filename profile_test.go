@@ -0,0 +1,236 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDetectProfile(t *testing.T) {
+	vscode := fakeTerminal{env: map[string]string{"TERM_PROGRAM": "vscode"}}
+	if p := detectProfile(vscode); p != ProfileXtermJS {
+		t.Errorf("Expected the xterm.js profile for VS Code, got %v", p)
+	}
+	if p := detectProfile(fakeTerminal{}); p != nil {
+		t.Errorf("Expected no profile for an unknown terminal, got %v", p)
+	}
+}
+
+func TestDetectProfileNotTerminal(t *testing.T) {
+	// Output captured in a buffer is the same whatever the environment
+	render := func() string {
+		var buf bytes.Buffer
+		Generate("https://example.com", L, &buf)
+		GenerateBinary([]byte{0, 1, 2}, L, &buf)
+		return buf.String()
+	}
+	want := render()
+	for _, env := range []string{"SSH_CONNECTION", "CI", "ASCIINEMA_REC", "MOSH"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "1")
+			if p := DetectProfile(&bytes.Buffer{}); p != nil {
+				t.Errorf("Expected no profile for a buffer, got %s", p.Name)
+			}
+			if got := render(); got != want {
+				t.Errorf("Output into a buffer changed with %s set", env)
+			}
+		})
+	}
+	t.Setenv("CI", "1")
+	if p := DetectProfileFromEnv(); p != ProfileCI {
+		t.Errorf("Expected the CI profile from the environment, got %v", p)
+	}
+}
+
+func TestProfileXtermJS(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{
+		Level:     L,
+		Writer:    &buf,
+		BlackChar: BLACK,
+		WhiteChar: WHITE,
+		QuietZone: QUIET_ZONE,
+		WithSixel: true,
+		Profile:   ProfileXtermJS,
+	}
+	GenerateWithConfig("https://github.com/mdp/qrterminal", config)
+	output := buf.String()
+
+	if strings.Contains(output, SIXEL_BEGIN) {
+		t.Errorf("The xterm.js profile should never emit sixel")
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for i, line := range lines {
+		if !strings.HasSuffix(line, "\033[0m") {
+			t.Errorf("Line %d should end with an SGR reset: %q", i, line)
+		}
+	}
+}
+
+func TestDetectProfileRemote(t *testing.T) {
+	ssh := fakeTerminal{env: map[string]string{"SSH_CONNECTION": "10.0.0.1 5000 10.0.0.2 22"}}
+	if p := detectProfile(ssh); p != ProfileRemote {
+		t.Errorf("Expected the remote profile over SSH, got %v", p)
+	}
+	vscodeSSH := fakeTerminal{env: map[string]string{
+		"SSH_CONNECTION": "10.0.0.1 5000 10.0.0.2 22",
+		"TERM_PROGRAM":   "vscode",
+	}}
+	if p := detectProfile(vscodeSSH); p != ProfileXtermJS {
+		t.Errorf("A known terminal should take precedence over the remote profile, got %v", p)
+	}
+}
+
+func TestProfileAsciinema(t *testing.T) {
+	recording := fakeTerminal{env: map[string]string{"ASCIINEMA_REC": "1", "TERM_PROGRAM": "vscode"}}
+	if p := detectProfile(recording); p != ProfileAsciinema {
+		t.Errorf("Expected the asciinema profile while recording, got %v", p)
+	}
+
+	var buf bytes.Buffer
+	GenerateWithConfig("https://github.com/mdp/qrterminal", Config{
+		Level:     L,
+		Writer:    &buf,
+		BlackChar: BLACK,
+		WhiteChar: WHITE,
+		QuietZone: QUIET_ZONE,
+		WithSixel: true,
+		Hyperlink: true,
+		Profile:   ProfileAsciinema,
+	})
+	output := buf.String()
+	if strings.Contains(output, SIXEL_BEGIN) {
+		t.Errorf("The asciinema profile should never emit sixel")
+	}
+	if strings.Contains(output, "\033]8;") {
+		t.Errorf("The asciinema profile should never emit hyperlinks")
+	}
+}
+
+func TestDetectProfilePlatform(t *testing.T) {
+	defer func(saved string) { goos = saved }(goos)
+	goos = "windows"
+	wt := fakeTerminal{env: map[string]string{"WT_SESSION": "a1b2"}}
+	if p := detectProfile(wt); p != ProfileWindowsTerminal {
+		t.Errorf("Expected the Windows Terminal profile, got %v", p)
+	}
+	if p := detectProfile(fakeTerminal{}); p != ProfileWindowsConsole {
+		t.Errorf("Expected the Windows console profile, got %v", p)
+	}
+	ci := fakeTerminal{env: map[string]string{"GITHUB_ACTIONS": "true", "WT_SESSION": "a1b2"}}
+	if p := detectProfile(ci); p != ProfileCI {
+		t.Errorf("Expected the CI profile in a CI job, got %v", p)
+	}
+	goos = "linux"
+	if p := detectProfile(wt); p != nil {
+		t.Errorf("Expected no profile outside of Windows, got %v", p)
+	}
+}
+
+func TestProfileFallbacks(t *testing.T) {
+	render := func(c Config) string {
+		var buf bytes.Buffer
+		c.Level, c.QuietZone, c.Writer = L, 2, &buf
+		GenerateWithConfig("test", c)
+		return buf.String()
+	}
+	caps := &Capabilities{Sixel: true, Unicode: true, Color: true}
+	halfBlocks := render(Config{Format: FormatHalfBlocks})
+
+	if got := render(Config{Profile: ProfileWindowsTerminal, Capabilities: &Capabilities{Unicode: true}}); got != halfBlocks {
+		t.Errorf("Expected half blocks from the profile's fallbacks, got\n%s", got)
+	}
+	noSixel := &Profile{Name: "test", NoSixel: true, Fallbacks: DefaultFallbacks}
+	if got := render(Config{Profile: noSixel, Capabilities: caps}); got != halfBlocks {
+		t.Errorf("NoSixel should skip the sixel fallback, got\n%s", got)
+	}
+	// A format chosen by the Config is kept
+	bits := render(Config{Format: FormatBits})
+	if got := render(Config{Profile: ProfileWindowsTerminal, Capabilities: caps, Format: FormatBits}); got != bits {
+		t.Errorf("Expected the bits format to be kept, got\n%s", got)
+	}
+}
+
+func TestProfileCI(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("https://github.com/mdp/qrterminal", Config{
+		Level:     L,
+		Writer:    &buf,
+		QuietZone: 1,
+		Format:    FormatBits,
+		Hyperlink: true,
+		Profile:   ProfileCI,
+	})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 29+2*QUIET_ZONE {
+		t.Errorf("Expected the full quiet zone, got %d lines for a 29 module code", len(lines))
+	}
+	if strings.Contains(buf.String(), "\033]8;") {
+		t.Errorf("The CI profile should never emit hyperlinks")
+	}
+}
+
+// cells replays the SGR sequences in s and returns each printed character
+// together with the attributes it is drawn with
+func cells(s string) []string {
+	var out []string
+	var attrs []string
+	for len(s) > 0 {
+		if n := sgrLen([]byte(s)); n > 0 {
+			if s[:n] == sgrReset {
+				attrs = nil
+			} else {
+				attrs = append(attrs, s[:n])
+			}
+			s = s[n:]
+			continue
+		}
+		out = append(out, strings.Join(attrs, "")+s[:1])
+		s = s[1:]
+	}
+	return out
+}
+
+func TestProfileRemote(t *testing.T) {
+	render := func(p *Profile) string {
+		var buf bytes.Buffer
+		GenerateWithConfig("https://github.com/mdp/qrterminal", Config{
+			Level:     L,
+			Writer:    &buf,
+			BlackChar: BLACK,
+			WhiteChar: WHITE,
+			QuietZone: QUIET_ZONE,
+			Profile:   p,
+		})
+		return buf.String()
+	}
+	plain := render(nil)
+	remote := render(ProfileRemote)
+
+	if len(remote) >= len(plain)/2 {
+		t.Errorf("Coalesced output should be much smaller, got %d bytes vs %d", len(remote), len(plain))
+	}
+	if strings.Count(remote, sgrReset+sgrReset) > 0 {
+		t.Errorf("Output should not contain redundant resets")
+	}
+	if strings.Join(cells(plain), "") != strings.Join(cells(remote), "") {
+		t.Errorf("Coalesced output should draw exactly the same cells")
+	}
+}
+
+func TestProfileSingleWrite(t *testing.T) {
+	var w countingWriter
+	GenerateWithConfig("test", Config{Level: L, Writer: &w, Profile: ProfileRemote})
+	if w.writes != 1 {
+		t.Errorf("Expected a single write, got %d", w.writes)
+	}
+}
+
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
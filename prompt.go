@@ -0,0 +1,30 @@
+//go:build !tinygo
+
+package qrterminal
+
+import (
+	"io"
+	"os"
+
+	"github.com/katzenpost/qrterminal/v3/secret"
+	"golang.org/x/term"
+)
+
+// ReadSecret writes prompt to out and reads a line from the terminal in
+// with echo turned off, e.g. a password or seed to encode that has to
+// stay out of the shell history and the process list. It fails with
+// ErrNotTerminal if in is not a terminal.
+func ReadSecret(in *os.File, out io.Writer, prompt string) (secret.Bytes, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, ErrNotTerminal
+	}
+	io.WriteString(out, prompt)
+	line, err := term.ReadPassword(fd)
+	// The Enter that ended the line was not echoed either
+	io.WriteString(out, "\n")
+	if err != nil {
+		return nil, err
+	}
+	return secret.Bytes(line), nil
+}
@@ -0,0 +1,27 @@
+//go:build !tinygo
+
+package qrterminal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestReadSecretNotTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.WriteString("secret\n")
+
+	var out bytes.Buffer
+	if _, err := ReadSecret(r, &out, "Secret: "); err != ErrNotTerminal {
+		t.Errorf("Expected ErrNotTerminal, got %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("Expected no prompt, got %q", out.String())
+	}
+}
@@ -0,0 +1,170 @@
+// Package qrhttp serves codes over HTTP, so Go services can mount a QR
+// endpoint behind their own routing and authentication.
+//
+// The payload is the data query parameter of a GET request or the body of
+// a POST request. The level, format and quiet query parameters override
+// the Level, Format and QuietZone of the handler's Config:
+//
+//	GET /qr?data=https%3A%2F%2Fexample.com&format=halfblocks&level=M
+package qrhttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+// Options configure a handler
+type Options struct {
+	// Config is the base configuration of every code, its Writer is not
+	// used. FormatBlocks is used if it selects no format.
+	Config qrterminal.Config
+	// MaxBytes limits the size of the payload, the capacity of the largest
+	// QR code if zero
+	MaxBytes int64
+	// Formats lists the formats clients may ask for, every format if nil
+	Formats []qrterminal.Format
+	// Allow restricts the payloads rendered, every payload if nil.
+	// Payloads it does not allow are refused with 403 Forbidden.
+	Allow *payload.Allowlist
+}
+
+// maxAllowBytes bounds how much of a payload is read to check it against
+// Options.Allow when MaxBytes is not set, more than a QR code holds
+const maxAllowBytes = 4096
+
+// contentTypes are the media types of the formats that are not text
+var contentTypes = map[qrterminal.Format]string{
+	qrterminal.FormatSixel: "application/octet-stream",
+	qrterminal.FormatPDF:   "application/pdf",
+	qrterminal.FormatZPL:   "application/octet-stream",
+	qrterminal.FormatEPL:   "application/octet-stream",
+}
+
+type handler struct {
+	opts    Options
+	allowed map[qrterminal.Format]bool
+}
+
+// NewHandler returns a handler that renders the payload of every request
+// as configured by opts
+func NewHandler(opts Options) http.Handler {
+	h := &handler{opts: opts}
+	if opts.Formats != nil {
+		h.allowed = make(map[qrterminal.Format]bool)
+		for _, f := range opts.Formats {
+			h.allowed[f] = true
+		}
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		// The query carries text, which is checked as GenerateText does
+		data := r.URL.Query().Get("data")
+		if strings.TrimSpace(data) == "" {
+			if !h.opts.Config.AllowEmpty {
+				http.Error(w, qrterminal.ErrEmptyPayload.Error(), http.StatusBadRequest)
+				return
+			}
+			if h.opts.Config.Placeholder != "" {
+				data = h.opts.Config.Placeholder
+			}
+		}
+		body = strings.NewReader(data)
+	case http.MethodPost:
+		body = r.Body
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "qrhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg := h.opts.Config
+	if err := parseQuery(r, &cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case cfg.Format != "":
+	case cfg.HalfBlocks:
+		cfg.Format = qrterminal.FormatHalfBlocks
+	case cfg.WithSixel:
+		cfg.Format = qrterminal.FormatSixel
+	default:
+		cfg.Format = qrterminal.FormatBlocks
+	}
+	if h.allowed != nil && !h.allowed[cfg.Format] {
+		http.Error(w, "qrhttp: format "+string(cfg.Format)+" is not available", http.StatusBadRequest)
+		return
+	}
+
+	if h.opts.Allow != nil {
+		limit := h.opts.MaxBytes
+		if limit <= 0 {
+			limit = maxAllowBytes
+		}
+		data, err := io.ReadAll(io.LimitReader(body, limit+1))
+		if err != nil {
+			http.Error(w, "qrhttp: reading the payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.opts.Allow.Check(data); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// The code is buffered so errors can still be reported with a status
+	var buf bytes.Buffer
+	cfg.Writer = &buf
+	if err := qrterminal.GenerateFromReader(body, h.opts.MaxBytes, cfg); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, qrterminal.ErrInputTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	contentType, ok := contentTypes[cfg.Format]
+	if !ok {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(buf.Bytes())
+}
+
+// parseQuery applies the level, format and quiet query parameters to cfg
+func parseQuery(r *http.Request, cfg *qrterminal.Config) error {
+	q := r.URL.Query()
+	if s := q.Get("level"); s != "" {
+		if err := cfg.Level.Set(s); err != nil {
+			return err
+		}
+	}
+	if s := q.Get("format"); s != "" {
+		if err := cfg.Format.Set(s); err != nil {
+			return err
+		}
+	}
+	if s := q.Get("quiet"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 16 {
+			return errors.New("qrhttp: quiet must be a number of modules from 0 to 16")
+		}
+		cfg.QuietZone = n
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package qrhttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/qrterminal/v3"
+	"github.com/katzenpost/qrterminal/v3/payload"
+)
+
+func render(t *testing.T, data string, cfg qrterminal.Config) string {
+	t.Helper()
+	var buf bytes.Buffer
+	cfg.Writer = &buf
+	if err := qrterminal.GenerateFromReader(strings.NewReader(data), 0, cfg); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(Options{Config: qrterminal.Config{Level: qrterminal.L, QuietZone: 2}})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/qr?data=hello&format=halfblocks&level=m", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	want := render(t, "hello", qrterminal.Config{Level: qrterminal.M, QuietZone: 2, Format: qrterminal.FormatHalfBlocks})
+	if rec.Body.String() != want {
+		t.Errorf("Unexpected code for a GET request")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/qr?format=pdf&quiet=4", strings.NewReader("hello")))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("Expected a PDF, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("The body of a POST request should be rendered as a PDF")
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := NewHandler(Options{
+		MaxBytes: 8,
+		Formats:  []qrterminal.Format{qrterminal.FormatBlocks, qrterminal.FormatBits},
+	})
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"Default", "GET", "/?data=ok", "", http.StatusOK},
+		{"Allowed", "GET", "/?data=ok&format=bits", "", http.StatusOK},
+		{"NotAllowed", "GET", "/?data=ok&format=pdf", "", http.StatusBadRequest},
+		{"UnknownFormat", "GET", "/?data=ok&format=png", "", http.StatusBadRequest},
+		{"BadLevel", "GET", "/?data=ok&level=z", "", http.StatusBadRequest},
+		{"BadQuiet", "GET", "/?data=ok&quiet=-1", "", http.StatusBadRequest},
+		{"Empty", "GET", "/", "", http.StatusBadRequest},
+		{"TooLarge", "POST", "/", "123456789", http.StatusRequestEntityTooLarge},
+		{"Method", "PUT", "/", "ok", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Errorf("Expected %d, got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerAllow(t *testing.T) {
+	h := NewHandler(Options{Allow: &payload.Allowlist{Prefixes: []string{"https://example.com/"}}})
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"Allowed", "GET", "/?data=https%3A%2F%2Fexample.com%2Fpair", "", http.StatusOK},
+		{"AllowedPost", "POST", "/", "https://example.com/pair", http.StatusOK},
+		{"Forbidden", "GET", "/?data=https%3A%2F%2Fevil.test%2F", "", http.StatusForbidden},
+		{"ForbiddenPost", "POST", "/", "rm -rf /", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Errorf("Expected %d, got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/katzenpost/qrterminal/v3/qrtcell
+
+go 1.20
+
+require (
+	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/katzenpost/qrterminal/v3 v3.0.0
+)
+
+require (
+	github.com/gdamore/encoding v1.0.1 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/rivo/uniseg v0.4.3 // indirect
+	golang.org/x/crypto v0.23.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/term v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	rsc.io/qr v0.2.0 // indirect
+)
+
+replace github.com/katzenpost/qrterminal/v3 => ../
@@ -0,0 +1,91 @@
+// Package qrtcell draws QR codes on a tcell.Screen, through the CellGrid
+// interface of qrterminal, so tcell based tools need not split rendered
+// strings into cells. It is a module of its own, which keeps tcell out of
+// the dependencies of qrterminal.
+package qrtcell
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+// Grid is a qrterminal.CellGrid that sets the cells of Screen
+type Grid struct {
+	Screen tcell.Screen
+	// Black and White are the colors of dark and light modules,
+	// tcell.ColorBlack and tcell.ColorWhite if zero. Light modules need
+	// a light color for the code to scan.
+	Black, White tcell.Color
+}
+
+// SetCell sets the content and colors of the cell at x, y
+func (g Grid) SetCell(x, y int, ch rune, fg, bg qrterminal.Color) {
+	style := tcell.StyleDefault.Foreground(g.color(fg)).Background(g.color(bg))
+	g.Screen.SetContent(x, y, ch, nil, style)
+}
+
+// color maps c to a tcell color
+func (g Grid) color(c qrterminal.Color) tcell.Color {
+	if c == qrterminal.ColorBlack {
+		if g.Black == 0 {
+			return tcell.ColorBlack
+		}
+		return g.Black
+	}
+	if g.White == 0 {
+		return tcell.ColorWhite
+	}
+	return g.White
+}
+
+// View shows the code of Data centered in a region of a screen, in the
+// largest format, level and quiet zone that fit as chosen by
+// qrterminal.FitTo. The choice is kept until the region changes size.
+type View struct {
+	Grid
+	Data []byte
+	// X, Y, Width and Height are the region, the whole screen if Width
+	// or Height is zero
+	X, Y, Width, Height int
+
+	fitted        bool
+	width, height int
+	config        qrterminal.Config
+	err           error
+}
+
+// Draw clears the region and draws the code in it, fitting it again if
+// the size of the region changed, e.g. after a *tcell.EventResize. If the
+// code does not fit the region is left blank and the *qrterminal.BudgetError
+// of FitTo is returned. Call Screen.Show to display it.
+func (v *View) Draw() error {
+	x, y, width, height := v.X, v.Y, v.Width, v.Height
+	if width == 0 || height == 0 {
+		x, y = 0, 0
+		width, height = v.Screen.Size()
+	}
+	if !v.fitted || width != v.width || height != v.height {
+		v.config, v.err = qrterminal.FitTo(v.Data, width, height)
+		v.config.Cache = qrterminal.NewEncodeCache(1)
+		v.fitted, v.width, v.height = true, width, height
+	}
+
+	for cy := y; cy < y+height; cy++ {
+		for cx := x; cx < x+width; cx++ {
+			v.Screen.SetContent(cx, cy, ' ', nil, tcell.StyleDefault)
+		}
+	}
+	if v.err != nil {
+		return v.err
+	}
+	cols, lines, err := qrterminal.RenderedSize(v.Data, v.config)
+	if err != nil {
+		return err
+	}
+	return qrterminal.RenderInto(v.Grid, x+(width-cols)/2, y+(height-lines)/2, v.Data, v.config)
+}
+
+// Reset makes the next Draw fit the code again, e.g. after Data changed
+func (v *View) Reset() {
+	v.fitted = false
+}
@@ -0,0 +1,86 @@
+package qrtcell
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/katzenpost/qrterminal/v3"
+)
+
+func newScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Fini)
+	s.SetSize(width, height)
+	return s
+}
+
+func TestGridColors(t *testing.T) {
+	s := newScreen(t, 4, 1)
+	g := Grid{Screen: s, White: tcell.ColorYellow}
+	g.SetCell(0, 0, '▀', qrterminal.ColorBlack, qrterminal.ColorWhite)
+	ch, _, style, _ := s.GetContent(0, 0)
+	fg, bg, _ := style.Decompose()
+	if ch != '▀' || fg != tcell.ColorBlack || bg != tcell.ColorYellow {
+		t.Errorf("got %q %v on %v", ch, fg, bg)
+	}
+}
+
+func TestViewRefit(t *testing.T) {
+	data := []byte("https://example.com")
+	s := newScreen(t, 80, 40)
+	v := &View{Grid: Grid{Screen: s}, Data: data}
+	if err := v.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := qrterminal.FitTo(data, 80, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.config.Format != want.Format || v.config.Level != want.Level || v.config.QuietZone != want.QuietZone {
+		t.Errorf("fitted %+v, want %+v", v.config, want)
+	}
+	cols, lines, _ := qrterminal.RenderedSize(data, v.config)
+	x, y := (80-cols)/2, (40-lines)/2
+	if _, _, style, _ := s.GetContent(x, y); style == tcell.StyleDefault {
+		t.Errorf("no code at %d, %d", x, y)
+	}
+	if _, _, style, _ := s.GetContent(x-1, y); style != tcell.StyleDefault {
+		t.Errorf("cell left of the code was drawn")
+	}
+
+	s.SetSize(10, 5)
+	var budget *qrterminal.BudgetError
+	if err := v.Draw(); !errors.As(err, &budget) {
+		t.Fatalf("got %v, want a BudgetError", err)
+	}
+	if _, _, style, _ := s.GetContent(x, y); style != tcell.StyleDefault {
+		t.Errorf("region not cleared")
+	}
+
+	s.SetSize(200, 100)
+	if err := v.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if v.width != 200 || v.height != 100 {
+		t.Errorf("not refitted to %dx%d", v.width, v.height)
+	}
+}
+
+func TestViewRegion(t *testing.T) {
+	s := newScreen(t, 120, 60)
+	v := &View{Grid: Grid{Screen: s}, Data: []byte("hi"), X: 70, Y: 5, Width: 50, Height: 30}
+	if err := v.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 70; x++ {
+			if _, _, style, _ := s.GetContent(x, y); style != tcell.StyleDefault {
+				t.Fatalf("cell %d, %d outside the region was drawn", x, y)
+			}
+		}
+	}
+}
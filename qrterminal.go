@@ -2,13 +2,12 @@ package qrterminal
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
-	"golang.org/x/term"
-	"rsc.io/qr"
+	"github.com/katzenpost/qrterminal/v3/sixel"
 )
 
 const WHITE = "\033[47m  \033[0m"
@@ -20,11 +19,6 @@ const BLACK_BLACK = " "
 const WHITE_BLACK = "▀"
 const WHITE_WHITE = "█"
 
-// Level - the QR Code's redundancy level
-const H = qr.H
-const M = qr.M
-const L = qr.L
-
 // default is 4-pixel-wide white quiet zone
 const QUIET_ZONE = 4
 
@@ -38,7 +32,7 @@ const SIXEL_BLOCK_SIZE = 12
 
 // Config for generating a barcode
 type Config struct {
-	Level          qr.Level
+	Level          Level
 	Writer         io.Writer
 	HalfBlocks     bool
 	BlackChar      string
@@ -47,41 +41,130 @@ type Config struct {
 	WhiteBlackChar string
 	QuietZone      int
 	WithSixel      bool
+	Profile        *Profile
+	// Format selects the output format, overriding HalfBlocks and WithSixel.
+	// Unknown formats are drawn as FormatBlocks.
+	Format Format
+	// BitsGroup separates the digits of FormatBits into groups of this size
+	BitsGroup int
+	// Theme provides the glyphs that are not set above
+	Theme *Theme
+	// OutputVersion pins the defaults to a documented revision, see OutputV1
+	OutputVersion int
+	// Symbology selects the registered Encoder, SymbologyQR if empty
+	Symbology string
+	// DotsPerModule is the size of a module in printer dots for FormatZPL
+	// and FormatEPL, 4 if zero
+	DotsPerModule int
+	// PDF lays out FormatPDF output, A4 with the default margins if nil
+	PDF *PDFOptions
+	// AspectRatio is the width of a terminal cell divided by its height,
+	// e.g. 0.5 for most fonts. When set, FormatBlocks and FormatDoubleSize
+	// repeat every module across or down so it comes out roughly square
+	// with the glyphs in use. Zero draws a module as one glyph on one
+	// line. FormatHalfBlocks is made for cells of 0.5 and ignores it.
+	AspectRatio float64
+	// LinePrinter tunes FormatLinePrinter output, a single pass of 'M' if
+	// nil
+	LinePrinter *LinePrinterOptions
+	// Hyperlink prints an OSC 8 hyperlink caption under the code when the
+	// data is an http or https URL, so it can also be clicked. Only enable
+	// it for terminals that support it, see Capabilities.Hyperlink.
+	Hyperlink bool
+	// QuietChar draws the quiet zone of FormatBlocks, WhiteChar is used when
+	// empty
+	QuietChar string
+	// QuietColor is the SGR parameters of a color for the quiet zone of
+	// FormatBlocks, e.g. "107" for a bright white background, so the border
+	// stays bright while the modules are styled. Lint checks its contrast.
+	QuietColor string
+	// Backdrop draws the block formats dark on light on a background of
+	// their own, for terminals with a dark background, see BackdropWhite,
+	// or with the modules alone on the terminal background, see
+	// BackdropTransparent.
+	// Sixel images always have an opaque background.
+	Backdrop *Backdrop
+	// DebugOverlay draws FormatBlocks and FormatHalfBlocks in colors that
+	// set the finder, timing and alignment patterns and the format and
+	// version information apart from the data and error correction
+	// modules, see Bitmap.Role, with a legend under the code. It is meant
+	// for learning and debugging, scanners may not read such codes. Other
+	// symbologies are drawn as usual.
+	DebugOverlay bool
+	// AllowEmpty lets GenerateText encode text that is empty or only
+	// whitespace, which it otherwise rejects with ErrEmptyPayload
+	AllowEmpty bool
+	// Placeholder is encoded by GenerateText instead of such text when
+	// AllowEmpty is set, e.g. a message to show while the real payload is
+	// not known yet
+	Placeholder string
+	// BackupText prints the data under the code in the manual-entry format
+	// of package manualcode, to type in when the code can not be scanned
+	BackupText bool
+	// AutoUppercaseURLs writes the scheme and host of an http or https URL
+	// in upper case when that lets the whole URL be encoded in the
+	// alphanumeric mode of QR codes, which makes a smaller symbol. Both are
+	// case insensitive, the rest of the URL is never changed.
+	AutoUppercaseURLs bool
+	// LowMemory keeps memory use down on devices such as routers and
+	// single-board computers: lines are written as they are drawn, even
+	// with a Profile that would render the whole code first, and the map
+	// of module roles behind Bitmap.Role and DebugOverlay is not built.
+	LowMemory bool
+	// Cache keeps the codes encoded with this Config for reuse, e.g. by a
+	// display that redraws the same data, see NewEncodeCache. Every code
+	// is encoded anew if nil.
+	Cache *EncodeCache
+	// Strict rejects custom glyphs and labels that are not plain ASCII
+	// with ErrNotASCII when the Profile only allows ASCII, such as
+	// ProfileCI, instead of writing them garbled into a build log. The
+	// default glyphs are not checked.
+	Strict bool
+	// OnLine is called with every line of text formats, without its line
+	// ending, as it is rendered, e.g. to draw the code inside a TUI. Lines
+	// still go to Writer unless it is nil.
+	OnLine func(line string)
+	// Outputs receive the code as well, after Writer, e.g. to keep a copy
+	// in a log file
+	Outputs []Output
+	// Fallbacks are tried in order, e.g. DefaultFallbacks, and the code is
+	// drawn in the first that the terminal supports and that fits in
+	// MaxColumns and MaxLines, instead of with the format and glyphs set
+	// above. If none does, nothing is drawn.
+	Fallbacks []RenderMode
+	// Capabilities are the features of the terminal the Fallbacks are
+	// checked against, DetectCapabilities of Writer if nil
+	Capabilities *Capabilities
+	// MaxColumns and MaxLines limit the size of the code drawn by the
+	// Fallbacks, a limit of 0 is no limit
+	MaxColumns, MaxLines int
+
+	middleware []Middleware
+	// lenient is set by the functions that can not return an error, which
+	// draw with fallback glyphs rather than draw nothing
+	lenient bool
 }
 
+// IsSixelSupported reports whether the terminal behind w renders sixel
+// graphics, as found by the registered probers
 func IsSixelSupported(w io.Writer) bool {
-	if w != os.Stdout {
-		return false
-	}
-	stdout := os.Stdout
-	if !term.IsTerminal(int(stdout.Fd())) {
-		return false
-	}
-	_, err := stdout.Write([]byte("\x1B[c"))
-	if err != nil {
-		return false
-	}
-	buf := make([]byte, 1024)
-	//set echo off
-	raw, err := term.MakeRaw(int(stdout.Fd()))
-	defer term.Restore(int(stdout.Fd()), raw)
-	_, err = stdout.Read(buf)
-	if err != nil {
-		return false
-	}
-	for _, b := range string(buf) {
-		if b == '4' {
-			//Found Sixel Support
-			return true
-		}
-	}
-	return false
+	return DetectCapabilities(w).Sixel
 }
 
-func (c *Config) writeSixel(w io.Writer, code *qr.Code) {
-	size := SIXEL_BLOCK_SIZE
+// sixelModuleSize returns the side of a module in pixels for sixel output
+func sixelModuleSize(code *Bitmap) int {
 	if code.Size > 50 {
-		size /= 2
+		return SIXEL_BLOCK_SIZE / 2
+	}
+	return SIXEL_BLOCK_SIZE
+}
+
+func (c *Config) writeSixel(w io.Writer, code *Bitmap) {
+	size := sixelModuleSize(code)
+	// generate has checked the version
+	if spec, _ := outputSpecFor(c.OutputVersion); spec.sixelImage {
+		sixel.Encode(w, code.Image(c.QuietZone), &sixel.Options{Scale: size})
+		return
 	}
 	line := size / 6
 	// Frame the barcode in a 1 pixel border
@@ -129,29 +212,35 @@ func (c *Config) writeSixel(w io.Writer, code *qr.Code) {
 	defer w.Write([]byte(SIXEL_END))
 }
 
-func (c *Config) writeFullBlocks(w io.Writer, code *qr.Code) {
-	white := c.WhiteChar
-	black := c.BlackChar
+func (c *Config) writeFullBlocks(w io.Writer, code *Bitmap) {
+	across, down := c.moduleScale()
+	white := stringRepeat(c.WhiteChar, across)
+	black := stringRepeat(c.BlackChar, across)
+	quiet := stringRepeat(c.QuietChar, across)
 
 	// Frame the barcode in a 1 pixel border
-	w.Write([]byte(stringRepeat(stringRepeat(white,
-		code.Size+c.QuietZone*2)+"\n", c.QuietZone))) // top border
+	w.Write([]byte(stringRepeat(stringRepeat(quiet,
+		code.Size+c.QuietZone*2)+"\n", c.QuietZone*down))) // top border
 	for i := 0; i <= code.Size; i++ {
-		w.Write([]byte(stringRepeat(white, c.QuietZone))) // left border
+		var row strings.Builder
+		row.WriteString(stringRepeat(quiet, c.QuietZone)) // left border
 		for j := 0; j <= code.Size; j++ {
 			if code.Black(j, i) {
-				w.Write([]byte(black))
+				row.WriteString(black)
+			} else if i == code.Size || j == code.Size {
+				row.WriteString(quiet)
 			} else {
-				w.Write([]byte(white))
+				row.WriteString(white)
 			}
 		}
-		w.Write([]byte(stringRepeat(white, c.QuietZone-1) + "\n")) // right border
+		row.WriteString(stringRepeat(quiet, c.QuietZone-1) + "\n") // right border
+		w.Write([]byte(stringRepeat(row.String(), down)))
 	}
-	w.Write([]byte(stringRepeat(stringRepeat(white,
-		code.Size+c.QuietZone*2)+"\n", c.QuietZone-1))) // bottom border
+	w.Write([]byte(stringRepeat(stringRepeat(quiet,
+		code.Size+c.QuietZone*2)+"\n", (c.QuietZone-1)*down))) // bottom border
 }
 
-func (c *Config) writeHalfBlocks(w io.Writer, code *qr.Code) {
+func (c *Config) writeHalfBlocks(w io.Writer, code *Bitmap) {
 	ww := c.WhiteChar
 	bb := c.BlackChar
 	wb := c.WhiteBlackChar
@@ -204,52 +293,252 @@ func stringRepeat(s string, count int) string {
 	return strings.Repeat(s, count)
 }
 
-// GenerateWithConfig expects a string to encode and a config
+// GenerateWithConfig expects a string to encode and a config. It can not
+// report errors: half block glyphs that ErrHalfBlockGlyphs would refuse
+// are mixed with the default ones, and nothing is drawn for a config that
+// Validate refuses. Use GenerateText to get the errors.
 func GenerateWithConfig(text string, config Config) {
-	if config.QuietZone < 1 {
-		config.QuietZone = 1 // at least 1-pixel-wide white quiet zone
+	config.lenient = true
+	generate([]byte(text), config)
+}
+
+// Validate returns the error a config fails with before any data is
+// encoded: ErrOutputVersion, an unknown Symbology or ErrHalfBlockGlyphs.
+// Callers of GenerateWithConfig and the other functions that return no
+// error can check their config with it.
+func (c Config) Validate() error {
+	spec, err := outputSpecFor(c.OutputVersion)
+	if err != nil {
+		return err
+	}
+	if c.Symbology != "" && !validSymbology(c.Symbology) {
+		return fmt.Errorf("qrterminal: unknown symbology %q", c.Symbology)
+	}
+	name := c.format()
+	if name == FormatHalfBlocks && spec.deriveHalfBlocks {
+		c.Theme.apply(&c, name)
+		return c.deriveHalfBlocks(spec.glyphs)
+	}
+	return nil
+}
+
+// ErrEmptyPayload is returned by GenerateText for text that is empty or
+// only whitespace, unless Config.AllowEmpty is set
+var ErrEmptyPayload = errors.New("qrterminal: nothing to encode")
+
+// GenerateText writes the code of text as configured, like
+// GenerateWithConfig, and returns the error that function drops. Text that
+// is empty or only whitespace is rejected with ErrEmptyPayload, or replaced
+// by Config.Placeholder with Config.AllowEmpty. Binary data is never
+// checked, whitespace bytes are data like any other.
+func GenerateText(text string, config Config) error {
+	if strings.TrimSpace(text) == "" {
+		if !config.AllowEmpty {
+			return ErrEmptyPayload
+		}
+		if config.Placeholder != "" {
+			text = config.Placeholder
+		}
+	}
+	return generate([]byte(text), config)
+}
+
+// Output is an additional destination of a code, see Config.Outputs. It
+// is not treated as a terminal: the Profile, Hyperlink and OnLine of the
+// Config do not apply to it.
+type Output struct {
+	Writer io.Writer
+	// Format is the format written to Writer, the format of the Config if
+	// empty. A different format is drawn with its default glyphs.
+	Format Format
+}
+
+// config returns the Config that renders o, derived from c
+func (o Output) config(c Config) Config {
+	c.Writer = o.Writer
+	c.Outputs = nil
+	c.Profile = nil
+	c.Hyperlink = false
+	c.OnLine = nil
+	if o.Format != "" && o.Format != c.format() {
+		c.Format = o.Format
+		c.HalfBlocks, c.WithSixel = false, false
+		c.BlackChar, c.WhiteChar, c.BlackWhiteChar, c.WhiteBlackChar = "", "", "", ""
+		c.QuietChar, c.QuietColor = "", ""
+		c.Theme, c.Backdrop = nil, nil
+	}
+	return c
+}
+
+// generate renders data as configured, to Writer and then every Output. It
+// returns the first error, if data can not be encoded or a writer fails.
+func generate(data []byte, config Config) error {
+	if len(config.Outputs) > 0 {
+		outputs := config.Outputs
+		config.Outputs = nil
+		err := generate(data, config)
+		for _, o := range outputs {
+			if oerr := generate(data, o.config(config)); err == nil {
+				err = oerr
+			}
+		}
+		return err
+	}
+	config.Profile.adjust(&config)
+	if len(config.Fallbacks) > 0 {
+		var err error
+		if config, _, err = config.fallback(data); err != nil {
+			return err
+		}
+	}
+
+	spec, err := outputSpecFor(config.OutputVersion)
+	if err != nil {
+		return err
+	}
+	if config.QuietZone < spec.minQuietZone {
+		config.QuietZone = spec.minQuietZone // at least 1-pixel-wide white quiet zone
 	}
 	w := config.Writer
-	code, _ := qr.Encode(text, config.Level)
+
+	bm, err := config.encode(data)
+	if err != nil {
+		return err
+	}
+
+	name := config.format()
+	config.Theme.apply(&config, name)
+	if err := config.checkGlyphs(name); err != nil {
+		return err
+	}
+	if name == FormatHalfBlocks && spec.deriveHalfBlocks {
+		if err := config.deriveHalfBlocks(spec.glyphs); err != nil && !config.lenient {
+			return err
+		}
+	}
 
 	// Set default values for characters if not provided
 	if config.BlackChar == "" {
-		config.BlackChar = BLACK_BLACK
+		config.BlackChar = spec.glyphs.Black
 	}
 	if config.WhiteBlackChar == "" {
-		config.WhiteBlackChar = WHITE_BLACK
+		config.WhiteBlackChar = spec.glyphs.WhiteBlack
 	}
 	if config.WhiteChar == "" {
-		config.WhiteChar = WHITE_WHITE
+		config.WhiteChar = spec.glyphs.White
 	}
 	if config.BlackWhiteChar == "" {
-		config.BlackWhiteChar = BLACK_WHITE
+		config.BlackWhiteChar = spec.glyphs.BlackWhite
+	}
+	if config.QuietChar == "" {
+		config.QuietChar = config.WhiteChar
+	}
+	if config.QuietColor != "" {
+		config.QuietChar = "\033[" + config.QuietColor + "m" + config.QuietChar + sgrReset
 	}
 
-	if config.HalfBlocks {
-		config.writeHalfBlocks(w, code)
-	} else if config.WithSixel {
-		config.writeSixel(w, code)
-	} else {
-		config.writeFullBlocks(w, code)
+	format, ok := formatSpecFor(name)
+	if !ok {
+		format, _ = formatSpecFor(FormatBlocks)
+	}
+	render := config.chain(func(w io.Writer, bm *Bitmap) error {
+		ew := &errWriter{w: w}
+		if format.text {
+			profile := config.Profile
+			if config.LowMemory {
+				profile = profile.unbuffered()
+			}
+			profile.render(ew, func(w io.Writer) {
+				if config.DebugOverlay && (name == FormatBlocks || name == FormatHalfBlocks) && config.drawOverlay(w, bm, name == FormatHalfBlocks) {
+					return
+				}
+				if config.Backdrop != nil && (name == FormatBlocks || name == FormatHalfBlocks || name == FormatDoubleSize) {
+					if name == FormatDoubleSize {
+						w = &doubleHeightWriter{w: w}
+					}
+					config.Backdrop.draw(w, bm, config.QuietZone, name == FormatHalfBlocks)
+					return
+				}
+				format.render(&config, w, bm)
+			})
+		} else if format.writer != nil {
+			if err := format.writer.WriteFormat(ew, bm, config); err != nil {
+				return err
+			}
+		} else {
+			format.render(&config, ew, bm)
+		}
+		// Text formats end with a newline, sixel does not
+		newline := !format.text
+		if config.Hyperlink && spec.hyperlink && format.annotate && writeHyperlink(ew, data, newline) {
+			newline = false
+		}
+		if config.BackupText && format.annotate {
+			backup, _ := config.payload(data)
+			writeBackupText(ew, backup, newline)
+		}
+		return ew.err
+	})
+	if config.OnLine != nil && format.text {
+		lw := &lineFuncWriter{w: w, fn: config.OnLine}
+		defer lw.flush()
+		w = lw
+	}
+	return render(w, bm)
+}
+
+// lineFuncWriter calls fn with every complete line written to it, and
+// passes all writes on to w if it is not nil
+type lineFuncWriter struct {
+	w       io.Writer
+	fn      func(line string)
+	partial []byte
+}
+
+func (l *lineFuncWriter) Write(p []byte) (int, error) {
+	if l.w != nil {
+		if n, err := l.w.Write(p); err != nil {
+			return n, err
+		}
+	}
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.fn(string(bytes.TrimSuffix(l.partial[:i], []byte("\r"))))
+		l.partial = l.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush hands a last line without a line ending to fn
+func (l *lineFuncWriter) flush() {
+	if len(l.partial) > 0 {
+		l.fn(string(l.partial))
+		l.partial = nil
 	}
 }
 
 // Generate a QR Code and write it out to io.Writer
-func Generate(text string, l qr.Level, w io.Writer) {
+func Generate(text string, l Level, w io.Writer) {
 	config := Config{
 		Level:     l,
 		Writer:    w,
 		BlackChar: BLACK,
 		WhiteChar: WHITE,
 		QuietZone: QUIET_ZONE,
+		Profile:   DetectProfile(w),
 	}
-	config.WithSixel = IsSixelSupported(w)
+	caps := DetectCapabilities(w)
+	config.WithSixel = caps.Sixel
+	config.Hyperlink = caps.Hyperlink
 	GenerateWithConfig(text, config)
 }
 
 // Generate a QR Code with half blocks and write it out to io.Writer
-func GenerateHalfBlock(text string, l qr.Level, w io.Writer) {
+func GenerateHalfBlock(text string, l Level, w io.Writer) {
 	config := Config{
 		Level:          l,
 		Writer:         w,
@@ -266,59 +555,35 @@ func GenerateHalfBlock(text string, l qr.Level, w io.Writer) {
 // GenerateBinary generates a QR Code from binary data and writes it out to io.Writer
 // This function encodes the actual binary data without any string conversion,
 // preserving the exact byte values in the QR code.
-func GenerateBinary(data []byte, l qr.Level, w io.Writer) {
+func GenerateBinary(data []byte, l Level, w io.Writer) {
 	config := Config{
 		Level:     l,
 		Writer:    w,
 		BlackChar: BLACK,
 		WhiteChar: WHITE,
 		QuietZone: QUIET_ZONE,
+		Profile:   DetectProfile(w),
 	}
-	config.WithSixel = IsSixelSupported(w)
+	caps := DetectCapabilities(w)
+	config.WithSixel = caps.Sixel
+	config.Hyperlink = caps.Hyperlink
 	GenerateBinaryWithConfig(data, config)
 }
 
 // GenerateBinaryWithConfig generates a QR Code from binary data using the provided config
 // This function encodes the actual binary data without any string conversion,
 // preserving the exact byte values in the QR code.
+// Like GenerateWithConfig it mixes incomplete half block glyphs with the
+// default ones, GenerateFromReader returns the error instead.
 func GenerateBinaryWithConfig(data []byte, config Config) {
-	if config.QuietZone < 1 {
-		config.QuietZone = 1 // at least 1-pixel-wide white quiet zone
-	}
-	w := config.Writer
-
-	// Convert binary data to string for QR encoding
-	// This is safe for binary data as we're encoding the exact byte values
-	text := string(data)
-	code, _ := qr.Encode(text, config.Level)
-
-	// Set default values for characters if not provided
-	if config.BlackChar == "" {
-		config.BlackChar = BLACK_BLACK
-	}
-	if config.WhiteBlackChar == "" {
-		config.WhiteBlackChar = WHITE_BLACK
-	}
-	if config.WhiteChar == "" {
-		config.WhiteChar = WHITE_WHITE
-	}
-	if config.BlackWhiteChar == "" {
-		config.BlackWhiteChar = BLACK_WHITE
-	}
-
-	if config.HalfBlocks {
-		config.writeHalfBlocks(w, code)
-	} else if config.WithSixel {
-		config.writeSixel(w, code)
-	} else {
-		config.writeFullBlocks(w, code)
-	}
+	config.lenient = true
+	generate(data, config)
 }
 
 // GenerateBinaryHalfBlock generates a QR Code from binary data with half blocks and writes it out to io.Writer
 // This function encodes the actual binary data without any string conversion,
 // preserving the exact byte values in the QR code.
-func GenerateBinaryHalfBlock(data []byte, l qr.Level, w io.Writer) {
+func GenerateBinaryHalfBlock(data []byte, l Level, w io.Writer) {
 	config := Config{
 		Level:          l,
 		Writer:         w,
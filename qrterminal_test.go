@@ -3,6 +3,7 @@ package qrterminal
 import (
 	"bytes"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -60,7 +61,7 @@ func TestCaptureOutput(t *testing.T) {
 	testCases := []struct {
 		name       string
 		input      string
-		level      qr.Level
+		level      Level
 		halfBlocks bool
 	}{
 		{"BasicURL", "https://example.com", L, false},
@@ -635,7 +636,7 @@ func TestBinaryDataRoundTrip(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Test 1: Verify that the underlying QR encoding can handle the data
 			text := string(tc.data)
-			qrCode, err := qr.Encode(text, L)
+			qrCode, err := qr.Encode(text, L.QR())
 			if err != nil {
 				t.Fatalf("Failed to encode data into QR code: %v", err)
 			}
@@ -778,7 +779,7 @@ func TestBinaryDataIntegrity(t *testing.T) {
 
 			// Verify the underlying QR library can handle this data
 			text := string(tc.data)
-			_, err := qr.Encode(text, M)
+			_, err := qr.Encode(text, M.QR())
 			if err != nil {
 				t.Errorf("QR library failed to encode binary data: %v", err)
 			}
@@ -817,3 +818,68 @@ func TestBinaryDataIntegrity(t *testing.T) {
 		})
 	}
 }
+
+func TestOnLine(t *testing.T) {
+	var out bytes.Buffer
+	var lines []string
+	config := Config{Level: L, Writer: &out, HalfBlocks: true, OnLine: func(line string) {
+		lines = append(lines, line)
+	}}
+	GenerateWithConfig("test", config)
+	if want := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected the lines of the output, got %q", lines)
+	}
+
+	// Without a writer, lines only go to the callback
+	lines = nil
+	config.Writer = nil
+	GenerateWithConfig("test", config)
+	if len(lines) != len(strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")) {
+		t.Errorf("Expected every line without a writer, got %d", len(lines))
+	}
+
+	// Formats that are not text are not split into lines
+	lines = nil
+	out.Reset()
+	config.Writer, config.Format = &out, FormatSixel
+	GenerateWithConfig("test", config)
+	if len(lines) != 0 || out.Len() == 0 {
+		t.Errorf("Sixel output should only go to the writer")
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	const url = "https://github.com/mdp/qrterminal"
+	render := func(c Config) string {
+		var buf bytes.Buffer
+		c.Writer = &buf
+		GenerateWithConfig(url, c)
+		return buf.String()
+	}
+
+	var term, log, bits bytes.Buffer
+	config := Config{
+		Level:     M,
+		Writer:    &term,
+		BlackChar: BLACK,
+		WhiteChar: WHITE,
+		QuietZone: 2,
+		Hyperlink: true,
+		Profile:   ProfileXtermJS,
+		Outputs: []Output{
+			{Writer: &log},
+			{Writer: &bits, Format: FormatBits},
+		},
+	}
+	GenerateWithConfig(url, config)
+
+	if want := render(Config{Level: M, BlackChar: BLACK, WhiteChar: WHITE, QuietZone: 2, Hyperlink: true, Profile: ProfileXtermJS}); term.String() != want {
+		t.Errorf("The terminal output should not change, got\n%s", term.String())
+	}
+	if want := render(Config{Level: M, BlackChar: BLACK, WhiteChar: WHITE, QuietZone: 2}); log.String() != want {
+		t.Errorf("An output without a format should get the same code without the terminal adjustments, got\n%s", log.String())
+	}
+	if want := render(Config{Level: M, QuietZone: 2, Format: FormatBits}); bits.String() != want {
+		t.Errorf("An output with a format should be drawn in it with its defaults, got\n%s", bits.String())
+	}
+}
@@ -0,0 +1,87 @@
+package qrterminal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// minQuietContrast is the contrast ratio, as defined by WCAG 2, that the
+// quiet zone needs against black modules to be found reliably by scanners
+const minQuietContrast = 4.5
+
+// ansiColors are the RGB values of the 16 ANSI colors, as xterm draws them
+var ansiColors = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// sgrBackground returns the background color set by the SGR parameters
+// params, e.g. "1;107" or "48;2;255;255;255". The last background wins, ok
+// is false if there is none or params can not be parsed.
+func sgrBackground(params string) (rgb [3]uint8, ok bool) {
+	var p []int
+	for _, s := range strings.Split(params, ";") {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return rgb, false
+		}
+		p = append(p, n)
+	}
+	for i := 0; i < len(p); i++ {
+		switch n := p[i]; {
+		case n >= 40 && n <= 47:
+			rgb, ok = ansiColors[n-40], true
+		case n >= 100 && n <= 107:
+			rgb, ok = ansiColors[n-100+8], true
+		case n == 49:
+			rgb, ok = [3]uint8{}, false
+		case (n == 38 || n == 48) && i+2 < len(p) && p[i+1] == 5:
+			if n == 48 && p[i+2] < 256 {
+				rgb, ok = xterm256(p[i+2]), true
+			}
+			i += 2
+		case (n == 38 || n == 48) && i+4 < len(p) && p[i+1] == 2:
+			if n == 48 && p[i+2] < 256 && p[i+3] < 256 && p[i+4] < 256 {
+				rgb, ok = [3]uint8{uint8(p[i+2]), uint8(p[i+3]), uint8(p[i+4])}, true
+			}
+			i += 4
+		}
+	}
+	return rgb, ok
+}
+
+// xterm256 returns the RGB value of color n of the xterm 256 color palette
+func xterm256(n int) [3]uint8 {
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + 40*v)
+		}
+		return [3]uint8{level(n / 36), level(n / 6 % 6), level(n % 6)}
+	default:
+		v := uint8(8 + 10*(n-232))
+		return [3]uint8{v, v, v}
+	}
+}
+
+// contrastToBlack returns the WCAG 2 contrast ratio of rgb against black
+func contrastToBlack(rgb [3]uint8) float64 {
+	linear := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	l := 0.2126*linear(rgb[0]) + 0.7152*linear(rgb[1]) + 0.0722*linear(rgb[2])
+	return (l + 0.05) / 0.05
+}
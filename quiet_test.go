@@ -0,0 +1,51 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQuietZoneStyle(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 2,
+		BlackChar: "  ", WhiteChar: "██", QuietChar: "░░", QuietColor: "107"})
+	lines := strings.Split(buf.String(), "\n")
+	quiet := "\033[107m░░" + sgrReset
+	if want := strings.Repeat(quiet, 25); lines[0] != want {
+		t.Errorf("Expected the top border in the quiet style, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], quiet+quiet+"  ") {
+		t.Errorf("Expected the left border in the quiet style, got %q", lines[2])
+	}
+	if strings.Count(buf.String(), "██") == 0 {
+		t.Errorf("White modules should keep their own glyph")
+	}
+}
+
+func TestSGRBackground(t *testing.T) {
+	testCases := []struct {
+		params string
+		rgb    [3]uint8
+		ok     bool
+	}{
+		{"47", [3]uint8{229, 229, 229}, true},
+		{"1;107", [3]uint8{255, 255, 255}, true},
+		{"48;5;231", [3]uint8{255, 255, 255}, true},
+		{"48;5;16", [3]uint8{0, 0, 0}, true},
+		{"48;5;244", [3]uint8{128, 128, 128}, true},
+		{"38;5;15;48;2;1;2;3", [3]uint8{1, 2, 3}, true},
+		{"44;49", [3]uint8{}, false},
+		{"97", [3]uint8{}, false},
+		{"x", [3]uint8{}, false},
+	}
+	for _, tc := range testCases {
+		rgb, ok := sgrBackground(tc.params)
+		if ok != tc.ok || (ok && rgb != tc.rgb) {
+			t.Errorf("sgrBackground(%q) = %v, %v, expected %v, %v", tc.params, rgb, ok, tc.rgb, tc.ok)
+		}
+	}
+	if r := contrastToBlack([3]uint8{255, 255, 255}); r < 20.9 || r > 21.1 {
+		t.Errorf("White should have a contrast of 21:1 to black, got %.2f", r)
+	}
+}
@@ -0,0 +1,32 @@
+package qrterminal
+
+import (
+	"errors"
+	"io"
+)
+
+// maxDataBytes is the most data a QR code holds, a version 40 code at level
+// L in byte mode
+const maxDataBytes = 2953
+
+// ErrInputTooLarge is returned by GenerateFromReader when the input exceeds
+// its limit
+var ErrInputTooLarge = errors.New("qrterminal: input exceeds the size limit")
+
+// GenerateFromReader reads at most limit bytes from r and writes their QR
+// code as configured. Input longer than limit is rejected with
+// ErrInputTooLarge without being read in full. A limit of zero or less
+// means the capacity of the largest QR code.
+func GenerateFromReader(r io.Reader, limit int64, config Config) error {
+	if limit <= 0 {
+		limit = maxDataBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > limit {
+		return ErrInputTooLarge
+	}
+	return generate(data, config)
+}
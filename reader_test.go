@@ -0,0 +1,55 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFromReader(t *testing.T) {
+	text := "https://github.com/mdp/qrterminal"
+	var want, got bytes.Buffer
+	GenerateWithConfig(text, Config{Level: M, Writer: &want, QuietZone: QUIET_ZONE})
+
+	err := GenerateFromReader(strings.NewReader(text), 64, Config{Level: M, Writer: &got, QuietZone: QUIET_ZONE})
+	if err != nil {
+		t.Fatalf("GenerateFromReader failed: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("Output differs from GenerateWithConfig")
+	}
+}
+
+func TestGenerateFromReaderLimit(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{Level: L, Writer: &buf}
+
+	if err := GenerateFromReader(strings.NewReader("12345"), 5, config); err != nil {
+		t.Errorf("Input at the limit should be accepted, got %v", err)
+	}
+
+	buf.Reset()
+	err := GenerateFromReader(strings.NewReader("123456"), 5, config)
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Nothing should be written for rejected input")
+	}
+
+	// The default limit is the capacity of the largest code
+	big := strings.Repeat("x", maxDataBytes+1)
+	if err := GenerateFromReader(strings.NewReader(big), 0, config); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge with the default limit, got %v", err)
+	}
+}
+
+func TestGenerateFromReaderTooLongForLevel(t *testing.T) {
+	// Fits the default limit but not a level H code
+	data := strings.Repeat("x", maxDataBytes)
+	err := GenerateFromReader(strings.NewReader(data), 0, Config{Level: H, Writer: &bytes.Buffer{}})
+	if err == nil {
+		t.Errorf("Expected an error for data that does not fit the code")
+	}
+}
@@ -0,0 +1,69 @@
+package qrterminal
+
+import "fmt"
+
+// BestFit returns config adjusted to draw data within cols columns and
+// lines lines: unchanged if it fits, otherwise switched to
+// FormatHalfBlocks, which is half as tall, with the half block glyphs of
+// its Theme or the defaults. If nothing fits the half block config is
+// returned, the smallest there is.
+func BestFit(data []byte, config Config, cols, lines int) (Config, error) {
+	w, h, err := RenderedSize(data, config)
+	if err != nil {
+		return config, err
+	}
+	if (w <= cols && h <= lines) || config.format() == FormatHalfBlocks {
+		return config, nil
+	}
+	half := config
+	half.Format = FormatHalfBlocks
+	half.BlackChar, half.WhiteChar, half.BlackWhiteChar, half.WhiteBlackChar = "", "", "", ""
+	half.QuietChar = ""
+	return half, nil
+}
+
+// BudgetError is returned by FitTo when no config draws the code in the
+// budget, with the smallest budget that one does
+type BudgetError struct {
+	Cols, Rows int
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("qrterminal: the code needs at least %d columns and %d rows", e.Cols, e.Rows)
+}
+
+// FitTo returns the Config that draws data within maxCols columns and
+// maxRows rows, e.g. in a fixed pane of a TUI. It prefers, in this order,
+// FormatBlocks over FormatHalfBlocks, whose modules are smaller, a higher
+// error correction level and a wider quiet zone, down to one module. The
+// Writer of the Config is not set. If nothing fits the error is a
+// *BudgetError.
+func FitTo(data []byte, maxCols, maxRows int) (Config, error) {
+	var smallest *BudgetError
+	for _, glyphs := range []Glyphs{GlyphsANSI, GlyphsHalfBlocks} {
+		for level := H; level >= L; level-- {
+			for quiet := QUIET_ZONE; quiet >= 1; quiet-- {
+				config := Config{Level: level, QuietZone: quiet, BlackChar: glyphs.Black, WhiteChar: glyphs.White}
+				if glyphs.BlackWhite != "" {
+					config.Format = FormatHalfBlocks
+					config.BlackWhiteChar, config.WhiteBlackChar = glyphs.BlackWhite, glyphs.WhiteBlack
+				}
+				cols, rows, err := RenderedSize(data, config)
+				if err != nil {
+					// The data does not fit in a code of this level
+					break
+				}
+				if cols <= maxCols && rows <= maxRows {
+					return config, nil
+				}
+				smallest = &BudgetError{cols, rows}
+			}
+		}
+	}
+	if smallest == nil {
+		// Not even level L holds the data
+		_, err := Encode(data, Config{Level: L})
+		return Config{}, err
+	}
+	return Config{}, smallest
+}
@@ -0,0 +1,42 @@
+//go:build !unix && !tinygo
+
+package qrterminal
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// resizePoll is how often the size of the terminal is checked where there
+// is no resize signal, such as the Windows console
+const resizePoll = 250 * time.Millisecond
+
+// notifyResize signals when the terminal f is resized, by polling its size
+func notifyResize(ctx context.Context, f *os.File) <-chan struct{} {
+	resized := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(resizePoll)
+		defer ticker.Stop()
+		cols, lines, _ := term.GetSize(int(f.Fd()))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c, l, err := term.GetSize(int(f.Fd()))
+				if err != nil || (c == cols && l == lines) {
+					continue
+				}
+				cols, lines = c, l
+				select {
+				case resized <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return resized
+}
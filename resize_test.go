@@ -0,0 +1,86 @@
+package qrterminal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBestFit(t *testing.T) {
+	data := []byte("https://github.com/mdp/qrterminal")
+	config := Config{Level: L, QuietZone: 2, BlackChar: BLACK, WhiteChar: WHITE}
+	w, h, _ := RenderedSize(data, config)
+
+	fit, err := BestFit(data, config, w, h)
+	if err != nil {
+		t.Fatalf("BestFit failed: %v", err)
+	}
+	if fit.format() != FormatBlocks || fit.BlackChar != BLACK {
+		t.Errorf("A config that fits should be kept")
+	}
+
+	fit, err = BestFit(data, config, w, h-1)
+	if err != nil {
+		t.Fatalf("BestFit failed: %v", err)
+	}
+	if fit.format() != FormatHalfBlocks {
+		t.Fatalf("Expected half blocks for a short terminal, got %s", fit.format())
+	}
+	fw, fh, err := RenderedSize(data, fit)
+	if err != nil {
+		t.Fatalf("The half block config can not be drawn: %v", err)
+	}
+	if fw > w || fh > h-1 {
+		t.Errorf("Expected the half blocks to fit in %dx%d, got %dx%d", w, h-1, fw, fh)
+	}
+
+	// Nothing fits, the smallest config is returned
+	fit, _ = BestFit(data, config, 1, 1)
+	if fit.format() != FormatHalfBlocks {
+		t.Errorf("Expected half blocks when nothing fits, got %s", fit.format())
+	}
+}
+
+func TestFitTo(t *testing.T) {
+	data := []byte("https://github.com/mdp/qrterminal")
+
+	// A large budget gets blocks at level H with the full quiet zone
+	config, err := FitTo(data, 500, 500)
+	if err != nil {
+		t.Fatalf("FitTo failed: %v", err)
+	}
+	if config.format() != FormatBlocks || config.Level != H || config.QuietZone != QUIET_ZONE {
+		t.Errorf("Expected blocks at level H, got %s at %s with quiet zone %d", config.format(), config.Level, config.QuietZone)
+	}
+
+	// Budgets just large enough for a smaller config give that config
+	for _, want := range []Config{
+		{Level: L, QuietZone: 1, BlackChar: BLACK, WhiteChar: WHITE},
+		{Level: Q, QuietZone: 2, Format: FormatHalfBlocks},
+		{Level: L, QuietZone: 1, Format: FormatHalfBlocks},
+	} {
+		cols, rows, _ := RenderedSize(data, want)
+		config, err := FitTo(data, cols, rows)
+		if err != nil {
+			t.Fatalf("FitTo(%d, %d) failed: %v", cols, rows, err)
+		}
+		w, h, _ := RenderedSize(data, config)
+		if w > cols || h > rows {
+			t.Errorf("FitTo(%d, %d) is %dx%d", cols, rows, w, h)
+		}
+		if config.format() != want.format() || config.Level < want.Level {
+			t.Errorf("FitTo(%d, %d) is %s at %s, expected %s at %s or higher", cols, rows, config.format(), config.Level, want.format(), want.Level)
+		}
+	}
+
+	// The error reports the smallest budget
+	cols, rows, _ := RenderedSize(data, Config{Level: L, QuietZone: 1, Format: FormatHalfBlocks})
+	_, err = FitTo(data, cols-1, rows)
+	var budget *BudgetError
+	if !errors.As(err, &budget) || budget.Cols != cols || budget.Rows != rows {
+		t.Errorf("Expected a budget of %dx%d, got %v", cols, rows, err)
+	}
+
+	if _, err := FitTo(make([]byte, 4000), 500, 500); err == nil || errors.As(err, &budget) {
+		t.Errorf("Expected an encoding error for data that does not fit, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package qrterminal
+
+import (
+	"fmt"
+
+	"rsc.io/qr/coding"
+)
+
+// ModuleRole is the part of a symbol a module belongs to, e.g. to style
+// the data modules without touching the patterns scanners look for
+type ModuleRole uint8
+
+const (
+	// RoleUnknown is the role of modules outside the grid, and of every
+	// module of a Bitmap not made by the built in QR encoder
+	RoleUnknown ModuleRole = iota
+	// RoleFinder are the three finder patterns in the corners and the
+	// white separators around them
+	RoleFinder
+	// RoleTiming are the rows of alternating modules between the finder
+	// patterns
+	RoleTiming
+	// RoleAlignment are the alignment patterns of version 2 and up
+	RoleAlignment
+	// RoleFormat is the format information, including the dark module
+	// next to it
+	RoleFormat
+	// RoleVersion is the version information of version 7 and up
+	RoleVersion
+	// RoleData are the modules of the data codewords and the remainder
+	// bits after them
+	RoleData
+	// RoleECC are the modules of the error correction codewords
+	RoleECC
+)
+
+var roleNames = [...]string{
+	RoleUnknown:   "unknown",
+	RoleFinder:    "finder",
+	RoleTiming:    "timing",
+	RoleAlignment: "alignment",
+	RoleFormat:    "format",
+	RoleVersion:   "version",
+	RoleData:      "data",
+	RoleECC:       "ecc",
+}
+
+func (r ModuleRole) String() string {
+	if int(r) >= len(roleNames) {
+		return fmt.Sprintf("ModuleRole(%d)", int(r))
+	}
+	return roleNames[r]
+}
+
+// Role returns the role of the module at (x, y)
+func (b *Bitmap) Role(x, y int) ModuleRole {
+	if b.roles == nil || x < 0 || x >= b.Size || y < 0 || y >= b.Size {
+		return RoleUnknown
+	}
+	return b.roles[y*b.Size+x]
+}
+
+// qrRoles returns the role of every module of a QR code with size modules
+// per side at level, row by row, or nil if no QR version has that size.
+// Where the codewords go only depends on the version and level, not on the
+// data or mask.
+func qrRoles(size int, level Level) []ModuleRole {
+	if size < 21 || (size-17)%4 != 0 || (size-17)/4 > coding.MaxVersion {
+		return nil
+	}
+	plan, err := coding.NewPlan(coding.Version((size-17)/4), coding.Level(level.QR()), 0)
+	if err != nil {
+		return nil
+	}
+	roles := make([]ModuleRole, 0, size*size)
+	for _, row := range plan.Pixel {
+		for _, p := range row {
+			var role ModuleRole
+			switch p.Role() {
+			case coding.Position:
+				role = RoleFinder
+			case coding.Timing:
+				role = RoleTiming
+			case coding.Alignment:
+				role = RoleAlignment
+			case coding.Format, coding.Unused:
+				role = RoleFormat
+			case coding.PVersion:
+				role = RoleVersion
+			case coding.Check:
+				role = RoleECC
+			default:
+				role = RoleData
+			}
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
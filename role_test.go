@@ -0,0 +1,63 @@
+package qrterminal
+
+import "testing"
+
+func TestBitmapRole(t *testing.T) {
+	tests := []struct {
+		version int
+		x, y    int
+		want    ModuleRole
+	}{
+		{1, 0, 0, RoleFinder},
+		{1, 7, 7, RoleFinder}, // separator
+		{1, 10, 6, RoleTiming},
+		{1, 6, 10, RoleTiming},
+		{1, 8, 0, RoleFormat},
+		{1, 0, 8, RoleFormat},
+		{1, 8, 21 - 8, RoleFormat}, // dark module
+		{1, 20, 20, RoleData},
+		{1, 0, 9, RoleECC},
+		{2, 18, 18, RoleAlignment},
+		{7, 45 - 11, 0, RoleVersion},
+		{7, 0, 45 - 11, RoleVersion},
+		{1, -1, 0, RoleUnknown},
+		{1, 21, 0, RoleUnknown},
+	}
+	for _, tt := range tests {
+		bm := NewBitmap(17 + 4*tt.version)
+		bm.roles = qrRoles(bm.Size, L)
+		if got := bm.Role(tt.x, tt.y); got != tt.want {
+			t.Errorf("Version %d module (%d, %d) has role %s, expected %s", tt.version, tt.x, tt.y, got, tt.want)
+		}
+	}
+	for _, size := range []int{0, 20, 22, 181} {
+		if qrRoles(size, L) != nil {
+			t.Errorf("Expected no roles for size %d", size)
+		}
+	}
+}
+
+func TestEncodeRoles(t *testing.T) {
+	bm, err := Encode([]byte("test"), Config{Level: L})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A version 1 code at level L holds 19 data and 7 error correction
+	// codewords
+	count := map[ModuleRole]int{}
+	for y := 0; y < bm.Size; y++ {
+		for x := 0; x < bm.Size; x++ {
+			count[bm.Role(x, y)]++
+		}
+	}
+	if count[RoleData] != 19*8 || count[RoleECC] != 7*8 {
+		t.Errorf("Expected %d data and %d ecc modules, got %d and %d", 19*8, 7*8, count[RoleData], count[RoleECC])
+	}
+	if count[RoleUnknown] != 0 {
+		t.Errorf("%d modules have no role", count[RoleUnknown])
+	}
+
+	if role := NewBitmap(21).Role(0, 0); role != RoleUnknown {
+		t.Errorf("Expected a new bitmap to have no roles, got %s", role)
+	}
+}
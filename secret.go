@@ -0,0 +1,33 @@
+package qrterminal
+
+import (
+	"io"
+
+	"github.com/katzenpost/qrterminal/v3/secret"
+)
+
+// GenerateSecret draws s as configured and then zeroes it, along with
+// the modules of its code, which give the secret away just as well. The
+// code is never kept in the Cache of config. Copies made elsewhere, such
+// as by the Writer or a Profile that renders the code in memory first,
+// are not wiped.
+func GenerateSecret(s secret.Bytes, config Config) error {
+	defer s.Zero()
+	config.Cache = nil
+	// The outermost middleware, so the modules are wiped after everything
+	// else has drawn them
+	config.middleware = append([]Middleware{wipeModules}, config.middleware...)
+	return generate(s, config)
+}
+
+// wipeModules zeroes the modules of a code once it is drawn
+func wipeModules(next RenderFunc) RenderFunc {
+	return func(w io.Writer, bm *Bitmap) error {
+		defer func() {
+			for i := range bm.pix {
+				bm.pix[i] = false
+			}
+		}()
+		return next(w, bm)
+	}
+}
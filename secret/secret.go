@@ -0,0 +1,33 @@
+// Package secret holds secret bytes, such as an OTP seed, a WiFi password
+// or a private key, so they stay out of logs and can be wiped. It has no
+// dependencies outside the standard library, for the qrterminal package
+// and the TinyGo builds that import it.
+package secret
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// Bytes are secret bytes. They format as a placeholder with every fmt
+// verb, so they do not leak through logs or error messages, and Zero
+// wipes them once they are no longer needed.
+type Bytes []byte
+
+// Format writes the length of s, never its contents
+func (s Bytes) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, "[secret, %d bytes]", len(s))
+}
+
+// Equal reports whether s equals t, in constant time for inputs of the
+// same length
+func (s Bytes) Equal(t []byte) bool {
+	return subtle.ConstantTimeCompare(s, t) == 1
+}
+
+// Zero overwrites s with zeroes
+func (s Bytes) Zero() {
+	for i := range s {
+		s[i] = 0
+	}
+}
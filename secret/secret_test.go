@@ -0,0 +1,30 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	s := Bytes("hunter2")
+	for _, verb := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%X", "%d"} {
+		got := fmt.Sprintf(verb, s)
+		if got != "[secret, 7 bytes]" {
+			t.Errorf("%s: got %q", verb, got)
+		}
+	}
+	if err := fmt.Errorf("bad key %v", s); strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Secret leaked into %q", err)
+	}
+
+	if !s.Equal([]byte("hunter2")) || s.Equal([]byte("hunter3")) || s.Equal([]byte("hunter")) {
+		t.Error("Equal is wrong")
+	}
+
+	s.Zero()
+	if !bytes.Equal(s, make([]byte, 7)) {
+		t.Errorf("Expected zeroes, got %x", []byte(s))
+	}
+}
@@ -0,0 +1,70 @@
+package qrterminal
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/qrterminal/v3/secret"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	var plain, hidden bytes.Buffer
+	GenerateWithConfig("otpauth://totp/x?secret=JBSWY3DP", Config{Level: L, Writer: &plain})
+
+	var drawn *Bitmap
+	config := Config{Level: L, Writer: &hidden, Cache: NewEncodeCache(1)}
+	config.Use(func(next RenderFunc) RenderFunc {
+		return func(w io.Writer, bm *Bitmap) error {
+			drawn = bm
+			return next(w, bm)
+		}
+	})
+	s := secret.Bytes("otpauth://totp/x?secret=JBSWY3DP")
+	if err := GenerateSecret(s, config); err != nil {
+		t.Fatal(err)
+	}
+	if plain.String() != hidden.String() {
+		t.Error("Expected the secret to be drawn as usual")
+	}
+	if !bytes.Equal(s, make([]byte, len(s))) {
+		t.Errorf("Expected the secret to be zeroed, got %q", []byte(s))
+	}
+	for _, black := range drawn.pix {
+		if black {
+			t.Fatal("Expected the modules to be zeroed")
+		}
+	}
+	if len(config.Cache.entries) != 0 {
+		t.Error("Expected the code not to be cached")
+	}
+}
+
+func TestCoreImports(t *testing.T) {
+	// Every importer, TinyGo builds included, would pull in the crypto of
+	// payload
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			if strings.HasSuffix(p, "/payload") || strings.HasPrefix(p, "golang.org/x/crypto/") {
+				t.Errorf("%s imports %s", path, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sideBySideGap is the number of blank columns between two codes
+const sideBySideGap = 4
+
+// ErrNotText is returned by GenerateSideBySide for formats that are not
+// drawn as lines of text, such as sixel
+var ErrNotText = errors.New("qrterminal: the format can not be drawn side by side")
+
+// GenerateSideBySide draws the codes of labels next to each other on the
+// Writer of config, each under its caption, e.g. to compare how two
+// payloads or two scanner apps fare on the same terminal. Every code is
+// drawn with config as GenerateWithConfig would, which has to resolve to a
+// text format; Fallbacks are resolved with the data of the first label.
+func GenerateSideBySide(labels []Label, config Config) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	config.Profile.adjust(&config)
+	if len(config.Fallbacks) > 0 {
+		var err error
+		if config, _, err = config.fallback(labels[0].Data); err != nil {
+			return err
+		}
+	}
+	if format, ok := formatSpecFor(config.format()); ok && !format.text {
+		return ErrNotText
+	}
+	w := config.Writer
+	config.Outputs = nil
+	config.OnLine = nil
+
+	columns := make([][]string, len(labels))
+	widths := make([]int, len(labels))
+	rows := 0
+	for i, l := range labels {
+		if err := config.checkASCII("caption", l.Caption); err != nil {
+			return fmt.Errorf("label %d: %w", i+1, err)
+		}
+		var buf bytes.Buffer
+		config.Writer = &buf
+		if err := generate(l.Data, config); err != nil {
+			return fmt.Errorf("label %d: %w", i+1, err)
+		}
+		lines := append([]string{l.Caption}, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")...)
+		for _, line := range lines {
+			if w := displayWidth([]byte(line)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+		columns[i] = lines
+		if len(lines) > rows {
+			rows = len(lines)
+		}
+	}
+
+	if w == nil {
+		return nil
+	}
+	var out strings.Builder
+	gap := strings.Repeat(" ", sideBySideGap)
+	for y := 0; y < rows; y++ {
+		var row strings.Builder
+		for i, lines := range columns {
+			if i > 0 {
+				row.WriteString(gap)
+			}
+			line := ""
+			if y < len(lines) {
+				line = lines[y]
+			}
+			row.WriteString(line)
+			if i < len(columns)-1 {
+				row.WriteString(strings.Repeat(" ", widths[i]-displayWidth([]byte(line))))
+			}
+		}
+		out.WriteString(row.String() + "\n")
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
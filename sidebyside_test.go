@@ -0,0 +1,51 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSideBySide(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{Level: L, Writer: &buf, QuietZone: 1, Format: FormatHalfBlocks}
+	labels := []Label{{Data: []byte("test"), Caption: "A"}, {Data: []byte("https://github.com/mdp/qrterminal"), Caption: "B"}}
+	if err := GenerateSideBySide(labels, config); err != nil {
+		t.Fatal(err)
+	}
+	var a, b bytes.Buffer
+	config.Writer = &a
+	GenerateWithConfig("test", config)
+	config.Writer = &b
+	GenerateWithConfig("https://github.com/mdp/qrterminal", config)
+	left := strings.Split(strings.TrimSuffix(a.String(), "\n"), "\n")
+	right := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	// The captions and the rows of the taller code
+	if len(lines) != 1+len(right) {
+		t.Fatalf("Expected %d lines, got %d", 1+len(right), len(lines))
+	}
+	width := displayWidth([]byte(left[0]))
+	if want := "A" + strings.Repeat(" ", width-1+sideBySideGap) + "B"; lines[0] != want {
+		t.Errorf("Expected the captions %q, got %q", want, lines[0])
+	}
+	for y, line := range lines[1:] {
+		want := strings.Repeat(" ", width)
+		if y < len(left) {
+			want = left[y]
+		}
+		want += strings.Repeat(" ", sideBySideGap) + right[y]
+		if line != want {
+			t.Fatalf("Line %d is %q, expected %q", y+1, line, want)
+		}
+	}
+
+	if err := GenerateSideBySide(labels, Config{Writer: &buf, Format: FormatSixel}); !errors.Is(err, ErrNotText) {
+		t.Errorf("Expected ErrNotText for sixel, got %v", err)
+	}
+	if err := GenerateSideBySide([]Label{{Data: make([]byte, 4000)}}, Config{Writer: &buf}); err == nil {
+		t.Errorf("Expected an error for data that does not fit")
+	}
+}
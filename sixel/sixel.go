@@ -0,0 +1,173 @@
+// Package sixel encodes images as DEC sixel graphics, which terminals such
+// as xterm, WezTerm, iTerm2 and Windows Terminal draw inline.
+//
+// An image is drawn in bands of six pixel rows. Every band lists, for each
+// color used in it, one character per column whose six low bits select the
+// pixels of that column drawn in the color.
+package sixel
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Options tune the encoding of an image
+type Options struct {
+	// Palette lists the colors the image is drawn with, every pixel takes
+	// the closest one. If nil, the palette of an *image.Paletted is used,
+	// and black and white for any other image.
+	Palette color.Palette
+	// Scale draws every pixel as a square of Scale by Scale pixels, 1 if
+	// zero
+	Scale int
+}
+
+// ErrPalette is returned for a palette that is empty or has more than the
+// 256 colors terminals support
+var ErrPalette = errors.New("sixel: a palette needs 1 to 256 colors")
+
+// ErrScale is returned for a negative Scale
+var ErrScale = errors.New("sixel: scale must not be negative")
+
+// Start and end of a sixel image
+const (
+	Begin = "\x1bPq"
+	End   = "\x1b\\"
+)
+
+// blackAndWhite is the palette of images that are not paletted
+var blackAndWhite = color.Palette{color.Black, color.White}
+
+// Encode writes img to w as a sixel image. The image starts at the cursor,
+// which ends up on the line below it.
+func Encode(w io.Writer, img image.Image, opts *Options) error {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Scale < 0 {
+		return ErrScale
+	}
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	if o.Palette == nil {
+		o.Palette = blackAndWhite
+		if p, ok := img.(*image.Paletted); ok && len(p.Palette) > 0 {
+			o.Palette = p.Palette
+		}
+	}
+	if len(o.Palette) == 0 || len(o.Palette) > 256 {
+		return ErrPalette
+	}
+
+	bounds := img.Bounds()
+	index := make([]uint8, bounds.Dx()*bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			index[y*bounds.Dx()+x] = uint8(o.Palette.Index(img.At(bounds.Min.X+x, bounds.Min.Y+y)))
+		}
+	}
+
+	e := encoder{
+		w:      bufio.NewWriter(w),
+		index:  index,
+		stride: bounds.Dx(),
+		width:  bounds.Dx() * o.Scale,
+		height: bounds.Dy() * o.Scale,
+		scale:  o.Scale,
+	}
+	e.header(o.Palette)
+	for top := 0; top < e.height; top += 6 {
+		e.band(top, len(o.Palette))
+	}
+	e.w.WriteString(End)
+	return e.w.Flush()
+}
+
+type encoder struct {
+	w             *bufio.Writer
+	index         []uint8 // palette index of every source pixel
+	stride        int
+	width, height int // in output pixels
+	scale         int
+}
+
+// header starts the image, declares its size with the raster attributes
+// and defines the palette with RGB components in percent
+func (e *encoder) header(palette color.Palette) {
+	fmt.Fprintf(e.w, "%s\"1;1;%d;%d", Begin, e.width, e.height)
+	for i, c := range palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(e.w, "#%d;2;%d;%d;%d", i, percent(r), percent(g), percent(b))
+	}
+	e.w.WriteString("\n")
+}
+
+// percent scales a 16 bit color component to 0-100
+func percent(v uint32) uint32 {
+	return (v*100 + 0xffff/2) / 0xffff
+}
+
+// at returns the palette index of the output pixel at x, y
+func (e *encoder) at(x, y int) uint8 {
+	return e.index[(y/e.scale)*e.stride+x/e.scale]
+}
+
+// band writes the six rows starting at top, one pass per color used in
+// them
+func (e *encoder) band(top, colors int) {
+	rows := 6
+	if top+rows > e.height {
+		rows = e.height - top
+	}
+	line := make([]byte, e.width)
+	first := true
+	for c := 0; c < colors; c++ {
+		used := false
+		for x := range line {
+			var bits byte
+			for r := 0; r < rows; r++ {
+				if int(e.at(x, top+r)) == c {
+					bits |= 1 << r
+				}
+			}
+			line[x] = '?' + bits
+			used = used || bits != 0
+		}
+		if !used {
+			continue
+		}
+		if !first {
+			e.w.WriteByte('$') // back to the start of the band
+		}
+		first = false
+		fmt.Fprintf(e.w, "#%d", c)
+		e.runs(line)
+	}
+	e.w.WriteString("-\n")
+}
+
+// runs writes line with the repeats compressed, dropping empty columns at
+// its end
+func (e *encoder) runs(line []byte) {
+	for len(line) > 0 && line[len(line)-1] == '?' {
+		line = line[:len(line)-1]
+	}
+	for i := 0; i < len(line); {
+		n := 1
+		for i+n < len(line) && line[i+n] == line[i] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(e.w, "!%d%c", n, line[i])
+		} else {
+			e.w.Write(line[i : i+n])
+		}
+		i += n
+	}
+}
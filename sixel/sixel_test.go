@@ -0,0 +1,133 @@
+package sixel
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decode parses the output of Encode back into palette indexes, it only
+// understands what Encode writes
+func decode(t *testing.T, out string) (width, height int, pix map[image.Point]int) {
+	t.Helper()
+	if !strings.HasPrefix(out, Begin) || !strings.HasSuffix(out, End) {
+		t.Fatalf("Missing sixel start or end in %q", out)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(out, Begin), End)
+	header, body, _ := strings.Cut(body, "\n")
+	raster, _, _ := strings.Cut(strings.TrimPrefix(header, "\""), "#")
+	fields := strings.Split(raster, ";")
+	if len(fields) != 4 {
+		t.Fatalf("Bad raster attributes %q", raster)
+	}
+	width, _ = strconv.Atoi(fields[2])
+	height, _ = strconv.Atoi(fields[3])
+
+	pix = map[image.Point]int{}
+	x, top, c := 0, 0, 0
+	for i := 0; i < len(body); i++ {
+		switch ch := body[i]; {
+		case ch == '\n':
+		case ch == '-':
+			x, top = 0, top+6
+		case ch == '$':
+			x = 0
+		case ch == '#':
+			j := i + 1
+			for j < len(body) && body[j] >= '0' && body[j] <= '9' {
+				j++
+			}
+			c, _ = strconv.Atoi(body[i+1 : j])
+			i = j - 1
+		case ch == '!':
+			j := i + 1
+			for body[j] >= '0' && body[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(body[i+1 : j])
+			for k := 0; k < n; k++ {
+				set(pix, x+k, top, c, body[j])
+			}
+			x += n
+			i = j
+		case ch >= '?' && ch <= '~':
+			set(pix, x, top, c, ch)
+			x++
+		default:
+			t.Fatalf("Unexpected byte %q in sixel data", ch)
+		}
+	}
+	return width, height, pix
+}
+
+func set(pix map[image.Point]int, x, top, c int, ch byte) {
+	for r := 0; r < 6; r++ {
+		if (ch-'?')&(1<<r) != 0 {
+			pix[image.Pt(x, top+r)] = c
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	palette := color.Palette{color.Black, color.White, color.RGBA{0xff, 0, 0, 0xff}}
+	img := image.NewPaletted(image.Rect(0, 0, 5, 3), palette)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			img.SetColorIndex(x, y, uint8((x+y)%3))
+		}
+	}
+
+	for _, scale := range []int{0, 1, 4} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, img, &Options{Scale: scale}); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), "#2;2;100;0;0") {
+			t.Errorf("Expected red in the palette, got %q", buf.String())
+		}
+		s := scale
+		if s == 0 {
+			s = 1
+		}
+		width, height, pix := decode(t, buf.String())
+		if width != 5*s || height != 3*s {
+			t.Fatalf("Expected a %dx%d image, got %dx%d", 5*s, 3*s, width, height)
+		}
+		if len(pix) != width*height {
+			t.Errorf("Expected %d pixels, got %d", width*height, len(pix))
+		}
+		for p, c := range pix {
+			if want := int(img.ColorIndexAt(p.X/s, p.Y/s)); c != want {
+				t.Errorf("Scale %d: pixel %v is color %d, expected %d", scale, p, c, want)
+			}
+		}
+	}
+}
+
+func TestEncodeRGBA(t *testing.T) {
+	// Without a palette, images are drawn in the closest of black and white
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{0x10, 0x10, 0x10, 0xff})
+	img.Set(1, 0, color.RGBA{0xf0, 0xf0, 0xf0, 0xff})
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	_, _, pix := decode(t, buf.String())
+	if pix[image.Pt(0, 0)] != 0 || pix[image.Pt(1, 0)] != 1 {
+		t.Errorf("Expected black then white, got %v", pix)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	if err := Encode(&bytes.Buffer{}, img, &Options{Scale: -1}); err != ErrScale {
+		t.Errorf("Expected ErrScale, got %v", err)
+	}
+	if err := Encode(&bytes.Buffer{}, img, &Options{Palette: make(color.Palette, 257)}); err != ErrPalette {
+		t.Errorf("Expected ErrPalette, got %v", err)
+	}
+}
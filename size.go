@@ -0,0 +1,191 @@
+package qrterminal
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/katzenpost/qrterminal/v3/manualcode"
+)
+
+// Terminal cell size in pixels assumed for sixel output, the common size of
+// a terminal font at its default size
+const (
+	sixelCellWidth  = 10
+	sixelCellHeight = 20
+)
+
+// RenderedSize returns the number of terminal columns and lines data takes
+// up when drawn with config, quiet zone, hyperlink caption, backup code and middleware
+// output included, so a layout can reserve the space before rendering.
+// Sixel images are measured assuming cells of 10x20 pixels. Formats that
+// are not drawn in the terminal, such as FormatPDF, return an error.
+func RenderedSize(data []byte, config Config) (wCells, hLines int, err error) {
+	config.Profile.adjust(&config)
+	if len(config.Fallbacks) > 0 {
+		if config, _, err = config.fallback(data); err != nil {
+			return 0, 0, err
+		}
+	}
+	name := config.format()
+	format, ok := formatSpecFor(name)
+	if !ok {
+		name = FormatBlocks
+		format, _ = formatSpecFor(FormatBlocks)
+	}
+	if format.document {
+		return 0, 0, fmt.Errorf("qrterminal: the %s format is not drawn in the terminal", name)
+	}
+	if name == FormatSixel {
+		return sixelSize(data, config)
+	}
+
+	// Measuring draws nothing, not even to the line callback
+	var m measureWriter
+	config.Writer = &m
+	config.Outputs = nil
+	config.OnLine = nil
+	if err := generate(data, config); err != nil {
+		return 0, 0, err
+	}
+	m.flush()
+	return m.width, m.lines, nil
+}
+
+// SixelPixels returns the width and height in pixels of the sixel image
+// data is drawn as with config, quiet zone included, e.g. to check that it
+// fits a window of known pixel size
+func SixelPixels(data []byte, config Config) (width, height int, err error) {
+	spec, err := outputSpecFor(config.OutputVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+	if config.QuietZone < spec.minQuietZone {
+		config.QuietZone = spec.minQuietZone
+	}
+	bm, err := config.encode(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	size := sixelModuleSize(bm)
+	modules := bm.Size + 2*config.QuietZone
+	height = modules * size
+	if !spec.sixelImage {
+		rows := modules * (size / 6) // a sixel row is 6 pixels high
+		if config.QuietZone > 1 {
+			rows++
+		}
+		height = rows * 6
+	}
+	return modules * size, height, nil
+}
+
+// sixelSize converts the size of the sixel image drawn by writeSixel to
+// cells, the image starts on a new line
+func sixelSize(data []byte, config Config) (wCells, hLines int, err error) {
+	width, height, err := SixelPixels(data, config)
+	if err != nil {
+		return 0, 0, err
+	}
+	wCells = (width + sixelCellWidth - 1) / sixelCellWidth
+	hLines = (height + sixelCellHeight - 1) / sixelCellHeight
+	if _, ok := webURL(data); ok && config.Hyperlink {
+		hLines++
+	}
+	if config.BackupText {
+		backup, _ := config.payload(data)
+		hLines += strings.Count(manualcode.Encode(backup), "\n") + 1
+	}
+	return wCells, hLines, nil
+}
+
+// measureWriter finds the widest line written to it in columns and counts
+// the lines, skipping escape sequences and counting one column per rune.
+// Only the line being written is kept in memory.
+type measureWriter struct {
+	width, lines int
+	partial      []byte
+}
+
+func (m *measureWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			m.partial = append(m.partial, p...)
+			break
+		}
+		m.line(append(m.partial, p[:i+1]...))
+		m.partial = m.partial[:0]
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush measures a last line without a line ending
+func (m *measureWriter) flush() {
+	if len(m.partial) > 0 {
+		m.line(m.partial)
+		m.partial = nil
+	}
+}
+
+func (m *measureWriter) line(line []byte) {
+	m.lines++
+	if w := displayWidth(line); w > m.width {
+		m.width = w
+	}
+}
+
+func displayWidth(line []byte) int {
+	// Every character of a double-height line takes up two columns
+	if bytes.HasPrefix(line, []byte(decDHLTop)) || bytes.HasPrefix(line, []byte(decDHLBottom)) {
+		return 2 * displayWidth(line[len(decDHLTop):])
+	}
+	width := 0
+	for len(line) > 0 {
+		if line[0] == '\033' && len(line) > 1 {
+			line = skipEscape(line)
+			continue
+		}
+		r, n := utf8.DecodeRune(line)
+		line = line[n:]
+		if r >= ' ' {
+			width++
+		}
+	}
+	return width
+}
+
+// skipEscape returns p after the escape sequence at its start: CSI
+// sequences up to their final byte, OSC sequences up to BEL or ST, three
+// bytes for DEC line attributes and two bytes for anything else
+func skipEscape(p []byte) []byte {
+	switch p[1] {
+	case '[':
+		for i := 2; i < len(p); i++ {
+			if p[i] >= 0x40 && p[i] <= 0x7e {
+				return p[i+1:]
+			}
+		}
+		return nil
+	case ']':
+		for i := 2; i < len(p); i++ {
+			if p[i] == '\a' {
+				return p[i+1:]
+			}
+			if p[i] == '\033' && i+1 < len(p) && p[i+1] == '\\' {
+				return p[i+2:]
+			}
+		}
+		return nil
+	case '#':
+		if len(p) < 3 {
+			return nil
+		}
+		return p[3:]
+	default:
+		return p[2:]
+	}
+}
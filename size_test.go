@@ -0,0 +1,114 @@
+package qrterminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderedSize(t *testing.T) {
+	// "test" at level L is a version 1 code of 21 modules
+	testCases := []struct {
+		name   string
+		config Config
+		w, h   int
+	}{
+		{"Blocks", Config{Level: L, QuietZone: 2}, 25, 25},
+		{"ANSIBlocks", Config{Level: L, QuietZone: 2, BlackChar: BLACK, WhiteChar: WHITE}, 50, 25},
+		{"HalfBlocks", Config{Level: L, QuietZone: 2, HalfBlocks: true}, 25, 13},
+		{"DoubleSize", Config{Level: L, QuietZone: 2, Format: FormatDoubleSize}, 50, 50},
+		{"AspectRatio", Config{Level: L, QuietZone: 2, AspectRatio: 0.5}, 50, 25},
+		{"AspectRatioANSI", Config{Level: L, QuietZone: 2, BlackChar: BLACK, WhiteChar: WHITE, AspectRatio: 0.5}, 50, 25},
+		{"AspectRatioWide", Config{Level: L, QuietZone: 2, BlackChar: "##", WhiteChar: "  ", AspectRatio: 1}, 50, 50},
+		{"Bits", Config{Level: L, QuietZone: 2, Format: FormatBits}, 25, 25},
+		{"BitsGrouped", Config{Level: L, QuietZone: 2, Format: FormatBits, BitsGroup: 5}, 29, 25},
+		{"Sixel", Config{Level: L, QuietZone: 2, Format: FormatSixel}, 30, 15},
+		{"SixelV2", Config{Level: L, QuietZone: 2, Format: FormatSixel, OutputVersion: OutputV2}, 30, 16},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, h, err := RenderedSize([]byte("test"), tc.config)
+			if err != nil {
+				t.Fatalf("RenderedSize failed: %v", err)
+			}
+			if w != tc.w || h != tc.h {
+				t.Errorf("Expected %dx%d, got %dx%d", tc.w, tc.h, w, h)
+			}
+		})
+	}
+}
+
+func TestRenderedSizeHyperlink(t *testing.T) {
+	link := []byte("https://github.com/mdp/qrterminal")
+	_, plain, _ := RenderedSize(link, Config{Level: L})
+	w, linked, _ := RenderedSize(link, Config{Level: L, Hyperlink: true})
+	if linked != plain+1 {
+		t.Errorf("Expected the caption to take one more line, got %d and %d", plain, linked)
+	}
+	if w < len(link) {
+		t.Errorf("Expected the caption to count %d columns, got %d", len(link), w)
+	}
+}
+
+func TestRenderedSizeErrors(t *testing.T) {
+	if _, _, err := RenderedSize([]byte("test"), Config{Format: FormatPDF}); err == nil {
+		t.Errorf("Expected an error for the pdf format")
+	}
+	if _, _, err := RenderedSize(make([]byte, 4000), Config{}); err == nil {
+		t.Errorf("Expected an error for data that does not fit")
+	}
+}
+
+func TestSixelPixels(t *testing.T) {
+	// 21 modules and the quiet zone, 12 pixels each
+	w, h, err := SixelPixels([]byte("test"), Config{Level: L, QuietZone: 2})
+	if err != nil || w != 300 || h != 300 {
+		t.Errorf("Expected 300x300 pixels, got %dx%d, %v", w, h, err)
+	}
+	// Codes over 50 modules are drawn at half the size
+	w, h, _ = SixelPixels(make([]byte, 200), Config{Level: L, QuietZone: 2})
+	if bm, _ := Encode(make([]byte, 200), Config{Level: L}); w != 6*(bm.Size+4) || h != w {
+		t.Errorf("Expected %d pixels on a side, got %dx%d", 6*(bm.Size+4), w, h)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	testCases := map[string]int{
+		"abc":                                    3,
+		WHITE + BLACK:                            4,
+		"██▀▄":                                   4,
+		"\033]8;;https://x\033\\x\033]8;;\033\\": 1,
+		"\033]8;;https://x\ax\033]8;;\a":         1,
+		"ab\n":                                   2,
+	}
+	for in, want := range testCases {
+		if got := displayWidth([]byte(in)); got != want {
+			t.Errorf("displayWidth(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestRenderedSizeOnLine(t *testing.T) {
+	// Measuring, also to pick a fallback or for Info, calls OnLine for no
+	// line, drawing calls it once per line
+	var calls int
+	config := Config{Level: L, QuietZone: 2, OnLine: func(string) { calls++ }}
+	if _, h, err := RenderedSize([]byte("test"), config); err != nil || h != 25 {
+		t.Fatalf("RenderedSize returned %d lines, %v", h, err)
+	}
+	if _, err := Info([]byte("test"), config); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no calls while measuring, got %d", calls)
+	}
+
+	var buf bytes.Buffer
+	config.Writer = &buf
+	config.Fallbacks = DefaultFallbacks
+	config.Capabilities = &Capabilities{Unicode: true, Color: true}
+	config.MaxColumns = 40
+	GenerateWithConfig("test", config)
+	if calls != 13 {
+		t.Errorf("Expected a call for each of the 13 lines drawn, got %d", calls)
+	}
+}
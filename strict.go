@@ -0,0 +1,60 @@
+package qrterminal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotASCII is returned in strict mode when a glyph or label is not
+// plain ASCII but the Profile only allows ASCII, see Config.Strict
+var ErrNotASCII = errors.New("qrterminal: not ASCII")
+
+// isASCII reports whether s is made of 7-bit characters only
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkASCII returns an ErrNotASCII naming what s is when c is strict,
+// its Profile is an ASCII one and s is not ASCII
+func (c *Config) checkASCII(what, s string) error {
+	if !c.Strict || c.Profile == nil || !c.Profile.ASCII || isASCII(s) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %q", ErrNotASCII, what, s)
+}
+
+// checkGlyphs checks the custom glyphs, those set on c or by its Theme,
+// that format name draws the code with
+func (c *Config) checkGlyphs(name Format) error {
+	var glyphs [][2]string
+	switch name {
+	case FormatSixel, FormatBits, FormatPDF, FormatZPL, FormatEPL:
+	case FormatLinePrinter:
+		if c.LinePrinter != nil {
+			glyphs = [][2]string{{"overstrike", c.LinePrinter.Overstrike}}
+		}
+	case FormatHalfBlocks:
+		glyphs = [][2]string{
+			{"black over white glyph", c.BlackWhiteChar},
+			{"white over black glyph", c.WhiteBlackChar},
+		}
+		fallthrough
+	default:
+		glyphs = append(glyphs, [][2]string{
+			{"black glyph", c.BlackChar},
+			{"white glyph", c.WhiteChar},
+			{"quiet zone glyph", c.QuietChar},
+		}...)
+	}
+	for _, g := range glyphs {
+		if err := c.checkASCII(g[0], g[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
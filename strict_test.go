@@ -0,0 +1,54 @@
+package qrterminal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config Config
+		err    bool
+	}{
+		{"default glyphs", Config{}, false},
+		{"ascii glyphs", Config{BlackChar: "  ", WhiteChar: "##"}, false},
+		{"ascii theme", Config{Theme: ThemeASCII}, false},
+		{"block glyphs", Config{BlackChar: "  ", WhiteChar: "██"}, true},
+		{"quiet glyph", Config{QuietChar: "░░"}, true},
+		{"default half blocks", Config{Format: FormatHalfBlocks}, false},
+		{"half block glyphs", Config{Format: FormatHalfBlocks, BlackWhiteChar: "▀", WhiteBlackChar: "▄"}, true},
+		{"shade theme", Config{Theme: ThemeShade}, true},
+		{"overstrike", Config{Format: FormatLinePrinter, LinePrinter: &LinePrinterOptions{Overstrike: "M▓"}}, true},
+		{"bits", Config{Format: FormatBits, BlackChar: "██"}, false},
+	} {
+		var buf bytes.Buffer
+		tc.config.Writer = &buf
+		tc.config.Profile = ProfileCI
+		tc.config.Strict = true
+		err := generate([]byte("test"), tc.config)
+		if tc.err != errors.Is(err, ErrNotASCII) {
+			t.Errorf("%s: got error %v", tc.name, err)
+		}
+		if tc.err && buf.Len() > 0 {
+			t.Errorf("%s: expected nothing to be written, got %q", tc.name, buf.String())
+		}
+
+		// Without Strict, or with a profile that is not ASCII, anything goes
+		tc.config.Strict = false
+		if err := generate([]byte("test"), tc.config); err != nil {
+			t.Errorf("%s: not strict: %v", tc.name, err)
+		}
+		tc.config.Strict, tc.config.Profile = true, ProfileRemote
+		if err := generate([]byte("test"), tc.config); err != nil {
+			t.Errorf("%s: not ASCII profile: %v", tc.name, err)
+		}
+	}
+
+	labels := []Label{{Data: []byte("a"), Caption: "a"}, {Data: []byte("b"), Caption: "café"}}
+	err := GenerateSideBySide(labels, Config{Writer: &bytes.Buffer{}, Profile: ProfileCI, Strict: true})
+	if !errors.Is(err, ErrNotASCII) {
+		t.Errorf("Expected the caption to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package qrterminal
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"rsc.io/qr"
+)
+
+// Encoder encodes data as the modules of a square two-dimensional symbol.
+// The renderers only see the resulting Bitmap, so an Encoder for another
+// symbology, such as DataMatrix or Aztec, works with every format.
+type Encoder interface {
+	Encode(data []byte, level Level) (*Bitmap, error)
+}
+
+// EncoderFunc adapts an ordinary function to the Encoder interface
+type EncoderFunc func(data []byte, level Level) (*Bitmap, error)
+
+// Encode calls f(data, level)
+func (f EncoderFunc) Encode(data []byte, level Level) (*Bitmap, error) {
+	return f(data, level)
+}
+
+// SymbologyQR names the built in QR code encoder, used when
+// Config.Symbology is not set
+const SymbologyQR = "qr"
+
+var (
+	encodersMu sync.Mutex
+	encoders   = map[string]Encoder{SymbologyQR: EncoderFunc(encodeQR)}
+)
+
+// RegisterSymbology makes e available as Config.Symbology name, replacing
+// any encoder registered under the same name
+func RegisterSymbology(name string, e Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[name] = e
+}
+
+// Symbologies returns the names of the registered encoders in sorted order
+func Symbologies() []string {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validSymbology reports whether an encoder is registered as name
+func validSymbology(name string) bool {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	_, ok := encoders[name]
+	return ok
+}
+
+// Encode returns the modules of data as encoded with the Symbology and
+// Level of config, e.g. to inspect the size of a code before rendering it.
+//
+// Encoding is deterministic: the same data and Level always give the same
+// QR code, in this and later releases of the package. The built in encoder
+// always applies mask pattern 0, there is no mask selection that could
+// depend on anything else.
+func Encode(data []byte, config Config) (*Bitmap, error) {
+	return config.encode(data)
+}
+
+// payload returns the data to encode in place of data: an upper cased URL
+// with AutoUppercaseURLs
+func (c *Config) payload(data []byte) ([]byte, error) {
+	if c.AutoUppercaseURLs {
+		if upper, ok := uppercaseURL(data); ok && alphanumeric(upper) {
+			return upper, nil
+		}
+	}
+	return data, nil
+}
+
+// encode encodes data with the encoder selected by c.Symbology
+func (c *Config) encode(data []byte) (*Bitmap, error) {
+	data, err := c.payload(data)
+	if err != nil {
+		return nil, err
+	}
+	name := c.Symbology
+	if name == "" {
+		name = SymbologyQR
+	}
+	var key string
+	if c.Cache != nil {
+		key = name + "\x00" + c.Level.String() + "\x00" + string(data)
+		if bm := c.Cache.get(key); bm != nil {
+			return bm, nil
+		}
+	}
+	encodersMu.Lock()
+	e, ok := encoders[name]
+	encodersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("qrterminal: unknown symbology %q", name)
+	}
+	bm, err := e.Encode(data, c.Level)
+	if err == nil && name == SymbologyQR && !c.LowMemory {
+		bm.roles = qrRoles(bm.Size, c.Level)
+	}
+	if err == nil && c.Cache != nil {
+		c.Cache.put(key, bm)
+	}
+	return bm, err
+}
+
+func encodeQR(data []byte, level Level) (*Bitmap, error) {
+	// Binary data is encoded with its exact byte values by the string path
+	code, err := qr.Encode(string(data), level.QR())
+	if err != nil {
+		return nil, err
+	}
+	return bitmapFromCode(code), nil
+}
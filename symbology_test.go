@@ -0,0 +1,185 @@
+package qrterminal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+// checkerboard is a stand-in for another symbology
+func checkerboard(data []byte, level Level) (*Bitmap, error) {
+	bm := NewBitmap(len(data))
+	for y := 0; y < bm.Size; y++ {
+		for x := 0; x < bm.Size; x++ {
+			bm.Set(x, y, (x+y)%2 == 0)
+		}
+	}
+	return bm, nil
+}
+
+func TestRegisterSymbology(t *testing.T) {
+	encodersMu.Lock()
+	saved := encoders
+	encoders = map[string]Encoder{SymbologyQR: EncoderFunc(encodeQR)}
+	encodersMu.Unlock()
+	defer func() { encoders = saved }()
+
+	RegisterSymbology("checkerboard", EncoderFunc(checkerboard))
+	if got := strings.Join(Symbologies(), ","); got != "checkerboard,qr" {
+		t.Errorf("Expected checkerboard and qr to be registered, got %s", got)
+	}
+
+	var buf bytes.Buffer
+	GenerateWithConfig("abc", Config{Writer: &buf, QuietZone: 1, Format: FormatBits, Symbology: "checkerboard"})
+	want := "00000\n01010\n00100\n01010\n00000\n"
+	if buf.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	// Other symbologies have their own quiet zone requirements
+	if hasWarning(Lint(Config{QuietZone: 1, Symbology: "checkerboard"}), "quiet-zone") {
+		t.Errorf("The QR quiet zone warning should not apply to other symbologies")
+	}
+}
+
+func TestUnknownSymbology(t *testing.T) {
+	err := GenerateFromReader(strings.NewReader("abc"), 0, Config{Writer: &bytes.Buffer{}, Symbology: "nope"})
+	if err == nil || !strings.Contains(err.Error(), `unknown symbology "nope"`) {
+		t.Errorf("Expected an unknown symbology error, got %v", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	bm, err := Encode([]byte("https://github.com/mdp/qrterminal"), Config{Level: M})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	code, _ := qr.Encode("https://github.com/mdp/qrterminal", M.QR())
+	if bm.Size != code.Size {
+		t.Errorf("Expected %d modules on a side, got %d", code.Size, bm.Size)
+	}
+}
+
+func TestEmptyPayload(t *testing.T) {
+	for _, text := range []string{"", " ", "\n\t \r\n"} {
+		var buf bytes.Buffer
+		if err := GenerateText(text, Config{Level: L, Writer: &buf}); err != ErrEmptyPayload {
+			t.Errorf("Expected ErrEmptyPayload for %q, got %v", text, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Nothing should be drawn for %q", text)
+		}
+
+		// Binary data and the void functions are not checked
+		if _, err := Encode([]byte(text), Config{Level: L}); err != nil {
+			t.Errorf("Encode should encode %q: %v", text, err)
+		}
+		if _, _, err := RenderedSize([]byte(text), Config{Level: L}); err != nil {
+			t.Errorf("RenderedSize should measure %q: %v", text, err)
+		}
+		buf.Reset()
+		GenerateWithConfig(text, Config{Level: L, Writer: &buf})
+		if buf.Len() == 0 {
+			t.Errorf("GenerateWithConfig should draw %q", text)
+		}
+	}
+
+	// AllowEmpty draws what rsc.io/qr makes of no data
+	var allowed, empty bytes.Buffer
+	if err := GenerateText("", Config{Level: L, Writer: &allowed, AllowEmpty: true}); err != nil {
+		t.Fatalf("AllowEmpty should encode empty text: %v", err)
+	}
+	GenerateWithConfig("", Config{Level: L, Writer: &empty})
+	if allowed.String() != empty.String() {
+		t.Errorf("AllowEmpty should encode the empty text as is")
+	}
+
+	var placeholder, want bytes.Buffer
+	GenerateText(" ", Config{Level: L, Writer: &placeholder, AllowEmpty: true, Placeholder: "pending"})
+	GenerateWithConfig("pending", Config{Level: L, Writer: &want})
+	if placeholder.String() != want.String() {
+		t.Errorf("The placeholder should be encoded in place of blank text")
+	}
+}
+
+func equalBitmaps(a, b *Bitmap) bool {
+	if a.Size != b.Size {
+		return false
+	}
+	for y := 0; y < a.Size; y++ {
+		for x := 0; x < a.Size; x++ {
+			if a.Black(x, y) != b.Black(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TestEncodeStable pins the modules of a few codes, Encode promises the
+// same symbol for the same input in every release
+func TestEncodeStable(t *testing.T) {
+	tests := []struct {
+		data  string
+		level Level
+		want  string
+	}{
+		{"https://github.com/mdp/qrterminal", L, "8030e7adca09467a74fd92248204b5f03efc06542154ac67611ee2bd47f3043f"},
+		{"HELLO WORLD", Q, "98ee3af8dfb46e027fa18006e4cb5079826d9b1aeeec68886f356578867c468c"},
+		{"01234567", H, "72511061a5155fd087555f5ccd8ac5236a25f0da5cb17e1580023ea04a7caee9"},
+	}
+	for _, tt := range tests {
+		bm, err := Encode([]byte(tt.data), Config{Level: tt.level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var bits strings.Builder
+		for y := 0; y < bm.Size; y++ {
+			for x := 0; x < bm.Size; x++ {
+				if bm.Black(x, y) {
+					bits.WriteByte('1')
+				} else {
+					bits.WriteByte('0')
+				}
+			}
+		}
+		sum := sha256.Sum256([]byte(bits.String()))
+		if got := hex.EncodeToString(sum[:]); got != tt.want {
+			t.Errorf("The symbol of %q at level %s changed, got hash %s", tt.data, tt.level, got)
+		}
+	}
+}
+
+func TestAutoUppercaseURLs(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"https://example.com/T0K3N", "HTTPS://EXAMPLE.COM/T0K3N"},
+		{"http://example.com:8080/A/B", "HTTP://EXAMPLE.COM:8080/A/B"},
+		// Upper casing would not make these alphanumeric
+		{"https://example.com/token", "https://example.com/token"},
+		{"https://user@example.com/T0K3N", "https://user@example.com/T0K3N"},
+		{"ftp://example.com/FILE", "ftp://example.com/FILE"},
+	}
+	for _, tt := range tests {
+		config := Config{Level: L, AutoUppercaseURLs: true}
+		got, err := config.payload([]byte(tt.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Expected %q to be encoded as %q, got %q", tt.data, tt.want, got)
+		}
+	}
+
+	plain, _ := Encode([]byte("https://example.com/T0K3N"), Config{Level: L})
+	upper, _ := Encode([]byte("https://example.com/T0K3N"), Config{Level: L, AutoUppercaseURLs: true})
+	if upper.Size >= plain.Size {
+		t.Errorf("Expected a smaller symbol in upper case, got %d modules instead of %d", upper.Size, plain.Size)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !tinygo
+
+package qrterminal
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// stdTerminal is the Terminal backed by the process environment and stdout
+type stdTerminal struct {
+	f *os.File
+}
+
+func newTerminal(w io.Writer) Terminal {
+	if w == os.Stdout {
+		return stdTerminal{os.Stdout}
+	}
+	return stdTerminal{}
+}
+
+func (t stdTerminal) Getenv(key string) string {
+	return os.Getenv(key)
+}
+
+func (t stdTerminal) IsTerminal() bool {
+	return t.f != nil && term.IsTerminal(int(t.f.Fd()))
+}
+
+func (t stdTerminal) Query(seq string) ([]byte, error) {
+	if !t.IsTerminal() || !canQuery() {
+		return nil, ErrNotTerminal
+	}
+	in := queryInput(t.f)
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, ErrNotTerminal
+	}
+	// set echo off so the reply is not printed
+	raw, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer term.Restore(fd, raw)
+	if _, err := t.f.Write([]byte(seq)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1024)
+	n, err := in.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,28 @@
+//go:build tinygo
+
+package qrterminal
+
+import (
+	"io"
+	"os"
+)
+
+// envTerminal is the Terminal of TinyGo builds, which only reads the
+// environment: microcontroller targets have no terminal to query
+type envTerminal struct{}
+
+func newTerminal(w io.Writer) Terminal {
+	return envTerminal{}
+}
+
+func (envTerminal) Getenv(key string) string {
+	return os.Getenv(key)
+}
+
+func (envTerminal) IsTerminal() bool {
+	return false
+}
+
+func (envTerminal) Query(seq string) ([]byte, error) {
+	return nil, ErrNotTerminal
+}
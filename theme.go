@@ -0,0 +1,108 @@
+package qrterminal
+
+// Glyphs is a set of strings used to draw modules. For FormatBlocks each
+// glyph covers one module and is usually two columns wide to make modules
+// roughly square. For FormatHalfBlocks each glyph covers two modules on top
+// of each other.
+type Glyphs struct {
+	Black      string
+	White      string
+	BlackWhite string // black module above a white one, half blocks only
+	WhiteBlack string // white module above a black one, half blocks only
+	// Quiet draws the quiet zone of FormatBlocks, White is used when empty
+	Quiet string
+}
+
+// Glyph sets for building a Theme or setting the glyphs of a Config. The
+// blocks sets assume a dark terminal background, so black modules are
+// blank and white ones are drawn.
+var (
+	// GlyphsANSI colors two spaces with a black or white background, the
+	// set used by Generate
+	GlyphsANSI = Glyphs{Black: BLACK, White: WHITE}
+	// GlyphsFullBlocks draws white modules as two full blocks
+	GlyphsFullBlocks = Glyphs{Black: "  ", White: "██"}
+	// GlyphsHalfBlocks is the default set of FormatHalfBlocks
+	GlyphsHalfBlocks = Glyphs{Black: BLACK_BLACK, White: WHITE_WHITE, BlackWhite: BLACK_WHITE, WhiteBlack: WHITE_BLACK}
+	// GlyphsShade draws white modules with the dark shade and the quiet
+	// zone with the light shade, see ThemeShade
+	GlyphsShade = Glyphs{Black: "  ", White: "▓▓", Quiet: "░░"}
+	// GlyphsASCII only uses ASCII, for fonts and consoles without block
+	// elements
+	GlyphsASCII = Glyphs{Black: "  ", White: "##"}
+	// GlyphsBraille draws white modules as full braille cells on the blank
+	// braille pattern, for fonts whose block elements leave gaps
+	GlyphsBraille = Glyphs{Black: "\u2800\u2800", White: "⣿⣿"}
+)
+
+// Theme is a named pair of glyph sets for the block formats, Blocks also
+// draws FormatDoubleSize. Glyphs set
+// directly on the Config take precedence over the ones of its Theme.
+type Theme struct {
+	Name       string
+	Blocks     Glyphs
+	HalfBlocks Glyphs
+	// Caveats describe known scannability issues, they are reported by Lint
+	Caveats []string
+}
+
+// ThemeShade draws white modules with the dark shade glyph and the quiet
+// zone with the light shade glyph. The dithered glyphs reduce moiré when a
+// camera photographs a low DPI terminal font, at the cost of contrast.
+// There are no shade half blocks, so FormatHalfBlocks uses the defaults.
+var ThemeShade = &Theme{
+	Name:   "shade",
+	Blocks: GlyphsShade,
+	Caveats: []string{
+		"shade glyphs lower the contrast between modules, check that your scanner reads the code",
+		"the light shade quiet zone is darker than the white modules, some scanners need a brighter border",
+	},
+}
+
+// ThemeASCII draws the blocks format with GlyphsASCII
+var ThemeASCII = &Theme{
+	Name:   "ascii",
+	Blocks: GlyphsASCII,
+	Caveats: []string{
+		"ASCII glyphs leave gaps inside white modules, scan the code from further away if it is not recognized",
+	},
+}
+
+var themes = []*Theme{ThemeShade, ThemeASCII}
+
+// ThemeByName returns the built in theme called name, or nil
+func ThemeByName(name string) *Theme {
+	for _, t := range themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// apply fills the glyphs of c that are not set from the theme's glyphs for
+// format f
+func (t *Theme) apply(c *Config, f Format) {
+	if t == nil {
+		return
+	}
+	var g Glyphs
+	switch f {
+	case FormatBlocks, FormatDoubleSize:
+		g = t.Blocks
+	case FormatHalfBlocks:
+		g = t.HalfBlocks
+	default:
+		return
+	}
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&c.BlackChar, g.Black)
+	fill(&c.WhiteChar, g.White)
+	fill(&c.BlackWhiteChar, g.BlackWhite)
+	fill(&c.WhiteBlackChar, g.WhiteBlack)
+	fill(&c.QuietChar, g.Quiet)
+}
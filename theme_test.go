@@ -0,0 +1,100 @@
+package qrterminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestThemeShade(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{
+		Level:     L,
+		Writer:    &buf,
+		QuietZone: QUIET_ZONE,
+		Theme:     ThemeShade,
+	})
+	lines := strings.Split(buf.String(), "\n")
+
+	if lines[0] != strings.Repeat("░░", strings.Count(lines[0], "░░")) {
+		t.Errorf("The top border should be drawn in light shade, got %q", lines[0])
+	}
+	middle := lines[QUIET_ZONE+3]
+	if !strings.HasPrefix(middle, strings.Repeat("░░", QUIET_ZONE)) {
+		t.Errorf("The left border should be drawn in light shade, got %q", middle)
+	}
+	if !strings.Contains(buf.String(), "▓▓") {
+		t.Errorf("White modules should be drawn in dark shade")
+	}
+	if strings.Contains(buf.String(), WHITE_WHITE) {
+		t.Errorf("Default glyphs should not be mixed into a themed code")
+	}
+}
+
+func TestThemeConfigPrecedence(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{
+		Level:     L,
+		Writer:    &buf,
+		WhiteChar: "..",
+		Theme:     ThemeShade,
+	})
+	if strings.Contains(buf.String(), "▓▓") || !strings.Contains(buf.String(), "..") {
+		t.Errorf("WhiteChar set on the Config should take precedence over the theme")
+	}
+	if !strings.Contains(buf.String(), "░░") {
+		t.Errorf("Glyphs not set on the Config should still come from the theme")
+	}
+}
+
+func TestThemeHalfBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{
+		Level:      L,
+		Writer:     &buf,
+		HalfBlocks: true,
+		Theme:      ThemeShade,
+	})
+	if strings.ContainsAny(buf.String(), "▓░") {
+		t.Errorf("The shade theme has no half blocks and should fall back to the defaults")
+	}
+}
+
+func TestThemeByName(t *testing.T) {
+	if ThemeByName("shade") != ThemeShade {
+		t.Errorf("Expected to find the shade theme")
+	}
+	if ThemeByName("nope") != nil {
+		t.Errorf("Expected nil for an unknown theme")
+	}
+}
+
+func TestGlyphSets(t *testing.T) {
+	sets := map[string]Glyphs{
+		"ANSI":       GlyphsANSI,
+		"FullBlocks": GlyphsFullBlocks,
+		"HalfBlocks": GlyphsHalfBlocks,
+		"Shade":      GlyphsShade,
+		"ASCII":      GlyphsASCII,
+		"Braille":    GlyphsBraille,
+	}
+	for name, g := range sets {
+		if g.Black == "" || g.White == "" || g.Black == g.White {
+			t.Errorf("%s: black and white glyphs must be set and differ", name)
+		}
+		theme := &Theme{Name: name, Blocks: g}
+		if warnings := Lint(Config{QuietZone: QUIET_ZONE, Theme: theme}); len(warnings) != 0 {
+			t.Errorf("%s: unexpected warnings %v", name, warnings)
+		}
+	}
+}
+
+func TestThemeASCII(t *testing.T) {
+	var buf bytes.Buffer
+	GenerateWithConfig("test", Config{Level: L, Writer: &buf, QuietZone: 1, Theme: ThemeByName("ascii")})
+	for _, r := range buf.String() {
+		if r != ' ' && r != '#' && r != '\n' {
+			t.Fatalf("Unexpected character %q in ASCII output", r)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !tinygo
+
+package qrterminal
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// WatchResize clears the terminal f and draws data on it with the config
+// that best fits its size, then does so again every time the terminal is
+// resized, until ctx is done. It is the building block of a display that
+// keeps a code on screen.
+func WatchResize(ctx context.Context, f *os.File, data []byte, config Config) error {
+	resized := notifyResize(ctx, f)
+	// Redraws only change the size, the code and what the terminal
+	// supports stay the same
+	if config.Cache == nil {
+		config.Cache = NewEncodeCache(1)
+	}
+	if config.Capabilities == nil && (len(config.Fallbacks) > 0 || (config.Profile != nil && len(config.Profile.Fallbacks) > 0)) {
+		caps := DetectCapabilities(f)
+		config.Capabilities = &caps
+	}
+	for {
+		cols, lines, err := term.GetSize(int(f.Fd()))
+		if err != nil {
+			return err
+		}
+		fit, err := BestFit(data, config, cols, lines)
+		if err != nil {
+			return err
+		}
+		io.WriteString(f, "\033[H\033[2J") // home the cursor and clear the screen
+		fit.Writer = f
+		if err := generate(data, fit); err != nil {
+			return err
+		}
+		// Outputs keep a copy of the code, which redraws do not change
+		config.Outputs = nil
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resized:
+		}
+	}
+}